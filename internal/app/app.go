@@ -0,0 +1,268 @@
+// Package app merangkai konfigurasi, database, repository, service dan
+// handler menjadi satu aplikasi yang siap dijalankan.
+package app
+
+import (
+	"api-otto/internal/auth"
+	"api-otto/internal/config"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/health"
+	"api-otto/internal/i18n"
+	"api-otto/internal/logging"
+	"api-otto/internal/metrics"
+	"api-otto/internal/migrate"
+	"api-otto/internal/repository"
+	"api-otto/internal/repository/memory"
+	"api-otto/internal/server"
+	"api-otto/internal/service"
+	"api-otto/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// idempotencyCleanupInterval adalah jeda pembersihan Idempotency-Key kadaluarsa
+const idempotencyCleanupInterval = time.Hour
+
+// rateLimitCleanupInterval adalah jeda pembersihan bucket rate limit yang
+// sudah lama tidak dipakai
+const rateLimitCleanupInterval = time.Minute
+
+// readinessTimeout membatasi lama semua check /readyz
+const readinessTimeout = 2 * time.Second
+
+// tracingShutdownTimeout membatasi pengiriman span yang tersisa saat berhenti
+const tracingShutdownTimeout = 5 * time.Second
+
+type App struct {
+	cfg     *config.Config
+	server  *server.Server
+	handler http.Handler
+}
+
+// New membuka storage sesuai cfg.Storage dan menyiapkan server. Koneksi
+// database ditutup oleh Run saat aplikasi berhenti.
+func New(cfg *config.Config) (*App, error) {
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	appMetrics := metrics.New()
+	store, err := openStorage(context.Background(), cfg, appMetrics)
+	if err != nil {
+		shutdownTracing(context.Background())
+		return nil, err
+	}
+
+	// Initialize services
+	brandService := service.NewTracedBrandService(service.NewBrandService(store.txManager, store.brands))
+	voucherService := service.NewTracedVoucherService(service.NewVoucherService(store.txManager, store.vouchers, store.brands))
+	transactionService := service.NewTracedTransactionService(service.NewTransactionService(store.txManager, store.transactions, appMetrics))
+	customerService := service.NewTracedCustomerService(service.NewCustomerService(store.customers))
+	auditService := service.NewTracedAuditService(service.NewAuditService(store.audit))
+
+	probe := health.NewProbe(readinessTimeout, store.checks...)
+
+	// Initialize handlers
+	handlers := handlers{
+		health:      handler.NewHealthHandler(probe),
+		brand:       handler.NewBrandHandler(brandService),
+		voucher:     handler.NewVoucherHandler(voucherService),
+		transaction: handler.NewTransactionHandler(transactionService),
+		customer:    handler.NewCustomerHandler(customerService),
+		audit:       handler.NewAuditHandler(auditService),
+	}
+	if cfg.Features.Idempotency {
+		handlers.idempotency = handler.NewIdempotency(store.idempotency, cfg.Idempotency.KeyTTL)
+	}
+	if cfg.Auth.Enabled {
+		authenticator, err := newAuthenticator(cfg.Auth, store.apiKeys, store.roles)
+		if err != nil {
+			store.Close()
+			shutdownTracing(context.Background())
+			return nil, err
+		}
+		handlers.authenticator = authenticator
+	} else {
+		slog.Warn("authentication is disabled, every endpoint is open")
+	}
+	var rateLimitRepo domain.RateLimitRepository
+	if cfg.RateLimit.Enabled {
+		rateLimitRepo = memory.NewRateLimitRepository()
+		if cfg.RateLimit.Backend == "postgres" {
+			rateLimitRepo = repository.NewRateLimitRepository(store.db)
+		}
+		handlers.rateLimiter = handler.NewRateLimiter(rateLimitRepo, rateLimitOptions(cfg.RateLimit))
+	}
+
+	router, err := newRouter(handlers, cfg, appMetrics)
+	if err != nil {
+		store.Close()
+		shutdownTracing(context.Background())
+		return nil, err
+	}
+
+	httpHandler := logging.Middleware(slog.Default(), i18n.Middleware(cfg.DefaultLanguage, router))
+	srv := server.New(server.Options{
+		Addr:            cfg.Server.Addr,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		IdleTimeout:     cfg.Server.IdleTimeout,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+	}, httpHandler)
+
+	// Bersihkan key yang sudah kadaluarsa secara berkala
+	if cfg.Features.Idempotency {
+		srv.AddWorker("idempotency-cleanup", func(ctx context.Context) {
+			ticker := time.NewTicker(idempotencyCleanupInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					if _, err := store.idempotency.DeleteExpired(ctx, now); err != nil {
+						slog.ErrorContext(ctx, "failed to delete expired idempotency keys", "error", err)
+					}
+				}
+			}
+		})
+	}
+	// Bucket yang tidak dipakai selama period terpanjang sudah penuh lagi,
+	// menghapusnya sama dengan membiarkannya
+	if cfg.RateLimit.Enabled {
+		idle := rateLimitIdlePeriod(cfg.RateLimit)
+		srv.AddWorker("rate-limit-cleanup", func(ctx context.Context) {
+			ticker := time.NewTicker(rateLimitCleanupInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					if _, err := rateLimitRepo.DeleteIdle(ctx, now.Add(-idle)); err != nil {
+						slog.ErrorContext(ctx, "failed to delete idle rate limit buckets", "error", err)
+					}
+				}
+			}
+		})
+	}
+	srv.OnDrain(probe.SetDraining)
+	// Closer dijalankan terbalik, span terakhir dikirim setelah database ditutup
+	srv.OnShutdown("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
+	srv.OnShutdown("storage", store.Close)
+
+	return &App{cfg: cfg, server: srv, handler: httpHandler}, nil
+}
+
+// checkSchema menolak start jika skema belum di versi terbaru. Skema yang
+// lebih baru dari binary diizinkan supaya rollback deploy tetap bisa jalan.
+func checkSchema(ctx context.Context, migrator *migrate.Migrator) error {
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if status.Dirty {
+		return fmt.Errorf("database schema version %d is dirty, fix it and run migrate force", status.Version)
+	}
+	if status.Behind() {
+		return fmt.Errorf("database schema is at version %d, latest is %d (%d pending); run migrate up",
+			status.Version, status.Latest, len(status.Pending))
+	}
+	return nil
+}
+
+// CheckConfig memeriksa bagian konfigurasi yang baru bisa divalidasi saat
+// aplikasi dirangkai, yaitu public key JWT dan route di
+// server.route_timeouts dan rate_limit.routes, tanpa membuka database
+func CheckConfig(cfg *config.Config) error {
+	var errs []error
+	var h handlers
+	if cfg.Auth.Enabled {
+		authenticator, err := newAuthenticator(cfg.Auth, nil, nil)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			h.authenticator = authenticator
+		}
+	}
+	if _, err := newRouter(h, cfg, metrics.New()); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// newAuthenticator membaca public key RS256 dari file jika dipakai
+func newAuthenticator(cfg config.AuthConfig, apiKeys domain.APIKeyRepository, roles domain.RoleRepository) (*auth.Authenticator, error) {
+	opts := auth.Options{
+		Algorithm: cfg.JWTAlgorithm,
+		Secret:    []byte(cfg.JWTSecret),
+		Issuer:    cfg.JWTIssuer,
+		Audience:  cfg.JWTAudience,
+	}
+	if cfg.JWTAlgorithm == "RS256" {
+		pem, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth.jwt_public_key_file: %w", err)
+		}
+		if opts.PublicKey, err = auth.ParseRSAPublicKey(pem); err != nil {
+			return nil, err
+		}
+	}
+	return auth.New(apiKeys, roles, opts)
+}
+
+func rateLimitOptions(cfg config.RateLimitConfig) handler.RateLimitOptions {
+	opts := handler.RateLimitOptions{
+		Default:           domain.RateLimitPolicy{Limit: cfg.Default.Limit, Period: cfg.Default.Period},
+		Routes:            map[string]domain.RateLimitPolicy{},
+		PerIP:             domain.RateLimitPolicy{Limit: cfg.PerIP.Limit, Period: cfg.PerIP.Period},
+		TrustForwardedFor: cfg.TrustForwardedFor,
+	}
+	for route, limit := range cfg.Routes {
+		opts.Routes[route] = domain.RateLimitPolicy{Limit: limit.Limit, Period: limit.Period}
+	}
+	return opts
+}
+
+// rateLimitIdlePeriod adalah period terpanjang dari semua policy
+func rateLimitIdlePeriod(cfg config.RateLimitConfig) time.Duration {
+	idle := cfg.Default.Period
+	if cfg.PerIP.Period > idle {
+		idle = cfg.PerIP.Period
+	}
+	for _, limit := range cfg.Routes {
+		if limit.Period > idle {
+			idle = limit.Period
+		}
+	}
+	return idle
+}
+
+// Handler mengembalikan semua route beserta middleware-nya tanpa membuka
+// listener, misalnya untuk httptest
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// Run menjalankan server sampai ctx selesai, lalu menunggu request yang
+// sedang berjalan selesai dan menutup koneksi database
+func (a *App) Run(ctx context.Context) error {
+	return a.server.Run(ctx)
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"api-otto/internal/config"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/logging"
+	"api-otto/internal/metrics"
+	"api-otto/internal/tracing"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type handlers struct {
+	health      *handler.HealthHandler
+	brand       *handler.BrandHandler
+	voucher     *handler.VoucherHandler
+	transaction *handler.TransactionHandler
+	customer    *handler.CustomerHandler
+	audit       *handler.AuditHandler
+	// idempotency bernilai nil jika fitur idempotency dimatikan
+	idempotency *handler.Idempotency
+	// authenticator bernilai nil jika autentikasi dimatikan
+	authenticator handler.Authenticator
+	// rateLimiter bernilai nil jika rate limit dimatikan
+	rateLimiter *handler.RateLimiter
+}
+
+// withIdempotency memasang Idempotency-Key pada handler jika fitur aktif
+func (h handlers) withIdempotency(scope string, next httprouter.Handle) httprouter.Handle {
+	if h.idempotency == nil {
+		return next
+	}
+	return h.idempotency.Wrap(scope, next)
+}
+
+// routes mendaftarkan handler ke router dengan deadline per route
+type routes struct {
+	router   *httprouter.Router
+	metrics  *metrics.Metrics
+	handlers handlers
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+	// known berisi semua route "METHOD /path" yang sudah didaftarkan
+	known map[string]bool
+}
+
+func (rt *routes) handle(method, path string, next httprouter.Handle) {
+	key := method + " " + path
+	timeout, ok := rt.timeouts[key]
+	if !ok {
+		timeout = rt.timeout
+	}
+	rt.known[key] = true
+	next = handler.WithTimeout(timeout, next)
+	next = tracing.Handle(method, path, next)
+	next = rt.metrics.Instrument(method, path, next)
+	rt.router.Handle(method, path, logging.WithRoute(path, next))
+}
+
+// secure mendaftarkan route yang butuh permission. Urutannya rate limit per
+// IP, autentikasi, rate limit per route lalu otorisasi. Bucket per IP
+// membatasi request yang gagal autentikasi, bucket per route dimiliki
+// principal yang sudah terverifikasi. Jika auth dimatikan, rate limit per
+// route memakai IP client dan bucket per IP tidak dipasang.
+func (rt *routes) secure(method, path string, permission domain.Permission, next httprouter.Handle) {
+	next = handler.Authorize(permission, next)
+	if rt.handlers.rateLimiter != nil {
+		next = rt.handlers.rateLimiter.Wrap(method+" "+path, next)
+	}
+	if rt.handlers.authenticator != nil {
+		next = handler.Authenticate(rt.handlers.authenticator, next)
+		if rt.handlers.rateLimiter != nil {
+			next = rt.handlers.rateLimiter.WrapIP(next)
+		}
+	}
+	rt.handle(method, path, next)
+}
+
+// newRouter mengembalikan error jika server.route_timeouts atau
+// rate_limit.routes berisi route yang tidak ada, supaya salah ketik tidak
+// diam-diam diabaikan
+func newRouter(h handlers, cfg *config.Config, m *metrics.Metrics) (*httprouter.Router, error) {
+	rt := &routes{
+		router:   httprouter.New(),
+		metrics:  m,
+		handlers: h,
+		timeout:  cfg.Server.RequestTimeout,
+		timeouts: cfg.Server.RouteTimeouts,
+		known:    map[string]bool{},
+	}
+
+	// Health routes tidak butuh autentikasi supaya bisa dipakai load
+	// balancer dan Prometheus
+	rt.handle(http.MethodGet, "/healthz", h.health.Healthz)
+	rt.handle(http.MethodGet, "/readyz", h.health.Readyz)
+	rt.handle(http.MethodGet, "/version", h.health.Version)
+	rt.handle(http.MethodGet, "/metrics", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		m.Handler().ServeHTTP(w, r)
+	})
+
+	// Brand routes
+	rt.secure(http.MethodPost, "/brand", domain.PermissionBrandWrite, h.withIdempotency("POST /brand", h.brand.Create))
+	rt.secure(http.MethodGet, "/brand/:id", domain.PermissionBrandRead, h.brand.GetByID)
+	rt.secure(http.MethodGet, "/brand", domain.PermissionBrandRead, h.brand.GetAll)
+	rt.secure(http.MethodPut, "/brand/:id", domain.PermissionBrandWrite, h.brand.Update)
+	rt.secure(http.MethodPatch, "/brand/:id", domain.PermissionBrandWrite, h.brand.Patch)
+	rt.secure(http.MethodDelete, "/brand/:id", domain.PermissionBrandWrite, h.brand.Delete)
+
+	// Voucher routes
+	rt.secure(http.MethodPost, "/voucher", domain.PermissionVoucherWrite, h.withIdempotency("POST /voucher", h.voucher.Create))
+	rt.secure(http.MethodGet, "/brand/:id/vouchers", domain.PermissionVoucherRead, h.voucher.GetByBrandID)
+	rt.secure(http.MethodGet, "/voucher/:id", domain.PermissionVoucherRead, h.voucher.GetByID)
+	rt.secure(http.MethodGet, "/voucher", domain.PermissionVoucherRead, h.voucher.List)
+	rt.secure(http.MethodPut, "/voucher/:id", domain.PermissionVoucherWrite, h.voucher.Update)
+	rt.secure(http.MethodPatch, "/voucher/:id", domain.PermissionVoucherWrite, h.voucher.Patch)
+	rt.secure(http.MethodDelete, "/voucher/:id", domain.PermissionVoucherWrite, h.voucher.Delete)
+
+	// Customer routes
+	rt.secure(http.MethodPost, "/customer", domain.PermissionCustomerWrite, h.customer.Create)
+	rt.secure(http.MethodGet, "/customer/:id", domain.PermissionCustomerRead, h.customer.GetByID)
+	rt.secure(http.MethodPost, "/customer/:id/points", domain.PermissionCustomerWrite, h.customer.CreditPoints)
+	rt.secure(http.MethodGet, "/customer/:id/points", domain.PermissionCustomerRead, h.customer.GetLedger)
+	rt.secure(http.MethodGet, "/customer/:id/transactions", domain.PermissionTransactionRead, h.transaction.GetCustomerTransactions)
+
+	// Transaction routes
+	rt.secure(http.MethodPost, "/transaction/redemption", domain.PermissionTransactionRedeem, h.withIdempotency("POST /transaction/redemption", h.transaction.CreateRedemption))
+	rt.secure(http.MethodGet, "/transaction/redemption/:id", domain.PermissionTransactionRead, h.transaction.GetTransactionByID)
+	rt.secure(http.MethodPost, "/transaction/redemption/:id/cancel", domain.PermissionTransactionRefund, h.transaction.CancelRedemption)
+	rt.secure(http.MethodPost, "/transaction/redemption/:id/items/:item_id/refund", domain.PermissionTransactionRefund, h.transaction.RefundItem)
+
+	// Audit routes
+	rt.secure(http.MethodGet, "/audit", domain.PermissionAuditRead, h.audit.List)
+
+	var errs []error
+	if err := unknownRoutes("server.route_timeouts", cfg.Server.RouteTimeouts, rt.known); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.RateLimit.Enabled {
+		if err := unknownRoutes("rate_limit.routes", cfg.RateLimit.Routes, rt.known); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rt.router, nil
+}
+
+// unknownRoutes mengembalikan error jika ada kunci overrides yang bukan
+// route terdaftar
+func unknownRoutes[V any](section string, overrides map[string]V, known map[string]bool) error {
+	var unknown []string
+	for key := range overrides {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("%s: unknown routes %q", section, unknown)
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"api-otto/database"
+	"api-otto/internal/auth"
+	"api-otto/internal/config"
+	"api-otto/internal/domain"
+	"api-otto/internal/health"
+	"api-otto/internal/metrics"
+	"api-otto/internal/migrate"
+	"api-otto/internal/repository"
+	"api-otto/internal/repository/memory"
+	"api-otto/internal/seed"
+	"api-otto/migrations"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DemoAPIKey adalah API key platform_admin yang dibuat storage memory saat
+// autentikasi aktif, supaya API lokal bisa langsung dipanggil
+const DemoAPIKey = "demo-platform-admin-key"
+
+// storage berisi repository dari satu backend penyimpanan beserta
+// readiness check dan resource yang harus ditutup
+type storage struct {
+	// db bernilai nil untuk storage memory
+	db           *sql.DB
+	brands       domain.BrandRepository
+	vouchers     domain.VoucherRepository
+	transactions domain.TransactionRepository
+	customers    domain.CustomerRepository
+	idempotency  domain.IdempotencyRepository
+	apiKeys      domain.APIKeyRepository
+	roles        domain.RoleRepository
+	audit        domain.AuditRepository
+	txManager    domain.TxManager
+	checks       []health.Check
+}
+
+func (s *storage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func openStorage(ctx context.Context, cfg *config.Config, m *metrics.Metrics) (*storage, error) {
+	if cfg.Storage == "memory" {
+		return openMemory(ctx, cfg)
+	}
+	return openPostgres(ctx, cfg, m)
+}
+
+func openPostgres(ctx context.Context, cfg *config.Config, m *metrics.Metrics) (*storage, error) {
+	db, err := database.NewPostgresConnection(cfg.Database.DSN)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if cfg.Database.RequireLatestSchema {
+		if err := checkSchema(ctx, migrator); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	m.RegisterDB("postgres", db)
+
+	return &storage{
+		db:           db,
+		brands:       repository.NewBrandRepository(db),
+		vouchers:     repository.NewVoucherRepository(db),
+		transactions: repository.NewTransactionRepository(db),
+		customers:    repository.NewCustomerRepository(db),
+		idempotency:  repository.NewIdempotencyRepository(db),
+		apiKeys:      repository.NewAPIKeyRepository(db),
+		roles:        repository.NewRoleRepository(db),
+		audit:        repository.NewAuditRepository(db),
+		txManager:    repository.NewTxManager(db),
+		checks:       []health.Check{health.Database(db), health.Migrations(migrator)},
+	}, nil
+}
+
+// openMemory mengisi storage memory dengan data demo dari package seed
+func openMemory(ctx context.Context, cfg *config.Config) (*storage, error) {
+	store := memory.NewStore()
+	s := &storage{
+		brands:       memory.NewBrandRepository(store),
+		vouchers:     memory.NewVoucherRepository(store),
+		transactions: memory.NewTransactionRepository(store),
+		customers:    memory.NewCustomerRepository(store),
+		idempotency:  memory.NewIdempotencyRepository(store),
+		apiKeys:      memory.NewAPIKeyRepository(store),
+		roles:        memory.NewRoleRepository(store),
+		audit:        memory.NewAuditRepository(store),
+		txManager:    memory.NewTxManager(store),
+	}
+
+	if _, err := seed.Run(ctx, s.txManager, time.Now()); err != nil {
+		return nil, fmt.Errorf("seed memory storage: %w", err)
+	}
+	slog.Warn("using memory storage with demo data, every change is lost on restart")
+	if cfg.Auth.Enabled {
+		key := &domain.APIKey{Name: "demo", KeyHash: auth.HashAPIKey(DemoAPIKey), Role: domain.RolePlatformAdmin}
+		if err := s.apiKeys.Create(ctx, key); err != nil {
+			return nil, err
+		}
+		slog.Warn("memory storage accepts a demo platform_admin API key", "header", "X-API-Key", "api_key", DemoAPIKey)
+	}
+	return s, nil
+}
@@ -0,0 +1,176 @@
+// Package auth memverifikasi kredensial request. Integrasi partner dan
+// back-office memakai API key statis di header X-API-Key, aplikasi customer
+// memakai JWT HS256 atau RS256 di header "Authorization: Bearer" dengan ID
+// customer sebagai klaim sub.
+package auth
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	// apiKeyPrefix memudahkan key dikenali, misalnya oleh secret scanner
+	apiKeyPrefix = "ak_"
+	// tokenLeeway menoleransi selisih jam antara server dan penerbit token
+	tokenLeeway = 30 * time.Second
+)
+
+// Options mengatur verifikasi JWT, sama dengan config.AuthConfig
+type Options struct {
+	// Algorithm adalah "HS256" atau "RS256"
+	Algorithm string
+	// Secret dipakai HS256. Jika kosong semua JWT ditolak dan hanya API key
+	// yang bisa dipakai.
+	Secret []byte
+	// PublicKey dipakai RS256
+	PublicKey *rsa.PublicKey
+	// Issuer dan Audience dicocokkan dengan klaim iss dan aud jika diisi
+	Issuer   string
+	Audience string
+}
+
+type Authenticator struct {
+	apiKeys domain.APIKeyRepository
+	roles   domain.RoleRepository
+	parser  *jwt.Parser
+	// key bernilai nil jika JWT tidak dikonfigurasi
+	key interface{}
+}
+
+// New membuat Authenticator. roles dipakai untuk memuat permission role
+// principal di setiap request, sehingga perubahan role_permissions langsung
+// berlaku.
+func New(apiKeys domain.APIKeyRepository, roles domain.RoleRepository, opts Options) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: apiKeys, roles: roles}
+	switch opts.Algorithm {
+	case "HS256":
+		if len(opts.Secret) > 0 {
+			a.key = opts.Secret
+		}
+	case "RS256":
+		if opts.PublicKey == nil {
+			return nil, errors.New("auth: RS256 requires a public key")
+		}
+		a.key = opts.PublicKey
+	default:
+		return nil, fmt.Errorf("auth: unsupported JWT algorithm %q", opts.Algorithm)
+	}
+
+	// Hanya algoritma yang dikonfigurasi yang diterima, supaya token "none"
+	// atau HS256 yang ditandatangani dengan public key RS256 ditolak
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{opts.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokenLeeway),
+	}
+	if opts.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(opts.Audience))
+	}
+	a.parser = jwt.NewParser(parserOptions...)
+	return a, nil
+}
+
+// Authenticate mengembalikan principal beserta permission role-nya dari
+// X-API-Key atau bearer token. Request tanpa keduanya mendapat
+// domain.ErrAuthenticationRequired.
+func (a *Authenticator) Authenticate(r *http.Request) (*domain.Principal, error) {
+	var principal *domain.Principal
+	var err error
+	scheme, token, bearer := strings.Cut(r.Header.Get("Authorization"), " ")
+	switch key := r.Header.Get(APIKeyHeader); {
+	case key != "":
+		principal, err = a.apiKey(r.Context(), key)
+	case bearer && strings.EqualFold(scheme, "Bearer"):
+		principal, err = a.token(r.Context(), strings.TrimSpace(token))
+	default:
+		return nil, domain.ErrAuthenticationRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if principal.Permissions, err = a.roles.Permissions(r.Context(), principal.Role); err != nil {
+		return nil, err
+	}
+	return principal, nil
+}
+
+func (a *Authenticator) apiKey(ctx context.Context, key string) (*domain.Principal, error) {
+	apiKey, err := a.apiKeys.GetActiveByHash(ctx, HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	principal := &domain.Principal{Type: domain.PrincipalAPIKey, ID: apiKey.ID, Name: apiKey.Name, Role: apiKey.Role}
+	if apiKey.BrandID != nil {
+		principal.BrandID = *apiKey.BrandID
+	}
+	return principal, nil
+}
+
+func (a *Authenticator) token(ctx context.Context, raw string) (*domain.Principal, error) {
+	if a.key == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	var claims jwt.RegisteredClaims
+	if _, err := a.parser.ParseWithClaims(raw, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.key, nil
+	}); err != nil {
+		slog.DebugContext(ctx, "token rejected", "error", err)
+		return nil, domain.ErrInvalidToken
+	}
+
+	customerID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || customerID <= 0 {
+		slog.DebugContext(ctx, "token rejected", "error", "sub is not a customer ID")
+		return nil, domain.ErrInvalidToken
+	}
+	return &domain.Principal{Type: domain.PrincipalCustomer, ID: customerID, Role: domain.RoleCustomer}, nil
+}
+
+// GenerateAPIKey membuat API key acak baru. Yang disimpan ke database
+// hanya HashAPIKey dari hasilnya.
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashAPIKey mengembalikan SHA-256 (hex) dari key. Key cukup acak sehingga
+// hash tanpa salt aman dan bisa dicari langsung dengan indeks.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseRSAPublicKey membaca public key RS256 dalam format PEM
+func ParseRSAPublicKey(pem []byte) (*rsa.PublicKey, error) {
+	key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	return key, nil
+}
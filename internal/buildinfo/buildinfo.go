@@ -0,0 +1,49 @@
+// Package buildinfo menyimpan informasi build yang diisi saat kompilasi:
+//
+//	go build -ldflags "-X api-otto/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	    -X api-otto/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Commit dan BuildTime diisi lewat -ldflags -X. Jika kosong, Commit diambil
+// dari informasi VCS yang disematkan go build bila tersedia.
+var (
+	Commit    = ""
+	BuildTime = ""
+)
+
+const unknown = "unknown"
+
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+func Get() Info {
+	info := Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" && info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = unknown
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = unknown
+	}
+	return info
+}
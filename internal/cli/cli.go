@@ -0,0 +1,279 @@
+// Package cli memetakan subcommand binary ke aplikasi: serve menjalankan
+// HTTP server, migrate mengelola skema dari migrasi yang disematkan, seed
+// mengisi data demo, create-api-key menerbitkan API key dan check-config
+// memvalidasi konfigurasi.
+package cli
+
+import (
+	"api-otto/database"
+	"api-otto/internal/app"
+	"api-otto/internal/auth"
+	"api-otto/internal/config"
+	"api-otto/internal/domain"
+	"api-otto/internal/migrate"
+	"api-otto/internal/repository"
+	"api-otto/internal/seed"
+	"api-otto/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Usage dicetak saat command tidak dikenal atau argumennya salah
+const Usage = `Usage: api-otto [flags] [command]
+
+Commands:
+  serve              run the HTTP server (default)
+  migrate up         apply all pending migrations
+  migrate down [N]   roll back the last N migrations (default 1)
+  migrate status     print the current schema version and pending migrations
+  migrate goto N     migrate up or down to version N, 0 rolls back everything
+  migrate force N    record version N as clean without running SQL
+  seed               insert demo brands, vouchers and customers
+  create-api-key NAME ROLE [BRAND_ID]
+                     create an API key and print it once, ROLE is
+                     platform_admin or brand_manager (needs BRAND_ID)
+  check-config       validate the config and exit
+  help               print this help
+
+Flags must come before the command, run "api-otto -h" to list them.
+`
+
+// ErrUsage dibungkus oleh error karena command atau argumen yang salah
+var ErrUsage = errors.New("invalid usage")
+
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// Run menjalankan command dari args, yaitu argumen sisa setelah flag. Tanpa
+// command, serve dijalankan supaya perilaku lama tetap sama.
+func Run(ctx context.Context, cfg *config.Config, args []string, stdout io.Writer) error {
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	// Storage memory sudah berisi data demo dan tidak punya skema
+	if (command == "migrate" || command == "seed" || command == "create-api-key") && cfg.Storage != "postgres" {
+		return fmt.Errorf("%s needs storage postgres, storage is %s", command, cfg.Storage)
+	}
+
+	switch command {
+	case "serve":
+		if len(args) > 0 {
+			return usageError("serve takes no arguments")
+		}
+		return serve(ctx, cfg)
+	case "migrate":
+		return runMigrate(ctx, cfg, args, stdout)
+	case "seed":
+		if len(args) > 0 {
+			return usageError("seed takes no arguments")
+		}
+		return runSeed(ctx, cfg, stdout)
+	case "create-api-key":
+		return runCreateAPIKey(ctx, cfg, args, stdout)
+	case "check-config":
+		if len(args) > 0 {
+			return usageError("check-config takes no arguments")
+		}
+		if err := app.CheckConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "config is valid")
+		return nil
+	case "help":
+		fmt.Fprint(stdout, Usage)
+		return nil
+	}
+	return usageError("unknown command %q", command)
+}
+
+func serve(ctx context.Context, cfg *config.Config) error {
+	application, err := app.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+	if err := application.Run(ctx); err != nil {
+		return fmt.Errorf("server stopped with error: %w", err)
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// runMigrate memvalidasi argumen sebelum membuka database supaya salah
+// ketik tidak butuh koneksi
+func runMigrate(ctx context.Context, cfg *config.Config, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return usageError("migrate needs a subcommand: up, down, status, goto or force")
+	}
+	sub, args := args[0], args[1:]
+
+	var run func(m *migrate.Migrator) error
+	switch sub {
+	case "up":
+		if len(args) > 0 {
+			return usageError("migrate up takes no arguments")
+		}
+		run = func(m *migrate.Migrator) error {
+			applied, err := m.Up(ctx)
+			return printSteps(stdout, applied, err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			return usageError("migrate down takes at most one argument")
+		}
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return usageError("migrate down: N must be a positive number, got %q", args[0])
+			}
+			steps = n
+		}
+		run = func(m *migrate.Migrator) error {
+			applied, err := m.Down(ctx, steps)
+			return printSteps(stdout, applied, err)
+		}
+	case "status":
+		if len(args) > 0 {
+			return usageError("migrate status takes no arguments")
+		}
+		run = func(m *migrate.Migrator) error {
+			return printStatus(ctx, stdout, m)
+		}
+	case "goto", "force":
+		if len(args) != 1 {
+			return usageError("migrate %s needs exactly one version", sub)
+		}
+		version, err := strconv.ParseUint(args[0], 10, 63)
+		if err != nil {
+			return usageError("migrate %s: invalid version %q", sub, args[0])
+		}
+		run = func(m *migrate.Migrator) error {
+			if sub == "force" {
+				if err := m.Force(ctx, version); err != nil {
+					return err
+				}
+				fmt.Fprintf(stdout, "forced version %d\n", version)
+				return nil
+			}
+			applied, err := m.Goto(ctx, version)
+			return printSteps(stdout, applied, err)
+		}
+	default:
+		return usageError("unknown migrate subcommand %q", sub)
+	}
+
+	db, err := database.NewPostgresConnection(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	return run(migrator)
+}
+
+// printSteps mencetak migrasi yang sudah dijalankan, juga saat migrasi
+// berikutnya gagal
+func printSteps(w io.Writer, steps []migrate.Step, err error) error {
+	for _, step := range steps {
+		fmt.Fprintf(w, "%s %s\n", step.Migration, step.Direction)
+	}
+	if err == nil && len(steps) == 0 {
+		fmt.Fprintln(w, "no change")
+	}
+	return err
+}
+
+func printStatus(ctx context.Context, w io.Writer, m *migrate.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "version: %d\n", status.Version)
+	fmt.Fprintf(w, "dirty: %t\n", status.Dirty)
+	fmt.Fprintf(w, "latest: %d\n", status.Latest)
+	fmt.Fprintf(w, "pending: %d\n", len(status.Pending))
+	for _, migration := range status.Pending {
+		fmt.Fprintf(w, "  %s\n", migration)
+	}
+	return nil
+}
+
+func runSeed(ctx context.Context, cfg *config.Config, stdout io.Writer) error {
+	db, err := database.NewPostgresConnection(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	seeded, err := seed.Run(ctx, repository.NewTxManager(db), time.Now())
+	if err != nil {
+		return err
+	}
+	if !seeded {
+		fmt.Fprintln(stdout, "demo data already exists, nothing to do")
+		return nil
+	}
+	fmt.Fprintln(stdout, "demo data created")
+	return nil
+}
+
+// runCreateAPIKey menyimpan hash key baru beserta role dan brand-nya. Key
+// asli hanya dicetak sekali dan tidak bisa diambil lagi dari database.
+func runCreateAPIKey(ctx context.Context, cfg *config.Config, args []string, stdout io.Writer) error {
+	if len(args) < 2 || len(args) > 3 {
+		return usageError("create-api-key needs NAME, ROLE and for brand_manager BRAND_ID")
+	}
+	key := &domain.APIKey{Name: args[0], Role: domain.Role(args[1])}
+	if key.Name == "" {
+		return usageError("create-api-key: NAME must not be empty")
+	}
+	switch key.Role {
+	case domain.RolePlatformAdmin:
+		if len(args) == 3 {
+			return usageError("create-api-key: platform_admin keys are not scoped to a brand")
+		}
+	case domain.RoleBrandManager:
+		if len(args) != 3 {
+			return usageError("create-api-key: brand_manager needs BRAND_ID")
+		}
+		brandID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil || brandID < 1 {
+			return usageError("create-api-key: BRAND_ID must be a positive number, got %q", args[2])
+		}
+		key.BrandID = &brandID
+	default:
+		return usageError("create-api-key: ROLE must be platform_admin or brand_manager, got %q", args[1])
+	}
+
+	plaintext, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	key.KeyHash = auth.HashAPIKey(plaintext)
+
+	db, err := database.NewPostgresConnection(cfg.Database.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := repository.NewAPIKeyRepository(db).Create(ctx, key); err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	fmt.Fprintf(stdout, "api key %d created for %s\n", key.ID, key.Name)
+	fmt.Fprintln(stdout, plaintext)
+	fmt.Fprintln(stdout, "store the key now, it is not shown again")
+	return nil
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// option menghubungkan satu field konfigurasi dengan flag dan environment
+// variable-nya. field mengembalikan pointer ke field pada c.
+type option struct {
+	flag  string
+	env   string
+	usage string
+	field func(c *Config) interface{}
+}
+
+var options = []option{
+	{"addr", "HTTP_ADDR", "HTTP listen address", func(c *Config) interface{} { return &c.Server.Addr }},
+	{"read-timeout", "HTTP_READ_TIMEOUT", "maximum duration for reading a request", func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"write-timeout", "HTTP_WRITE_TIMEOUT", "maximum duration for writing a response", func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"idle-timeout", "HTTP_IDLE_TIMEOUT", "keep-alive idle timeout", func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"shutdown-timeout", "HTTP_SHUTDOWN_TIMEOUT", "how long to wait for in-flight requests on SIGTERM", func(c *Config) interface{} { return &c.Server.ShutdownTimeout }},
+	{"drain-delay", "HTTP_DRAIN_DELAY", "how long /readyz fails before the server stops accepting connections on SIGTERM", func(c *Config) interface{} { return &c.Server.DrainDelay }},
+	{"request-timeout", "HTTP_REQUEST_TIMEOUT", "default deadline for each request", func(c *Config) interface{} { return &c.Server.RequestTimeout }},
+	{"route-timeout", "HTTP_ROUTE_TIMEOUTS", `per-route deadline as "METHOD /path=duration", repeatable; env takes a comma-separated list`, func(c *Config) interface{} { return &c.Server.RouteTimeouts }},
+	{"db-dsn", "DATABASE_URL", "PostgreSQL connection string", func(c *Config) interface{} { return &c.Database.DSN }},
+	{"db-max-open-conns", "DB_MAX_OPEN_CONNS", "maximum open database connections (0 = unlimited)", func(c *Config) interface{} { return &c.Database.MaxOpenConns }},
+	{"db-max-idle-conns", "DB_MAX_IDLE_CONNS", "maximum idle database connections", func(c *Config) interface{} { return &c.Database.MaxIdleConns }},
+	{"db-conn-max-lifetime", "DB_CONN_MAX_LIFETIME", "maximum lifetime of a database connection", func(c *Config) interface{} { return &c.Database.ConnMaxLifetime }},
+	{"db-conn-max-idle-time", "DB_CONN_MAX_IDLE_TIME", "maximum idle time of a database connection", func(c *Config) interface{} { return &c.Database.ConnMaxIdleTime }},
+	{"db-require-latest-schema", "DB_REQUIRE_LATEST_SCHEMA", "refuse to serve while migrations are pending or the schema is dirty", func(c *Config) interface{} { return &c.Database.RequireLatestSchema }},
+	{"log-level", "LOG_LEVEL", "log level: debug, info, warn or error", func(c *Config) interface{} { return &c.Log.Level }},
+	{"idempotency-key-ttl", "IDEMPOTENCY_KEY_TTL", "how long an Idempotency-Key is remembered", func(c *Config) interface{} { return &c.Idempotency.KeyTTL }},
+	{"feature-idempotency", "FEATURE_IDEMPOTENCY", "enable Idempotency-Key support", func(c *Config) interface{} { return &c.Features.Idempotency }},
+	{"tracing-exporter", "TRACING_EXPORTER", "span exporter: none, stdout, file or otlp", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"tracing-file", "TRACING_FILE", "file the file exporter appends spans to", func(c *Config) interface{} { return &c.Tracing.File }},
+	{"tracing-endpoint", "TRACING_ENDPOINT", "OTLP/HTTP endpoint URL, e.g. http://localhost:4318", func(c *Config) interface{} { return &c.Tracing.Endpoint }},
+	{"tracing-service-name", "TRACING_SERVICE_NAME", "service.name resource attribute of every span", func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+	{"auth-enabled", "AUTH_ENABLED", "require an API key or JWT on every endpoint except health and metrics", func(c *Config) interface{} { return &c.Auth.Enabled }},
+	{"jwt-algorithm", "JWT_ALGORITHM", "customer token algorithm: HS256 or RS256", func(c *Config) interface{} { return &c.Auth.JWTAlgorithm }},
+	{"jwt-secret", "JWT_SECRET", "HS256 signing secret, at least 32 bytes; empty disables customer tokens", func(c *Config) interface{} { return &c.Auth.JWTSecret }},
+	{"jwt-public-key-file", "JWT_PUBLIC_KEY_FILE", "PEM file with the RS256 public key", func(c *Config) interface{} { return &c.Auth.JWTPublicKeyFile }},
+	{"jwt-issuer", "JWT_ISSUER", "required iss claim of customer tokens, empty accepts any", func(c *Config) interface{} { return &c.Auth.JWTIssuer }},
+	{"jwt-audience", "JWT_AUDIENCE", "required aud claim of customer tokens, empty accepts any", func(c *Config) interface{} { return &c.Auth.JWTAudience }},
+	{"rate-limit-enabled", "RATE_LIMIT_ENABLED", "limit requests per client with a token bucket", func(c *Config) interface{} { return &c.RateLimit.Enabled }},
+	{"rate-limit-backend", "RATE_LIMIT_BACKEND", "token bucket storage: memory (per instance) or postgres (shared)", func(c *Config) interface{} { return &c.RateLimit.Backend }},
+	{"rate-limit-trust-forwarded-for", "RATE_LIMIT_TRUST_FORWARDED_FOR", "use the last X-Forwarded-For entry as the client IP, only behind a proxy", func(c *Config) interface{} { return &c.RateLimit.TrustForwardedFor }},
+	{"rate-limit-default", "RATE_LIMIT_DEFAULT", `default limit per client and route as "limit/period", e.g. 300/1m`, func(c *Config) interface{} { return &c.RateLimit.Default }},
+	{"rate-limit-per-ip", "RATE_LIMIT_PER_IP", `limit per client IP across all routes before authentication, e.g. 3000/1m`, func(c *Config) interface{} { return &c.RateLimit.PerIP }},
+	{"route-rate-limit", "RATE_LIMIT_ROUTES", `per-route limit as "METHOD /path=limit/period", repeatable; env takes a comma-separated list`, func(c *Config) interface{} { return &c.RateLimit.Routes }},
+	{"storage", "STORAGE", "where data is kept: postgres, or memory with demo data for local development", func(c *Config) interface{} { return &c.Storage }},
+	{"default-language", "DEFAULT_LANGUAGE", "response language when Accept-Language is missing: en or id", func(c *Config) interface{} { return &c.DefaultLanguage }},
+}
+
+// ConfigFileEnv menunjuk file konfigurasi jika flag --config tidak dipakai
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load membaca konfigurasi dari file, environment dan args lalu
+// memvalidasinya. lookupEnv biasanya os.LookupEnv. Flag yang bukan bagian
+// dari Config, seperti --print-config, dikembalikan lewat Flags.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, *Flags, error) {
+	// Tahap pertama hanya mencari lokasi file, nilai field lain dibuang
+	pre, preFlags := newFlagSet(Default())
+	pre.SetOutput(io.Discard)
+	if err := pre.Parse(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+	path := preFlags.ConfigFile
+	if path == "" {
+		path, _ = lookupEnv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := applyEnv(cfg, lookupEnv); err != nil {
+		return nil, nil, err
+	}
+
+	fs, flags := newFlagSet(cfg)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	flags.ConfigFile = path
+	flags.Args = fs.Args()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, flags, nil
+}
+
+// Flags berisi flag yang bukan bagian dari Config
+type Flags struct {
+	ConfigFile  string
+	PrintConfig bool
+	// Args adalah argumen sisa setelah flag
+	Args []string
+}
+
+func newFlagSet(cfg *Config) (*flag.FlagSet, *Flags) {
+	fs := flag.NewFlagSet("api-otto", flag.ContinueOnError)
+	flags := &Flags{}
+	fs.StringVar(&flags.ConfigFile, "config", "", "path to a YAML or JSON config file (env "+ConfigFileEnv+")")
+	fs.BoolVar(&flags.PrintConfig, "print-config", false, "print the effective config with secrets redacted and exit")
+
+	for _, opt := range options {
+		usage := fmt.Sprintf("%s (env %s)", opt.usage, opt.env)
+		switch field := opt.field(cfg).(type) {
+		case *string:
+			fs.StringVar(field, opt.flag, *field, usage)
+		case *int:
+			fs.IntVar(field, opt.flag, *field, usage)
+		case *bool:
+			fs.BoolVar(field, opt.flag, *field, usage)
+		case *time.Duration:
+			fs.DurationVar(field, opt.flag, *field, usage)
+		case *map[string]time.Duration:
+			fs.Var((*durationMap)(field), opt.flag, usage)
+		case *RateLimit:
+			fs.Var(field, opt.flag, usage)
+		case *map[string]RateLimit:
+			fs.Var((*rateLimitMap)(field), opt.flag, usage)
+		}
+	}
+	return fs, flags
+}
+
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+	for _, opt := range options {
+		raw, ok := lookupEnv(opt.env)
+		if !ok || raw == "" {
+			continue
+		}
+
+		var err error
+		switch field := opt.field(cfg).(type) {
+		case *string:
+			*field = raw
+		case *int:
+			*field, err = strconv.Atoi(raw)
+		case *bool:
+			*field, err = strconv.ParseBool(raw)
+		case *time.Duration:
+			*field, err = time.ParseDuration(raw)
+		case *map[string]time.Duration:
+			for _, entry := range strings.Split(raw, ",") {
+				if err = (*durationMap)(field).Set(entry); err != nil {
+					break
+				}
+			}
+		case *RateLimit:
+			err = field.Set(raw)
+		case *map[string]RateLimit:
+			for _, entry := range strings.Split(raw, ",") {
+				if err = (*rateLimitMap)(field).Set(entry); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s=%q: %v", opt.env, raw, unwrapNumError(err)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadFile membaca file YAML atau JSON. File JSON diubah dulu menjadi YAML
+// supaya keduanya memakai decoder yang sama, termasuk durasi seperti "5s"
+// dan penolakan field yang tidak dikenal.
+func loadFile(path string, cfg *Config) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return fmt.Errorf("config file %s: unsupported extension %q, use .yaml, .yml or .json", path, ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	if ext == ".json" {
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(document); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// durationMap adalah flag.Value untuk entri "kunci=durasi" yang bisa diulang
+type durationMap map[string]time.Duration
+
+func (m *durationMap) String() string {
+	if m == nil || *m == nil {
+		return ""
+	}
+	entries := make([]string, 0, len(*m))
+	for key, value := range *m {
+		entries = append(entries, key+"="+value.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (m *durationMap) Set(entry string) error {
+	key, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok {
+		return fmt.Errorf("%q must be key=duration", entry)
+	}
+	value, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = durationMap{}
+	}
+	(*m)[strings.TrimSpace(key)] = value
+	return nil
+}
+
+// rateLimitMap adalah flag.Value untuk entri "kunci=limit/period" yang bisa
+// diulang
+type rateLimitMap map[string]RateLimit
+
+func (m *rateLimitMap) String() string {
+	if m == nil || *m == nil {
+		return ""
+	}
+	entries := make([]string, 0, len(*m))
+	for key, value := range *m {
+		entries = append(entries, key+"="+value.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (m *rateLimitMap) Set(entry string) error {
+	key, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok {
+		return fmt.Errorf("%q must be key=limit/period", entry)
+	}
+	var value RateLimit
+	if err := value.Set(raw); err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = rateLimitMap{}
+	}
+	(*m)[strings.TrimSpace(key)] = value
+	return nil
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "time"
+)
+
+var (
+    ErrInvalidAuditEntity  = NewError(ErrValidation, "INVALID_AUDIT_ENTITY", "entity must be brand, voucher or transaction")
+    ErrAuditEntityRequired = NewError(ErrValidation, "AUDIT_ENTITY_REQUIRED", "entity is required when id is set")
+)
+
+// AuditEntityType adalah jenis data yang perubahannya dicatat
+type AuditEntityType string
+
+const (
+    AuditEntityBrand       AuditEntityType = "brand"
+    AuditEntityVoucher     AuditEntityType = "voucher"
+    AuditEntityTransaction AuditEntityType = "transaction"
+)
+
+// Valid melaporkan apakah t adalah jenis data yang dicatat
+func (t AuditEntityType) Valid() bool {
+    switch t {
+    case AuditEntityBrand, AuditEntityVoucher, AuditEntityTransaction:
+        return true
+    }
+    return false
+}
+
+type AuditAction string
+
+const (
+    AuditActionCreate       AuditAction = "create"
+    AuditActionUpdate       AuditAction = "update"
+    AuditActionDelete       AuditAction = "delete"
+    AuditActionStatusChange AuditAction = "status_change"
+    // AuditActionItemRefund dicatat pada transaksi untuk setiap item yang
+    // direfund, baik lewat refund item maupun pembatalan
+    AuditActionItemRefund AuditAction = "item_refund"
+)
+
+// AuditEntry adalah satu perubahan data. Before dan After hanya berisi field
+// yang berubah; Before kosong untuk create dan After kosong untuk delete.
+type AuditEntry struct {
+    ID         int64           `json:"id"`
+    Actor      string          `json:"actor"`
+    RequestID  string          `json:"request_id,omitempty"`
+    EntityType AuditEntityType `json:"entity_type"`
+    EntityID   int64           `json:"entity_id"`
+    Action     AuditAction     `json:"action"`
+    Before     json.RawMessage `json:"before,omitempty"`
+    After      json.RawMessage `json:"after,omitempty"`
+    CreatedAt  time.Time       `json:"created_at"`
+}
+
+// auditIgnoredFields tidak ikut dibandingkan karena selalu berubah dan
+// waktunya sudah tercatat di CreatedAt
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// SetDiff mengisi Before dan After dari data sebelum dan sesudah perubahan.
+// before atau after boleh nil. Jika keduanya ada, hanya field JSON yang
+// nilainya berbeda yang disimpan.
+func (e *AuditEntry) SetDiff(before, after interface{}) error {
+    beforeFields, err := auditFields(before)
+    if err != nil {
+        return err
+    }
+    afterFields, err := auditFields(after)
+    if err != nil {
+        return err
+    }
+
+    if beforeFields != nil && afterFields != nil {
+        for field, value := range beforeFields {
+            if auditIgnoredFields[field] || bytes.Equal(value, afterFields[field]) {
+                delete(beforeFields, field)
+                delete(afterFields, field)
+            }
+        }
+        for field := range afterFields {
+            if auditIgnoredFields[field] {
+                delete(afterFields, field)
+            }
+        }
+    }
+
+    if e.Before, err = marshalAuditFields(beforeFields); err != nil {
+        return err
+    }
+    e.After, err = marshalAuditFields(afterFields)
+    return err
+}
+
+func auditFields(value interface{}) (map[string]json.RawMessage, error) {
+    if value == nil {
+        return nil, nil
+    }
+    raw, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+    fields := map[string]json.RawMessage{}
+    if err := json.Unmarshal(raw, &fields); err != nil {
+        return nil, err
+    }
+    return fields, nil
+}
+
+func marshalAuditFields(fields map[string]json.RawMessage) (json.RawMessage, error) {
+    if fields == nil {
+        return nil, nil
+    }
+    return json.Marshal(fields)
+}
+
+// AuditFilter membatasi audit log ke satu jenis data dan, jika EntityID
+// diisi, ke satu data tertentu
+type AuditFilter struct {
+    EntityType AuditEntityType
+    EntityID   *int64
+    Page
+}
+
+// Validate memastikan EntityType dikenal dan diisi jika EntityID dipakai
+func (f AuditFilter) Validate() error {
+    if f.EntityType == "" {
+        if f.EntityID != nil {
+            return ErrAuditEntityRequired
+        }
+        return nil
+    }
+    if !f.EntityType.Valid() {
+        return ErrInvalidAuditEntity
+    }
+    return nil
+}
+
+type AuditRepository interface {
+    Create(ctx context.Context, entry *AuditEntry) error
+    // List mengembalikan satu halaman audit log, default yang terbaru lebih
+    // dulu, beserta cursor halaman berikutnya
+    List(ctx context.Context, filter AuditFilter) ([]AuditEntry, string, error)
+}
+
+type AuditService interface {
+    List(ctx context.Context, filter AuditFilter) ([]AuditEntry, string, error)
+}
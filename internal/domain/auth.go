@@ -0,0 +1,85 @@
+package domain
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// PrincipalType membedakan pemilik kredensial sebuah request
+type PrincipalType string
+
+const (
+    // PrincipalAPIKey adalah integrasi partner atau back-office
+    PrincipalAPIKey PrincipalType = "api_key"
+    // PrincipalCustomer adalah aplikasi customer yang memakai JWT
+    PrincipalCustomer PrincipalType = "customer"
+)
+
+// Principal adalah pihak yang sudah terautentikasi. ID adalah ID API key
+// untuk PrincipalAPIKey atau ID customer untuk PrincipalCustomer.
+type Principal struct {
+    Type PrincipalType
+    ID   int64
+    // Name adalah nama API key, kosong untuk customer
+    Name string
+    Role Role
+    // BrandID diisi jika principal hanya boleh mengakses satu brand
+    BrandID     int64
+    Permissions []Permission
+}
+
+// Can melaporkan apakah role principal memiliki permission
+func (p *Principal) Can(permission Permission) bool {
+    for _, granted := range p.Permissions {
+        if granted == permission {
+            return true
+        }
+    }
+    return false
+}
+
+// String mengembalikan identitas principal, misalnya "customer:42"
+func (p *Principal) String() string {
+    return fmt.Sprintf("%s:%d", p.Type, p.ID)
+}
+
+type principalKey struct{}
+
+// WithPrincipal menyimpan principal request ke context
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+    return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext mengembalikan principal request, false jika request
+// tidak terautentikasi atau autentikasi dimatikan
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+    principal, ok := ctx.Value(principalKey{}).(*Principal)
+    return principal, ok
+}
+
+// APIKey adalah API key statis. KeyHash adalah SHA-256 (hex) dari key asli,
+// key asli tidak pernah disimpan.
+type APIKey struct {
+    ID      int64
+    Name    string
+    KeyHash string
+    Role    Role
+    // BrandID membatasi key ke satu brand, nil untuk key tanpa batasan brand
+    BrandID   *int64
+    CreatedAt time.Time
+    RevokedAt *time.Time
+}
+
+type APIKeyRepository interface {
+    Create(ctx context.Context, key *APIKey) error
+    // GetActiveByHash mengembalikan nil, nil jika key tidak ada atau sudah
+    // dicabut
+    GetActiveByHash(ctx context.Context, keyHash string) (*APIKey, error)
+}
+
+var (
+    ErrAuthenticationRequired = NewError(ErrUnauthenticated, "AUTHENTICATION_REQUIRED", "authentication is required")
+    ErrInvalidAPIKey          = NewError(ErrUnauthenticated, "INVALID_API_KEY", "API key is invalid or revoked")
+    ErrInvalidToken           = NewError(ErrUnauthenticated, "INVALID_TOKEN", "token is invalid or expired")
+)
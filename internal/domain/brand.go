@@ -1,27 +1,61 @@
 package domain
 
-import "time"
+import (
+    "context"
+    "time"
+)
+
+var (
+    ErrBrandNotFound    = NewError(ErrNotFound, "BRAND_NOT_FOUND", "brand not found")
+    ErrBrandHasVouchers = NewError(ErrConflict, "BRAND_HAS_VOUCHERS", "brand still has vouchers")
+)
+
+// DefaultCancellationWindowMinutes dipakai jika brand dibuat tanpa
+// cancellation_window_minutes
+const DefaultCancellationWindowMinutes = 24 * 60
 
 type Brand struct {
-    ID          int64     `json:"id"`
-    Name        string    `json:"name" validate:"required,min=3,max=200"`
-    Description string    `json:"description"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID                        int64     `json:"id"`
+    Name                      string    `json:"name" validate:"required,min=3,max=200"`
+    Description               string    `json:"description"`
+    // CancellationWindowMinutes adalah batas waktu pembatalan redemption
+    // voucher brand ini, dihitung sejak transaksi dibuat. 0 = tidak bisa dibatalkan
+    CancellationWindowMinutes *int      `json:"cancellation_window_minutes,omitempty" validate:"omitempty,gte=0"`
+    CreatedAt                 time.Time `json:"created_at"`
+    UpdatedAt                 time.Time `json:"updated_at"`
+}
+
+// CancellationWindow mengembalikan batas waktu pembatalan brand
+func (b *Brand) CancellationWindow() time.Duration {
+    minutes := DefaultCancellationWindowMinutes
+    if b.CancellationWindowMinutes != nil {
+        minutes = *b.CancellationWindowMinutes
+    }
+    return time.Duration(minutes) * time.Minute
+}
+
+// BrandFilter adalah filter untuk daftar brand. Name mencari sebagian nama
+// tanpa membedakan huruf besar kecil.
+type BrandFilter struct {
+    Name    string
+    Created TimeRange
+    Page
 }
 
 type BrandRepository interface {
-    Create(brand *Brand) error
-    GetByID(id int64) (*Brand, error)
-    Update(brand *Brand) error
-    Delete(id int64) error
-    List() ([]Brand, error)
+    Create(ctx context.Context, brand *Brand) error
+    GetByID(ctx context.Context, id int64) (*Brand, error)
+    Update(ctx context.Context, brand *Brand) error
+    Delete(ctx context.Context, id int64) error
+    // List mengembalikan satu halaman brand beserta cursor halaman berikutnya,
+    // cursor kosong berarti tidak ada halaman lagi
+    List(ctx context.Context, filter BrandFilter) ([]Brand, string, error)
 }
 
 type BrandService interface {
-    Create(brand *Brand) error
-    GetByID(id int64) (*Brand, error)
-    Update(brand *Brand) error
-    Delete(id int64) error
-    List() ([]Brand, error)
+    Create(ctx context.Context, brand *Brand) error
+    GetByID(ctx context.Context, id int64) (*Brand, error)
+    Update(ctx context.Context, brand *Brand) error
+    Delete(ctx context.Context, id int64) error
+    List(ctx context.Context, filter BrandFilter) ([]Brand, string, error)
 } 
\ No newline at end of file
@@ -0,0 +1,65 @@
+package domain
+
+import (
+    "context"
+    "time"
+)
+
+var (
+    ErrCustomerNotFound    = NewError(ErrNotFound, "CUSTOMER_NOT_FOUND", "customer not found")
+    ErrCustomerEmailExists = NewError(ErrConflict, "CUSTOMER_EMAIL_EXISTS", "customer email already exists")
+    ErrInsufficientPoints  = NewError(ErrInsufficientBalance, "INSUFFICIENT_POINTS", "insufficient points")
+    ErrPointsNotPositive   = NewError(ErrValidation, "POINTS_NOT_POSITIVE", "points must be greater than 0")
+)
+
+type Customer struct {
+    ID            int64     `json:"id"`
+    Name          string    `json:"name" validate:"required,min=3,max=255"`
+    Email         string    `json:"email" validate:"omitempty,email"`
+    PointsBalance int       `json:"points_balance"`
+    CreatedAt     time.Time `json:"created_at"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type PointsEntryType string
+
+const (
+    PointsEntryCredit PointsEntryType = "credit"
+    PointsEntryDebit  PointsEntryType = "debit"
+)
+
+const (
+    PointsReasonRedemption   = "redemption"
+    PointsReasonCancellation = "cancellation"
+    PointsReasonRefund       = "refund"
+)
+
+// PointsLedgerEntry adalah satu baris di ledger poin. Ledger bersifat
+// append-only; saldo customer adalah jumlah credit dikurangi debit.
+type PointsLedgerEntry struct {
+    ID            int64           `json:"id"`
+    CustomerID    int64           `json:"customer_id"`
+    EntryType     PointsEntryType `json:"entry_type"`
+    Points        int             `json:"points" validate:"required,gt=0"`
+    Reason        string          `json:"reason" validate:"required,max=255"`
+    TransactionID *int64          `json:"transaction_id,omitempty"`
+    CreatedAt     time.Time       `json:"created_at"`
+}
+
+type CustomerRepository interface {
+    Create(ctx context.Context, customer *Customer) error
+    GetByID(ctx context.Context, id int64) (*Customer, error)
+    // GetByIDForUpdate mengunci baris customer sampai database transaction
+    // selesai, dipakai sebelum cek saldo dan debit poin
+    GetByIDForUpdate(ctx context.Context, id int64) (*Customer, error)
+    GetBalance(ctx context.Context, customerID int64) (int, error)
+    CreateLedgerEntry(ctx context.Context, entry *PointsLedgerEntry) error
+    GetLedgerEntries(ctx context.Context, customerID int64) ([]PointsLedgerEntry, error)
+}
+
+type CustomerService interface {
+    Create(ctx context.Context, customer *Customer) error
+    GetByID(ctx context.Context, id int64) (*Customer, error)
+    CreditPoints(ctx context.Context, entry *PointsLedgerEntry) error
+    GetLedger(ctx context.Context, customerID int64) ([]PointsLedgerEntry, error)
+}
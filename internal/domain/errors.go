@@ -0,0 +1,86 @@
+package domain
+
+import "errors"
+
+// Jenis error domain. Handler memetakan jenis ini ke status HTTP, sehingga
+// error baru cukup memilih salah satu jenis tanpa mengubah handler.
+var (
+    ErrNotFound   = errors.New("not found")
+    ErrConflict   = errors.New("conflict")
+    ErrValidation = errors.New("validation failed")
+    ErrExpired    = errors.New("expired")
+    // ErrInsufficientBalance adalah jenis untuk ErrInsufficientPoints
+    ErrInsufficientBalance = errors.New("insufficient balance")
+    // ErrUnauthenticated untuk request tanpa kredensial yang valid
+    ErrUnauthenticated = errors.New("unauthenticated")
+    // ErrForbidden untuk principal yang tidak berhak melakukan aksi
+    ErrForbidden = errors.New("forbidden")
+)
+
+// Error adalah error domain bertipe. Kind adalah salah satu jenis error di
+// atas, Code adalah kode stabil yang dikirim ke client sebagai error_code
+// sekaligus kunci pesan terjemahannya. Message adalah pesan bahasa Inggris
+// untuk log dan test.
+type Error struct {
+    Kind    error
+    Code    string
+    Message string
+    // Args dipakai untuk memformat pesan terjemahan Code
+    Args []interface{}
+    // Fields berisi detail per field untuk error validasi input
+    Fields []FieldError
+}
+
+// FieldError menjelaskan satu field yang gagal validasi. Field memakai nama
+// JSON, misalnya "items[1].voucher_id", dan Rule adalah nama aturan yang
+// dilanggar, misalnya "required".
+type FieldError struct {
+    Field   string `json:"field"`
+    Rule    string `json:"rule"`
+    Message string `json:"message"`
+}
+
+// NewError membuat error domain baru dengan jenis, kode dan pesan
+func NewError(kind error, code, message string) *Error {
+    return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+    return e.Message
+}
+
+// Is membuat errors.Is(err, ErrNotFound) dan sejenisnya bernilai true untuk
+// semua error dengan jenis tersebut
+func (e *Error) Is(target error) bool {
+    return target == e.Kind
+}
+
+// Error umum yang tidak terikat ke satu entitas, biasanya hasil terjemahan
+// constraint database
+var (
+    ErrAlreadyExists       = NewError(ErrConflict, "ALREADY_EXISTS", "resource already exists")
+    ErrInvalidReference    = NewError(ErrValidation, "INVALID_REFERENCE", "referenced resource does not exist")
+    ErrResourceInUse       = NewError(ErrConflict, "RESOURCE_IN_USE", "resource is still referenced by other data")
+    ErrConstraintViolation = NewError(ErrValidation, "CONSTRAINT_VIOLATION", "value violates a data constraint")
+)
+
+// NewFieldValidationError membuat error validasi yang membawa semua field
+// yang gagal sekaligus
+func NewFieldValidationError(fields []FieldError) *Error {
+    return &Error{
+        Kind:    ErrValidation,
+        Code:    "VALIDATION_FAILED",
+        Message: "validation failed",
+        Fields:  fields,
+    }
+}
+
+// ErrorCode mengembalikan kode error domain dari err, atau string kosong jika
+// err bukan error domain
+func ErrorCode(err error) string {
+    var domainErr *Error
+    if errors.As(err, &domainErr) {
+        return domainErr.Code
+    }
+    return ""
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+    "context"
+    "time"
+)
+
+// IdempotencyRecord menyimpan response pertama untuk sebuah Idempotency-Key.
+// ResponseStatus bernilai 0 selama request pertama masih diproses.
+type IdempotencyRecord struct {
+    Scope          string
+    Key            string
+    RequestHash    string
+    ResponseStatus int
+    ResponseBody   []byte
+    CreatedAt      time.Time
+    ExpiresAt      time.Time
+}
+
+type IdempotencyRepository interface {
+    // Reserve menyimpan key baru. Mengembalikan false jika key yang sama
+    // masih aktif; key yang sudah kadaluarsa akan ditimpa.
+    Reserve(ctx context.Context, record *IdempotencyRecord) (bool, error)
+    // Get mengembalikan nil, nil jika key tidak ada atau sudah kadaluarsa
+    Get(ctx context.Context, scope, key string) (*IdempotencyRecord, error)
+    SaveResponse(ctx context.Context, record *IdempotencyRecord) error
+    Delete(ctx context.Context, scope, key string) error
+    DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
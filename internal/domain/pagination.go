@@ -0,0 +1,42 @@
+package domain
+
+import (
+    "time"
+)
+
+var (
+    ErrInvalidCursor = NewError(ErrValidation, "INVALID_CURSOR", "invalid cursor")
+    ErrInvalidSort   = NewError(ErrValidation, "INVALID_SORT", "invalid sort field")
+)
+
+const (
+    DefaultPageLimit = 20
+    MaxPageLimit     = 100
+)
+
+// Page adalah parameter pagination berbasis cursor. Cursor bersifat opaque,
+// nilainya diambil dari next_cursor halaman sebelumnya. Sort berisi nama
+// field, diawali "-" untuk urutan menurun (misalnya "-created_at").
+type Page struct {
+    Limit  int
+    Cursor string
+    Sort   string
+}
+
+// PageLimit mengembalikan limit yang sudah dibatasi ke DefaultPageLimit dan MaxPageLimit
+func (p Page) PageLimit() int {
+    if p.Limit <= 0 {
+        return DefaultPageLimit
+    }
+    if p.Limit > MaxPageLimit {
+        return MaxPageLimit
+    }
+    return p.Limit
+}
+
+// TimeRange membatasi kolom waktu, From inklusif dan To eksklusif.
+// Field yang nil tidak dipakai sebagai filter.
+type TimeRange struct {
+    From *time.Time
+    To   *time.Time
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+    "context"
+    "math"
+    "time"
+)
+
+// RateLimitPolicy adalah token bucket berkapasitas Limit yang terisi penuh
+// kembali dalam Period, misalnya 10 request per menit
+type RateLimitPolicy struct {
+    Limit  int
+    Period time.Duration
+}
+
+// Rate adalah jumlah token yang bertambah per detik
+func (p RateLimitPolicy) Rate() float64 {
+    return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Refill mengembalikan isi bucket pada now dari isi terakhir pada updatedAt.
+// Jam yang mundur, misalnya selisih jam antar instance, tidak mengurangi token.
+func (p RateLimitPolicy) Refill(tokens float64, updatedAt, now time.Time) float64 {
+    elapsed := now.Sub(updatedAt).Seconds()
+    if elapsed < 0 {
+        elapsed = 0
+    }
+    return math.Min(float64(p.Limit), tokens+elapsed*p.Rate())
+}
+
+// Take mengambil satu token dari bucket yang berisi tokens. Mengembalikan
+// isi bucket setelahnya dan hasilnya.
+func (p RateLimitPolicy) Take(tokens float64) (float64, RateLimitResult) {
+    if tokens >= 1 {
+        return tokens - 1, p.Result(true, tokens-1)
+    }
+    return tokens, p.Result(false, tokens)
+}
+
+// Result menghitung sisa kuota dan waktu tunggu dari isi bucket setelah
+// request
+func (p RateLimitPolicy) Result(allowed bool, tokens float64) RateLimitResult {
+    result := RateLimitResult{
+        Allowed:   allowed,
+        Limit:     p.Limit,
+        Remaining: int(math.Max(0, math.Floor(tokens))),
+        Reset:     p.duration(float64(p.Limit) - tokens),
+    }
+    if !allowed {
+        result.RetryAfter = p.duration(1 - tokens)
+    }
+    return result
+}
+
+// duration mengembalikan waktu sampai bucket bertambah sebanyak tokens
+func (p RateLimitPolicy) duration(tokens float64) time.Duration {
+    if tokens <= 0 {
+        return 0
+    }
+    return time.Duration(tokens / p.Rate() * float64(time.Second))
+}
+
+// RateLimitResult adalah hasil satu request terhadap policy. Reset adalah
+// waktu sampai bucket penuh kembali, RetryAfter waktu sampai satu token
+// tersedia dan hanya diisi jika request ditolak.
+type RateLimitResult struct {
+    Allowed    bool
+    Limit      int
+    Remaining  int
+    Reset      time.Duration
+    RetryAfter time.Duration
+}
+
+type RateLimitRepository interface {
+    // Take mengambil satu token dari bucket key secara atomik. Bucket yang
+    // belum ada dimulai dalam keadaan penuh.
+    Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+    // DeleteIdle menghapus bucket yang tidak dipakai sejak before. Bucket
+    // yang tidak dipakai selama Period sudah penuh lagi sehingga aman dihapus.
+    DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
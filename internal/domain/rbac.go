@@ -0,0 +1,57 @@
+package domain
+
+import (
+    "context"
+    "fmt"
+)
+
+// Role menentukan permission sebuah principal. Permission setiap role
+// disimpan di tabel role_permissions.
+type Role string
+
+const (
+    RolePlatformAdmin Role = "platform_admin"
+    RoleBrandManager  Role = "brand_manager"
+    RoleCustomer      Role = "customer"
+)
+
+// Permission berformat "<resource>:<aksi>" dan dicek per route
+type Permission string
+
+const (
+    PermissionBrandRead         Permission = "brand:read"
+    PermissionBrandWrite        Permission = "brand:write"
+    PermissionVoucherRead       Permission = "voucher:read"
+    PermissionVoucherWrite      Permission = "voucher:write"
+    PermissionCustomerRead      Permission = "customer:read"
+    PermissionCustomerWrite     Permission = "customer:write"
+    PermissionTransactionRead   Permission = "transaction:read"
+    PermissionTransactionRedeem Permission = "transaction:redeem"
+    PermissionTransactionRefund Permission = "transaction:refund"
+    PermissionAuditRead         Permission = "audit:read"
+)
+
+type RoleRepository interface {
+    // Permissions mengembalikan permission milik role, kosong jika role
+    // tidak dikenal
+    Permissions(ctx context.Context, role Role) ([]Permission, error)
+}
+
+// Error untuk principal yang sudah terautentikasi tetapi tidak berhak.
+// Permission dicek di router, akses ke brand dan customer lain dicek di
+// service.
+var (
+    ErrBrandAccessDenied    = NewError(ErrForbidden, "BRAND_ACCESS_DENIED", "access is limited to your own brand")
+    ErrCustomerAccessDenied = NewError(ErrForbidden, "CUSTOMER_ACCESS_DENIED", "access is limited to your own data")
+)
+
+// NewPermissionDeniedError membuat error untuk role yang tidak memiliki
+// permission
+func NewPermissionDeniedError(permission Permission) *Error {
+    return &Error{
+        Kind:    ErrForbidden,
+        Code:    "PERMISSION_DENIED",
+        Message: fmt.Sprintf("permission %s is required", permission),
+        Args:    []interface{}{permission},
+    }
+}
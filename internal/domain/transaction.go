@@ -1,24 +1,40 @@
 package domain
 
-import "time"
+import (
+    "context"
+    "time"
+)
+
+var (
+    ErrTransactionNotFound       = NewError(ErrNotFound, "TRANSACTION_NOT_FOUND", "transaction not found")
+    ErrTransactionItemNotFound   = NewError(ErrNotFound, "TRANSACTION_ITEM_NOT_FOUND", "transaction item not found")
+    ErrTransactionNotCancellable = NewError(ErrConflict, "TRANSACTION_NOT_CANCELLABLE", "transaction cannot be cancelled")
+    ErrTransactionItemRefunded   = NewError(ErrConflict, "TRANSACTION_ITEM_REFUNDED", "transaction item already refunded")
+    ErrCancellationWindowExpired = NewError(ErrExpired, "CANCELLATION_WINDOW_EXPIRED", "cancellation window has expired")
+    ErrTransactionItemsRequired  = NewError(ErrValidation, "TRANSACTION_ITEMS_REQUIRED", "transaction must have at least one item")
+)
 
 type Transaction struct {
-    ID          int64             `json:"id"`
-    CustomerID  int64             `json:"customer_id" validate:"required"`
-    TotalPoints int               `json:"total_points"`
-    Status      TransactionStatus `json:"status"`
-    Items       []TransactionItem `json:"items" validate:"required,min=1"`
-    CreatedAt   time.Time         `json:"created_at"`
-    UpdatedAt   time.Time         `json:"updated_at"`
+    ID            int64                     `json:"id"`
+    CustomerID    int64                     `json:"customer_id" validate:"required"`
+    TotalPoints   int                       `json:"total_points"`
+    Status        TransactionStatus         `json:"status"`
+    FailureReason string                    `json:"failure_reason,omitempty"`
+    Items         []TransactionItem         `json:"items" validate:"required,min=1,unique_vouchers,dive"`
+    Refunds       []Refund                  `json:"refunds,omitempty"`
+    History       []TransactionStatusChange `json:"history,omitempty"`
+    CreatedAt     time.Time                 `json:"created_at"`
+    UpdatedAt     time.Time                 `json:"updated_at"`
 }
 
 type TransactionItem struct {
-    ID          int64     `json:"id"`
-    TransactionID int64   `json:"transaction_id"`
-    VoucherID   int64     `json:"voucher_id" validate:"required"`
-    PointsUsed  int       `json:"points_used"`
-    CreatedAt   time.Time `json:"created_at"`
-    Voucher     *Voucher  `json:"voucher,omitempty"`
+    ID            int64                 `json:"id"`
+    TransactionID int64                 `json:"transaction_id"`
+    VoucherID     int64                 `json:"voucher_id" validate:"required"`
+    PointsUsed    int                   `json:"points_used"`
+    Status        TransactionItemStatus `json:"status,omitempty"`
+    CreatedAt     time.Time             `json:"created_at"`
+    Voucher       *Voucher              `json:"voucher,omitempty"`
 }
 
 type TransactionStatus string
@@ -27,19 +43,79 @@ const (
     TransactionStatusPending   TransactionStatus = "pending"
     TransactionStatusCompleted TransactionStatus = "completed"
     TransactionStatusFailed    TransactionStatus = "failed"
+    TransactionStatusCancelled TransactionStatus = "cancelled"
+    TransactionStatusRefunded  TransactionStatus = "refunded"
+)
+
+type TransactionItemStatus string
+
+const (
+    TransactionItemStatusRedeemed TransactionItemStatus = "redeemed"
+    TransactionItemStatusRefunded TransactionItemStatus = "refunded"
 )
 
+// Refund mencatat pengembalian satu item: poin yang dikembalikan,
+// siapa yang melakukan, dan alasannya
+type Refund struct {
+    ID                int64     `json:"id"`
+    TransactionID     int64     `json:"transaction_id"`
+    TransactionItemID int64     `json:"transaction_item_id"`
+    Points            int       `json:"points"`
+    Actor             string    `json:"actor"`
+    Reason            string    `json:"reason"`
+    CreatedAt         time.Time `json:"created_at"`
+}
+
+// RefundRequest adalah input untuk pembatalan transaksi maupun refund item.
+// Actor tidak diterima dari client, service mengambilnya dari principal request.
+type RefundRequest struct {
+    Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// TransactionFilter adalah filter untuk daftar transaksi customer
+type TransactionFilter struct {
+    Status    TransactionStatus
+    MinPoints *int
+    MaxPoints *int
+    Created   TimeRange
+    // BrandID membatasi ke transaksi yang menukar voucher brand ini
+    BrandID *int64
+    Page
+}
+
 type TransactionRepository interface {
-    Create(transaction *Transaction) error
-    GetByID(id int64) (*Transaction, error)
-    GetByCustomerID(customerID int64) ([]Transaction, error)
-    Update(transaction *Transaction) error
-    CreateTransactionItem(item *TransactionItem) error
-    GetTransactionItems(transactionID int64) ([]TransactionItem, error)
+    // Create menyimpan transaksi beserta itemnya dan mencatat status awalnya
+    // di riwayat status atas nama actor
+    Create(ctx context.Context, transaction *Transaction, actor string) error
+    GetByID(ctx context.Context, id int64) (*Transaction, error)
+    // GetByIDForUpdate mengunci baris transaksi sampai database transaction selesai
+    GetByIDForUpdate(ctx context.Context, id int64) (*Transaction, error)
+    // GetByCustomerID mengembalikan satu halaman transaksi customer beserta
+    // cursor halaman berikutnya
+    GetByCustomerID(ctx context.Context, customerID int64, filter TransactionFilter) ([]Transaction, string, error)
+    // UpdateStatus memindahkan status transaksi sesuai state machine dan
+    // mencatatnya di riwayat status. Perpindahan yang tidak diizinkan
+    // mengembalikan *InvalidTransitionError.
+    UpdateStatus(ctx context.Context, transaction *Transaction, to TransactionStatus, actor string, reason string) error
+    GetStatusHistory(ctx context.Context, transactionID int64) ([]TransactionStatusChange, error)
+    CreateTransactionItem(ctx context.Context, item *TransactionItem) error
+    GetTransactionItems(ctx context.Context, transactionID int64) ([]TransactionItem, error)
+    UpdateTransactionItem(ctx context.Context, item *TransactionItem) error
+    CreateRefund(ctx context.Context, refund *Refund) error
+    GetRefunds(ctx context.Context, transactionID int64) ([]Refund, error)
+}
+
+// RedemptionRecorder menerima hasil setiap CreateRedemption, misalnya untuk
+// metrics. brandPoints berisi total poin voucher per brand yang sempat
+// dibaca, cause berisi alasan jika redemption gagal.
+type RedemptionRecorder interface {
+    RecordRedemption(status TransactionStatus, brandPoints map[int64]int, cause error)
 }
 
 type TransactionService interface {
-    CreateRedemption(transaction *Transaction) error
-    GetTransactionByID(id int64) (*Transaction, error)
-    GetCustomerTransactions(customerID int64) ([]Transaction, error)
-} 
\ No newline at end of file
+    CreateRedemption(ctx context.Context, transaction *Transaction) error
+    GetTransactionByID(ctx context.Context, id int64) (*Transaction, error)
+    GetCustomerTransactions(ctx context.Context, customerID int64, filter TransactionFilter) ([]Transaction, string, error)
+    CancelRedemption(ctx context.Context, id int64, request RefundRequest) (*Transaction, error)
+    RefundItem(ctx context.Context, id int64, itemID int64, request RefundRequest) (*Transaction, error)
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+    "fmt"
+    "time"
+)
+
+// ActorSystem dipakai untuk perubahan status yang dilakukan oleh aplikasi
+const ActorSystem = "system"
+
+// ActorAnonymous dipakai sebagai actor jika autentikasi dimatikan
+const ActorAnonymous = "anonymous"
+
+var ErrInvalidTransition = NewError(ErrConflict, "INVALID_STATUS_TRANSITION", "invalid transaction status transition")
+
+// transactionTransitions mendefinisikan perpindahan status yang diizinkan.
+// Status kosong adalah keadaan sebelum transaksi disimpan.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+    "":                         {TransactionStatusPending},
+    TransactionStatusPending:   {TransactionStatusCompleted, TransactionStatusFailed},
+    TransactionStatusCompleted: {TransactionStatusCancelled, TransactionStatusRefunded},
+    TransactionStatusFailed:    {},
+    TransactionStatusCancelled: {},
+    TransactionStatusRefunded:  {},
+}
+
+// InvalidTransitionError dikembalikan jika perpindahan status tidak diizinkan
+type InvalidTransitionError struct {
+    From TransactionStatus
+    To   TransactionStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+    from := string(e.From)
+    if from == "" {
+        from = "new"
+    }
+    return fmt.Sprintf("%s: %s to %s", ErrInvalidTransition, from, e.To)
+}
+
+// Unwrap membuat errors.Is(err, ErrInvalidTransition) bernilai true dan
+// errors.As menemukan kode error domainnya
+func (e *InvalidTransitionError) Unwrap() error {
+    return ErrInvalidTransition
+}
+
+// IsValid melaporkan apakah s adalah status transaksi yang dikenal
+func (s TransactionStatus) IsValid() bool {
+    _, ok := transactionTransitions[s]
+    return ok && s != ""
+}
+
+// CanTransitionTo melaporkan apakah status boleh berpindah ke next
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+    for _, allowed := range transactionTransitions[s] {
+        if allowed == next {
+            return true
+        }
+    }
+    return false
+}
+
+// ValidateTransition mengembalikan *InvalidTransitionError jika perpindahan
+// dari from ke to tidak diizinkan
+func ValidateTransition(from, to TransactionStatus) error {
+    if !from.CanTransitionTo(to) {
+        return &InvalidTransitionError{From: from, To: to}
+    }
+    return nil
+}
+
+// TransactionStatusChange adalah satu baris riwayat perubahan status
+type TransactionStatusChange struct {
+    ID            int64             `json:"id"`
+    TransactionID int64             `json:"transaction_id"`
+    FromStatus    TransactionStatus `json:"from_status,omitempty"`
+    ToStatus      TransactionStatus `json:"to_status"`
+    Actor         string            `json:"actor"`
+    Reason        string            `json:"reason,omitempty"`
+    CreatedAt     time.Time         `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// UnitOfWork memberi akses ke repository yang terikat pada satu database
+// transaction. Semua perubahan lewat repository ini di-commit bersama.
+type UnitOfWork interface {
+    Brands() BrandRepository
+    Vouchers() VoucherRepository
+    Customers() CustomerRepository
+    Transactions() TransactionRepository
+    Audit() AuditRepository
+}
+
+type TxManager interface {
+    // WithinTransaction menjalankan fn dalam satu database transaction.
+    // Commit jika fn mengembalikan nil, rollback jika fn mengembalikan error.
+    WithinTransaction(ctx context.Context, fn func(uow UnitOfWork) error) error
+}
@@ -1,34 +1,77 @@
 package domain
 
-import "time"
+import (
+    "context"
+    "time"
+)
+
+var (
+    ErrVoucherNotFound   = NewError(ErrNotFound, "VOUCHER_NOT_FOUND", "voucher not found")
+    ErrVoucherExpired    = NewError(ErrExpired, "VOUCHER_EXPIRED", "voucher has expired")
+    ErrVoucherSoldOut    = NewError(ErrConflict, "VOUCHER_SOLD_OUT", "voucher sold out")
+    ErrVoucherRedeemed   = NewError(ErrConflict, "VOUCHER_REDEEMED", "voucher has already been redeemed")
+    ErrVoucherCodeExists = NewError(ErrConflict, "VOUCHER_CODE_EXISTS", "voucher code already exists")
+    ErrValidUntilPast    = NewError(ErrValidation, "VALID_UNTIL_IN_PAST", "valid_until must be in the future")
+)
 
 type Voucher struct {
-    ID          int64     `json:"id"`
-    BrandID     int64     `json:"brand_id" validate:"required"`
-    Code        string    `json:"code" validate:"required"`
-    Name        string    `json:"name" validate:"required"`
-    Description string    `json:"description"`
-    Points      int       `json:"points" validate:"required,gt=0"`
-    ValidUntil  time.Time `json:"valid_until"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
-    Brand       *Brand    `json:"brand,omitempty"`
+    ID             int64     `json:"id"`
+    BrandID        int64     `json:"brand_id" validate:"required"`
+    Code           string    `json:"code" validate:"required,voucher_code"`
+    Name           string    `json:"name" validate:"required,max=255"`
+    Description    string    `json:"description"`
+    Points         int       `json:"points" validate:"required,gt=0"`
+    ValidUntil     time.Time `json:"valid_until" validate:"required,future"`
+    // TotalStock dan RemainingStock bernilai nil untuk voucher tanpa kuota
+    TotalStock     *int      `json:"total_stock,omitempty" validate:"omitempty,gte=0"`
+    RemainingStock *int      `json:"remaining_stock,omitempty"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+    Brand          *Brand    `json:"brand,omitempty"`
+}
+
+// VoucherFilter adalah filter untuk daftar voucher. Valid true hanya
+// mengembalikan voucher yang belum kadaluarsa, false hanya yang sudah.
+type VoucherFilter struct {
+    BrandID    *int64
+    Valid      *bool
+    MinPoints  *int
+    MaxPoints  *int
+    ValidUntil TimeRange
+    Created    TimeRange
+    Page
+}
+
+// Narrowed melaporkan apakah filter mempersempit hasil atau melanjutkan
+// dari cursor, sehingga halaman kosong tidak berarti tidak ada voucher sama
+// sekali. BrandID tidak dihitung karena diisi oleh endpoint voucher brand.
+func (f VoucherFilter) Narrowed() bool {
+    return f.Valid != nil || f.MinPoints != nil || f.MaxPoints != nil ||
+        f.ValidUntil != (TimeRange{}) || f.Created != (TimeRange{}) || f.Cursor != ""
 }
 
 type VoucherRepository interface {
-    Create(voucher *Voucher) error
-    GetByID(id int64) (*Voucher, error)
-    GetByBrandID(brandID int64) ([]Voucher, error)
-    Update(voucher *Voucher) error
-    Delete(id int64) error
-    List() ([]Voucher, error)
+    Create(ctx context.Context, voucher *Voucher) error
+    GetByID(ctx context.Context, id int64) (*Voucher, error)
+    Update(ctx context.Context, voucher *Voucher) error
+    Delete(ctx context.Context, id int64) error
+    // List mengembalikan satu halaman voucher beserta cursor halaman berikutnya
+    List(ctx context.Context, filter VoucherFilter) ([]Voucher, string, error)
+    // DecrementStock mengurangi remaining_stock satu unit secara atomik dan
+    // mengembalikan ErrVoucherSoldOut jika stok sudah habis
+    DecrementStock(ctx context.Context, id int64) error
+    // IncrementStock mengembalikan satu unit stok setelah refund
+    IncrementStock(ctx context.Context, id int64) error
 }
 
 type VoucherService interface {
-    Create(voucher *Voucher) error
-    GetByID(id int64) (*Voucher, error)
-    GetByBrandID(brandID int64) ([]Voucher, error)
-    Update(voucher *Voucher) error
-    Delete(id int64) error
-    List() ([]Voucher, error)
+    Create(ctx context.Context, voucher *Voucher) error
+    GetByID(ctx context.Context, id int64) (*Voucher, error)
+    // GetForUpdate membaca voucher yang akan diubah setelah memastikan
+    // principal boleh mengubah voucher tersebut
+    GetForUpdate(ctx context.Context, id int64) (*Voucher, error)
+    GetByBrandID(ctx context.Context, brandID int64, filter VoucherFilter) ([]Voucher, string, error)
+    Update(ctx context.Context, voucher *Voucher) error
+    Delete(ctx context.Context, id int64) error
+    List(ctx context.Context, filter VoucherFilter) ([]Voucher, string, error)
 } 
\ No newline at end of file
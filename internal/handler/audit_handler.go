@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type AuditHandler struct {
+	service domain.AuditService
+}
+
+func NewAuditHandler(service domain.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// List mendukung filter entity (brand, voucher atau transaction) dan id
+// serta limit, cursor dan sort (-created_at, created_at, id)
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	query := newQueryParser(r)
+	filter := domain.AuditFilter{
+		EntityType: domain.AuditEntityType(query.values.Get("entity")),
+		EntityID:   query.int64("id"),
+		Page:       query.page(),
+	}
+	if query.err != nil {
+		writeDomainError(w, r, query.err)
+		return
+	}
+
+	entries, next, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:     http.StatusOK,
+		Message:    localize(r, "success"),
+		Data:       entries,
+		NextCursor: next,
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// Authenticator memverifikasi kredensial request, diimplementasikan oleh
+// auth.Authenticator
+type Authenticator interface {
+	Authenticate(r *http.Request) (*domain.Principal, error)
+}
+
+// Authenticate menolak request tanpa kredensial yang valid dengan 401 dan
+// menyimpan principal ke context request untuk handler dan service
+func Authenticate(authenticator Authenticator, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil {
+			if errors.Is(err, domain.ErrUnauthenticated) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api-otto"`)
+			}
+			writeDomainError(w, r, err)
+			return
+		}
+		next(w, r.WithContext(domain.WithPrincipal(r.Context(), principal)), ps)
+	}
+}
+
+// Authorize menolak principal yang role-nya tidak memiliki permission
+// dengan 403. Request tanpa principal, yaitu saat autentikasi dimatikan,
+// diteruskan apa adanya. Batasan brand dan customer dicek di service.
+func Authorize(permission domain.Permission, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if principal, ok := domain.PrincipalFromContext(r.Context()); ok && !principal.Can(permission) {
+			writeDomainError(w, r, domain.NewPermissionDeniedError(permission))
+			return
+		}
+		next(w, r, ps)
+	}
+}
@@ -2,65 +2,45 @@ package handler
 
 import (
 	"api-otto/internal/domain"
+	"api-otto/internal/validation"
 	"encoding/json"
 	"net/http"
 	"strconv"
 
-	"github.com/go-playground/validator/v10"
-
 	"github.com/julienschmidt/httprouter"
 )
 
 type BrandHandler struct {
 	service   domain.BrandService
-	validator *validator.Validate
+	validator *validation.Validator
 }
 
 func NewBrandHandler(service domain.BrandService) *BrandHandler {
 	return &BrandHandler{
 		service:   service,
-		validator: validator.New(),
+		validator: validation.New(),
 	}
 }
 
 func (h *BrandHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	var brand domain.Brand
 	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request body")
+		writeError(w, r, http.StatusBadRequest, "invalid_request_body")
 		return
 	}
 
-	// Validasi brand
-	if err := h.validator.Struct(brand); err != nil {
-		if _, ok := err.(*validator.InvalidValidationError); ok {
-			writeError(w, http.StatusInternalServerError, "Invalid validation error")
-			return
-		}
-
-		var errorMessages []string
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Tag() {
-			case "required":
-				errorMessages = append(errorMessages, "Nama brand tidak boleh kosong")
-			case "min":
-				errorMessages = append(errorMessages, "Nama brand minimal harus 3 karakter")
-            case"max":
-                errorMessages = append(errorMessages, "Nama brand maksimal harus 200 karakter")
-			}
-		}
-
-		writeError(w, http.StatusBadRequest, errorMessages[0])
+	if !validate(w, r, h.validator, brand) {
 		return
 	}
 
-	if err := h.service.Create(&brand); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Create(r.Context(), &brand); err != nil {
+		writeDomainError(w, r, err)
 		return
 	}
 
 	resp := Response{
 		Status:  http.StatusCreated,
-		Message: "Brand created successfully",
+		Message: localize(r, "brand_created"),
 		Data:    brand,
 	}
 	writeJSON(w, http.StatusCreated, resp)
@@ -69,39 +49,135 @@ func (h *BrandHandler) Create(w http.ResponseWriter, r *http.Request, _ httprout
 func (h *BrandHandler) GetByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid ID")
+		writeError(w, r, http.StatusBadRequest, "invalid_id")
 		return
 	}
 
-	brand, err := h.service.GetByID(id)
+	brand, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeDomainError(w, r, err)
 		return
 	}
 	if brand == nil {
-		writeError(w, http.StatusNotFound, "Brand not found")
+		writeDomainError(w, r, domain.ErrBrandNotFound)
 		return
 	}
 
 	resp := Response{
 		Status:  http.StatusOK,
-		Message: "Success",
+		Message: localize(r, "success"),
 		Data:    brand,
 	}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// GetAll mendukung filter name, created_from, created_to serta limit, cursor
+// dan sort (id, name, created_at, updated_at)
 func (h *BrandHandler) GetAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	brands, err := h.service.List()
+	query := newQueryParser(r)
+	filter := domain.BrandFilter{
+		Name:    query.values.Get("name"),
+		Created: query.timeRange("created"),
+		Page:    query.page(),
+	}
+	if query.err != nil {
+		writeDomainError(w, r, query.err)
+		return
+	}
+
+	brands, next, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:     http.StatusOK,
+		Message:    localize(r, "success"),
+		Data:       brands,
+		NextCursor: next,
+	}
+	writeJSON(w, http.StatusOK, resp)
+} 
+
+// Update mengganti seluruh data brand (PUT)
+func (h *BrandHandler) Update(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_id")
+		return
+	}
+
+	var brand domain.Brand
+	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	brand.ID = id
+
+	h.update(w, r, &brand)
+}
+
+// Patch hanya mengubah field yang dikirim (PATCH)
+func (h *BrandHandler) Patch(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_id")
+		return
+	}
+
+	brand, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeDomainError(w, r, err)
+		return
+	}
+	if brand == nil {
+		writeDomainError(w, r, domain.ErrBrandNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(brand); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	brand.ID = id
+
+	h.update(w, r, brand)
+}
+
+func (h *BrandHandler) update(w http.ResponseWriter, r *http.Request, brand *domain.Brand) {
+	if !validate(w, r, h.validator, brand) {
+		return
+	}
+
+	if err := h.service.Update(r.Context(), brand); err != nil {
+		writeDomainError(w, r, err)
 		return
 	}
 
 	resp := Response{
 		Status:  http.StatusOK,
-		Message: "Success",
-		Data:    brands,
+		Message: localize(r, "brand_updated"),
+		Data:    brand,
 	}
 	writeJSON(w, http.StatusOK, resp)
-} 
\ No newline at end of file
+}
+
+func (h *BrandHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_id")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "brand_deleted"),
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/validation"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type CustomerHandler struct {
+	service   domain.CustomerService
+	validator *validation.Validator
+}
+
+func NewCustomerHandler(service domain.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		service:   service,
+		validator: validation.New(),
+	}
+}
+
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var customer domain.Customer
+	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+
+	if !validate(w, r, h.validator, customer) {
+		return
+	}
+
+	if err := h.service.Create(r.Context(), &customer); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:  http.StatusCreated,
+		Message: localize(r, "customer_created"),
+		Data:    customer,
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_customer_id")
+		return
+	}
+
+	customer, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	if customer == nil {
+		writeDomainError(w, r, domain.ErrCustomerNotFound)
+		return
+	}
+
+	resp := Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "success"),
+		Data:    customer,
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *CustomerHandler) CreditPoints(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_customer_id")
+		return
+	}
+
+	var entry domain.PointsLedgerEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+		return
+	}
+	entry.CustomerID = id
+
+	if !validate(w, r, h.validator, entry) {
+		return
+	}
+
+	if err := h.service.CreditPoints(r.Context(), &entry); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:  http.StatusCreated,
+		Message: localize(r, "points_credited"),
+		Data:    entry,
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *CustomerHandler) GetLedger(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_customer_id")
+		return
+	}
+
+	entries, err := h.service.GetLedger(r.Context(), id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+
+	resp := Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "success"),
+		Data:    entries,
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
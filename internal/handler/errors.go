@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/i18n"
+	"api-otto/internal/logging"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const errorCodeInternal = "INTERNAL_ERROR"
+
+// errorStatuses memetakan jenis error domain ke status HTTP
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{domain.ErrNotFound, http.StatusNotFound},
+	{domain.ErrConflict, http.StatusConflict},
+	{domain.ErrValidation, http.StatusBadRequest},
+	{domain.ErrExpired, http.StatusUnprocessableEntity},
+	{domain.ErrInsufficientBalance, http.StatusUnprocessableEntity},
+	{domain.ErrUnauthenticated, http.StatusUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden},
+}
+
+// writeDomainError adalah satu-satunya tempat error dari service diubah
+// menjadi response. Error domain mendapat status sesuai jenisnya, error_code
+// dan pesan dalam bahasa request. Request yang melewati deadline mendapat
+// 504. Error lain dicatat di log dan dikembalikan sebagai 500 tanpa
+// membocorkan pesan aslinya ke client.
+func writeDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		for _, mapping := range errorStatuses {
+			if errors.Is(domainErr, mapping.kind) {
+				writeJSON(w, mapping.status, Response{
+					Status:    mapping.status,
+					Message:   domainErrorMessage(r, err, domainErr),
+					ErrorCode: domainErr.Code,
+					RequestID: logging.RequestID(r.Context()),
+					Errors:    domainErr.Fields,
+				})
+				return
+			}
+		}
+	}
+
+	// Error karena deadline atau client yang memutus koneksi bukan bug
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded):
+		slog.WarnContext(r.Context(), "request timed out", "method", r.Method, "path", r.URL.Path, "error", err)
+		writeErrorCode(w, r, http.StatusGatewayTimeout, "REQUEST_TIMEOUT", localize(r, "REQUEST_TIMEOUT"))
+		return
+	case errors.Is(err, context.Canceled) || errors.Is(r.Context().Err(), context.Canceled):
+		writeErrorCode(w, r, statusClientClosedRequest, "REQUEST_CANCELLED", localize(r, "REQUEST_CANCELLED"))
+		return
+	}
+
+	slog.ErrorContext(r.Context(), "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+	writeErrorCode(w, r, http.StatusInternalServerError, errorCodeInternal, localize(r, "internal_error"))
+}
+
+// domainErrorMessage menerjemahkan pesan error domain berdasarkan kodenya.
+// Detail yang ditambahkan service dengan fmt.Errorf("%w: ...") tetap
+// disertakan, misalnya "voucher sudah kadaluarsa: voucher 3".
+func domainErrorMessage(r *http.Request, err error, domainErr *domain.Error) string {
+	message, ok := i18n.Lookup(i18n.Language(r.Context()), domainErr.Code)
+	if !ok {
+		return err.Error()
+	}
+	if len(domainErr.Args) > 0 {
+		message = fmt.Sprintf(message, domainErr.Args...)
+	}
+	if detail := strings.TrimPrefix(err.Error(), domainErr.Error()); detail != err.Error() {
+		message += detail
+	}
+	return message
+}
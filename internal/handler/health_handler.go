@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"api-otto/internal/buildinfo"
+	"api-otto/internal/health"
+	"api-otto/internal/logging"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// HealthHandler melayani endpoint untuk load balancer dan informasi build
+type HealthHandler struct {
+	probe *health.Probe
+}
+
+func NewHealthHandler(probe *health.Probe) *HealthHandler {
+	return &HealthHandler{probe: probe}
+}
+
+// Healthz selalu berhasil selama proses masih melayani request
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	writeJSON(w, http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "success"),
+		Data:    health.Result{Status: health.StatusOK},
+	})
+}
+
+// Readyz gagal dengan 503 jika dependency bermasalah atau server sedang
+// shutdown
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	result, ok := h.probe.Ready(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusServiceUnavailable, Response{
+			Status:    http.StatusServiceUnavailable,
+			Message:   localize(r, "not_ready"),
+			ErrorCode: "NOT_READY",
+			RequestID: logging.RequestID(r.Context()),
+			Data:      result,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "success"),
+		Data:    result,
+	})
+}
+
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	writeJSON(w, http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: localize(r, "success"),
+		Data:    buildinfo.Get(),
+	})
+}
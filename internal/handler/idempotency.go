@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// Idempotency membungkus handler POST supaya retry dengan Idempotency-Key
+// yang sama mengembalikan response pertama tanpa memproses ulang request.
+type Idempotency struct {
+	repository domain.IdempotencyRepository
+	ttl        time.Duration
+}
+
+func NewIdempotency(repository domain.IdempotencyRepository, ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		repository: repository,
+		ttl:        ttl,
+	}
+}
+
+// Wrap memasang idempotency pada handler. scope membedakan key yang sama
+// di endpoint yang berbeda, misalnya "POST /transaction/redemption". Jika
+// request sudah terautentikasi, principal ikut menjadi bagian scope supaya
+// key dari dua client yang kebetulan sama tidak saling bertabrakan.
+func (i *Idempotency) Wrap(scope string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r, ps)
+			return
+		}
+		scope := scope
+		if principal, ok := domain.PrincipalFromContext(r.Context()); ok {
+			scope += " " + principal.String()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, r, http.StatusBadRequest, "idempotency_key_too_long")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256(body)
+		now := time.Now()
+		record := &domain.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash[:]),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.ttl),
+		}
+
+		reserved, err := i.repository.Reserve(r.Context(), record)
+		if err != nil {
+			writeDomainError(w, r, err)
+			return
+		}
+		if !reserved {
+			i.replay(w, r, record)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r, ps)
+
+		// Hasil tetap disimpan walaupun client sudah memutus koneksi
+		ctx := context.WithoutCancel(r.Context())
+
+		// Error server tidak disimpan supaya client bisa retry dengan key yang sama
+		if rec.status >= http.StatusInternalServerError {
+			if err := i.repository.Delete(ctx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "failed to release idempotency key", "key", key, "error", err)
+			}
+			return
+		}
+
+		record.ResponseStatus = rec.status
+		record.ResponseBody = rec.body.Bytes()
+		if err := i.repository.SaveResponse(ctx, record); err != nil {
+			slog.ErrorContext(ctx, "failed to store idempotency response", "key", key, "error", err)
+		}
+	}
+}
+
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, record *domain.IdempotencyRecord) {
+	existing, err := i.repository.Get(r.Context(), record.Scope, record.Key)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	if existing == nil {
+		// Key kadaluarsa atau dilepas di antara Reserve dan Get
+		writeErrorCode(w, r, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", localize(r, "IDEMPOTENCY_KEY_IN_PROGRESS"))
+		return
+	}
+	if existing.RequestHash != record.RequestHash {
+		writeErrorCode(w, r, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", localize(r, "IDEMPOTENCY_KEY_REUSED"))
+		return
+	}
+	if existing.ResponseStatus == 0 {
+		writeErrorCode(w, r, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", localize(r, "IDEMPOTENCY_KEY_IN_PROGRESS"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(idempotencyReplayedHeader, "true")
+	w.WriteHeader(existing.ResponseStatus)
+	w.Write(existing.ResponseBody)
+}
+
+// responseRecorder meneruskan response ke client sambil menyimpan salinannya
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
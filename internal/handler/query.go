@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// queryParser membaca parameter list dari query string. Error pertama
+// disimpan di err sehingga handler cukup memeriksanya sekali.
+type queryParser struct {
+    values url.Values
+    err    *domain.Error
+}
+
+func newQueryParser(r *http.Request) *queryParser {
+    return &queryParser{values: r.URL.Query()}
+}
+
+func (p *queryParser) fail(name string) {
+    if p.err == nil {
+        p.err = &domain.Error{
+            Kind:    domain.ErrValidation,
+            Code:    "INVALID_QUERY_PARAMETER",
+            Message: fmt.Sprintf("Invalid query parameter %s", name),
+            Args:    []interface{}{name},
+        }
+    }
+}
+
+func (p *queryParser) int(name string) *int {
+    raw := p.values.Get(name)
+    if raw == "" {
+        return nil
+    }
+    value, err := strconv.Atoi(raw)
+    if err != nil {
+        p.fail(name)
+        return nil
+    }
+    return &value
+}
+
+func (p *queryParser) int64(name string) *int64 {
+    raw := p.values.Get(name)
+    if raw == "" {
+        return nil
+    }
+    value, err := strconv.ParseInt(raw, 10, 64)
+    if err != nil {
+        p.fail(name)
+        return nil
+    }
+    return &value
+}
+
+func (p *queryParser) bool(name string) *bool {
+    raw := p.values.Get(name)
+    if raw == "" {
+        return nil
+    }
+    value, err := strconv.ParseBool(raw)
+    if err != nil {
+        p.fail(name)
+        return nil
+    }
+    return &value
+}
+
+// timeRange membaca <prefix>_from dan <prefix>_to. Nilai boleh RFC3339 atau
+// tanggal saja; tanggal saja pada _to mencakup seluruh hari tersebut.
+func (p *queryParser) timeRange(prefix string) domain.TimeRange {
+    return domain.TimeRange{
+        From: p.time(prefix+"_from", false),
+        To:   p.time(prefix+"_to", true),
+    }
+}
+
+func (p *queryParser) time(name string, endOfDay bool) *time.Time {
+    raw := p.values.Get(name)
+    if raw == "" {
+        return nil
+    }
+    if value, err := time.Parse(time.RFC3339, raw); err == nil {
+        return &value
+    }
+    value, err := time.Parse(dateLayout, raw)
+    if err != nil {
+        p.fail(name)
+        return nil
+    }
+    if endOfDay {
+        value = value.AddDate(0, 0, 1)
+    }
+    return &value
+}
+
+func (p *queryParser) page() domain.Page {
+    page := domain.Page{
+        Cursor: p.values.Get("cursor"),
+        Sort:   p.values.Get("sort"),
+    }
+    if limit := p.int("limit"); limit != nil {
+        if *limit <= 0 {
+            p.fail("limit")
+        }
+        page.Limit = *limit
+    }
+    return page
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"api-otto/internal/domain"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	rateLimitPolicyHeader    = "RateLimit-Policy"
+	retryAfterHeader         = "Retry-After"
+)
+
+// RateLimitOptions mengatur policy rate limiter. Routes menimpa Default per
+// route dengan kunci "METHOD /path". PerIP dipakai WrapIP untuk semua route
+// sekaligus. TrustForwardedFor hanya boleh aktif jika aplikasi berada di
+// belakang proxy yang menambahkan X-Forwarded-For, karena header ini bisa
+// dipalsukan client.
+type RateLimitOptions struct {
+	Default           domain.RateLimitPolicy
+	Routes            map[string]domain.RateLimitPolicy
+	PerIP             domain.RateLimitPolicy
+	TrustForwardedFor bool
+}
+
+// RateLimiter membatasi request per client dengan token bucket. Client
+// dikenali dari principal hasil autentikasi (API key atau customer), atau
+// dari IP jika request tidak terautentikasi.
+type RateLimiter struct {
+	repository domain.RateLimitRepository
+	options    RateLimitOptions
+}
+
+func NewRateLimiter(repository domain.RateLimitRepository, options RateLimitOptions) *RateLimiter {
+	return &RateLimiter{
+		repository: repository,
+		options:    options,
+	}
+}
+
+// Policy mengembalikan policy yang berlaku untuk route
+func (l *RateLimiter) Policy(route string) domain.RateLimitPolicy {
+	if policy, ok := l.options.Routes[route]; ok {
+		return policy
+	}
+	return l.options.Default
+}
+
+// Wrap memasang rate limit pada handler. Setiap route punya bucket sendiri
+// supaya endpoint yang ramai tidak menghabiskan kuota endpoint lain. Jika
+// repository gagal, request tetap dilayani supaya gangguan database tidak
+// menjatuhkan semua endpoint.
+func (l *RateLimiter) Wrap(route string, next httprouter.Handle) httprouter.Handle {
+	policy := l.Policy(route)
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if l.allow(w, r, route+" "+l.client(r), policy) {
+			next(w, r, ps)
+		}
+	}
+}
+
+// WrapIP memasang bucket per IP yang dipakai bersama semua route. Bucket ini
+// dipasang sebelum autentikasi, sehingga request dengan API key atau token
+// yang salah tetap terkena limit walaupun selalu ditolak dengan 401.
+func (l *RateLimiter) WrapIP(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if l.allow(w, r, "* "+l.clientIP(r), l.options.PerIP) {
+			next(w, r, ps)
+		}
+	}
+}
+
+// allow mengambil satu token dari bucket dan menulis header rate limit.
+// Jika token habis, allow menulis 429 dan mengembalikan false.
+func (l *RateLimiter) allow(w http.ResponseWriter, r *http.Request, bucket string, policy domain.RateLimitPolicy) bool {
+	result, err := l.repository.Take(r.Context(), bucket, policy, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "rate limiter unavailable, request allowed", "bucket", bucket, "error", err)
+		return true
+	}
+
+	header := w.Header()
+	header.Set(rateLimitLimitHeader, strconv.Itoa(result.Limit))
+	header.Set(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	header.Set(rateLimitResetHeader, seconds(result.Reset))
+	header.Set(rateLimitPolicyHeader, strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(int(math.Ceil(policy.Period.Seconds()))))
+	if !result.Allowed {
+		header.Set(retryAfterHeader, seconds(result.RetryAfter))
+		writeErrorCode(w, r, http.StatusTooManyRequests, "RATE_LIMITED", localize(r, "RATE_LIMITED"))
+		return false
+	}
+	return true
+}
+
+// client mengembalikan identitas pemilik bucket, misalnya "customer:42" atau
+// "ip:10.0.0.5"
+func (l *RateLimiter) client(r *http.Request) string {
+	if principal, ok := domain.PrincipalFromContext(r.Context()); ok {
+		return principal.String()
+	}
+	return l.clientIP(r)
+}
+
+// clientIP mengembalikan IP client, misalnya "ip:10.0.0.5"
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	if l.options.TrustForwardedFor {
+		// Entri terakhir ditambahkan proxy kita sendiri, entri sebelumnya
+		// berasal dari client dan tidak bisa dipercaya
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			entries := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+				return "ip:" + ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds membulatkan ke atas supaya client tidak retry terlalu cepat
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
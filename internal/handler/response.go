@@ -1,14 +1,27 @@
 package handler
 
 import (
+	"api-otto/internal/domain"
+	"api-otto/internal/i18n"
+	"api-otto/internal/logging"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
+// Response adalah envelope semua endpoint. ErrorCode adalah kode stabil
+// untuk dibaca mesin dan hanya diisi pada response error, begitu juga
+// RequestID supaya support bisa mencari log request tersebut. Errors berisi
+// detail per field untuk error validasi. NextCursor diisi pada endpoint
+// list jika masih ada halaman berikutnya.
 type Response struct {
-	Status  int         `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Status     int                 `json:"status"`
+	Message    string              `json:"message"`
+	ErrorCode  string              `json:"error_code,omitempty"`
+	RequestID  string              `json:"request_id,omitempty"`
+	Errors     []domain.FieldError `json:"errors,omitempty"`
+	Data       interface{}         `json:"data,omitempty"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
 
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -17,10 +30,29 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
+// writeError menulis response error dengan error_code umum berdasarkan
+// status, misalnya BAD_REQUEST, dan pesan key dalam bahasa request. Error
+// dari service sebaiknya lewat writeDomainError supaya mendapat kode yang
+// spesifik.
+func writeError(w http.ResponseWriter, r *http.Request, status int, key string) {
+	writeErrorCode(w, r, status, statusErrorCode(status), localize(r, key))
+}
+
+func writeErrorCode(w http.ResponseWriter, r *http.Request, status int, code string, message string) {
 	resp := Response{
-		Status:  status,
-		Message: message,
+		Status:    status,
+		Message:   message,
+		ErrorCode: code,
+		RequestID: logging.RequestID(r.Context()),
 	}
 	writeJSON(w, status, resp)
-} 
\ No newline at end of file
+}
+
+// localize menerjemahkan key ke bahasa yang dipilih dari Accept-Language
+func localize(r *http.Request, key string, args ...interface{}) string {
+	return i18n.T(i18n.Language(r.Context()), key, args...)
+}
+
+func statusErrorCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// statusClientClosedRequest mengikuti konvensi nginx untuk request yang
+// dibatalkan client sebelum response ditulis
+const statusClientClosedRequest = 499
+
+// WithTimeout memberi deadline pada context request. Query database yang
+// memakai context ini dibatalkan saat deadline lewat, sehingga request yang
+// lambat tidak menahan koneksi terlalu lama.
+func WithTimeout(timeout time.Duration, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next(w, r.WithContext(ctx), ps)
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"api-otto/internal/domain"
+	"api-otto/internal/validation"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -10,28 +11,42 @@ import (
 )
 
 type TransactionHandler struct {
-    service domain.TransactionService
+    service   domain.TransactionService
+    validator *validation.Validator
 }
 
 func NewTransactionHandler(service domain.TransactionService) *TransactionHandler {
-    return &TransactionHandler{service: service}
+    return &TransactionHandler{
+        service:   service,
+        validator: validation.New(),
+    }
 }
 
 func (h *TransactionHandler) CreateRedemption(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
     var transaction domain.Transaction
     if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
-        writeError(w, http.StatusBadRequest, "Invalid request body")
+        writeError(w, r, http.StatusBadRequest, "invalid_request_body")
         return
     }
 
-    if err := h.service.CreateRedemption(&transaction); err != nil {
-        writeError(w, http.StatusInternalServerError, err.Error())
+    // Aplikasi customer hanya boleh menukar poin miliknya sendiri, jadi
+    // customer_id di body diabaikan dan diambil dari token
+    if principal, ok := domain.PrincipalFromContext(r.Context()); ok && principal.Type == domain.PrincipalCustomer {
+        transaction.CustomerID = principal.ID
+    }
+
+    if !validate(w, r, h.validator, transaction) {
+        return
+    }
+
+    if err := h.service.CreateRedemption(r.Context(), &transaction); err != nil {
+        writeDomainError(w, r, err)
         return
     }
 
     resp := Response{
         Status:  http.StatusCreated,
-        Message: "Redemption created successfully",
+        Message: localize(r, "redemption_created"),
         Data:    transaction,
     }
     writeJSON(w, http.StatusCreated, resp)
@@ -40,35 +55,144 @@ func (h *TransactionHandler) CreateRedemption(w http.ResponseWriter, r *http.Req
 func (h *TransactionHandler) GetTransactionByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
     idStr := ps.ByName("id")
     if idStr == "" {
-        writeError(w, http.StatusBadRequest, "Transaction ID is required")
+        writeError(w, r, http.StatusBadRequest, "transaction_id_required")
         return
     }
 
     transactionID, err := strconv.ParseInt(idStr, 10, 64)
     if err != nil {
-        writeError(w, http.StatusBadRequest, "Invalid transaction ID")
+        writeError(w, r, http.StatusBadRequest, "invalid_transaction_id")
         return
     }
 
-    transaction, err := h.service.GetTransactionByID(transactionID)
+    transaction, err := h.service.GetTransactionByID(r.Context(), transactionID)
     if err != nil {
-        if err.Error() == "transaction not found" {
-            writeError(w, http.StatusNotFound, "Transaction not found")
-            return
-        }
-        writeError(w, http.StatusInternalServerError, err.Error())
+        writeDomainError(w, r, err)
         return
     }
 
     if transaction == nil {
-        writeError(w, http.StatusNotFound, "Transaction not found")
+        writeDomainError(w, r, domain.ErrTransactionNotFound)
+        return
+    }
+
+    resp := Response{
+        Status:  http.StatusOK,
+        Message: localize(r, "success"),
+        Data:    transaction,
+    }
+    writeJSON(w, http.StatusOK, resp)
+} 
+
+// GetCustomerTransactions mendukung filter status, min_points, max_points,
+// created_from/_to serta limit, cursor dan sort (id, total_points,
+// created_at, updated_at). Default urutan adalah -created_at.
+func (h *TransactionHandler) GetCustomerTransactions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    customerID, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_customer_id")
+        return
+    }
+
+    query := newQueryParser(r)
+    filter := domain.TransactionFilter{
+        Status:    domain.TransactionStatus(query.values.Get("status")),
+        MinPoints: query.int("min_points"),
+        MaxPoints: query.int("max_points"),
+        Created:   query.timeRange("created"),
+        Page:      query.page(),
+    }
+    if filter.Status != "" && !filter.Status.IsValid() {
+        query.fail("status")
+    }
+    if query.err != nil {
+        writeDomainError(w, r, query.err)
+        return
+    }
+
+    transactions, next, err := h.service.GetCustomerTransactions(r.Context(), customerID, filter)
+    if err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    resp := Response{
+        Status:     http.StatusOK,
+        Message:    localize(r, "success"),
+        Data:       transactions,
+        NextCursor: next,
+    }
+    writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *TransactionHandler) CancelRedemption(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    transactionID, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_transaction_id")
+        return
+    }
+
+    request, ok := h.decodeRefundRequest(w, r)
+    if !ok {
+        return
+    }
+
+    transaction, err := h.service.CancelRedemption(r.Context(), transactionID, request)
+    if err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    resp := Response{
+        Status:  http.StatusOK,
+        Message: localize(r, "redemption_cancelled"),
+        Data:    transaction,
+    }
+    writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *TransactionHandler) RefundItem(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    transactionID, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_transaction_id")
+        return
+    }
+
+    itemID, err := strconv.ParseInt(ps.ByName("item_id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_item_id")
+        return
+    }
+
+    request, ok := h.decodeRefundRequest(w, r)
+    if !ok {
+        return
+    }
+
+    transaction, err := h.service.RefundItem(r.Context(), transactionID, itemID, request)
+    if err != nil {
+        writeDomainError(w, r, err)
         return
     }
 
     resp := Response{
         Status:  http.StatusOK,
-        Message: "Success",
+        Message: localize(r, "item_refunded"),
         Data:    transaction,
     }
     writeJSON(w, http.StatusOK, resp)
-} 
\ No newline at end of file
+}
+
+// decodeRefundRequest membaca alasan refund dari body. Field actor dari
+// client diabaikan, service mencatat principal request sebagai actor.
+func (h *TransactionHandler) decodeRefundRequest(w http.ResponseWriter, r *http.Request) (domain.RefundRequest, bool) {
+    var request domain.RefundRequest
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+        return request, false
+    }
+    if !validate(w, r, h.validator, request) {
+        return request, false
+    }
+    return request, true
+}
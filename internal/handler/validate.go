@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"api-otto/internal/i18n"
+	"api-otto/internal/validation"
+	"net/http"
+)
+
+// validate menjalankan validasi struct dan menulis response 400 berisi semua
+// field yang gagal dalam bahasa request. Rule di skip tidak dijalankan.
+// Mengembalikan false jika request tidak valid.
+func validate(w http.ResponseWriter, r *http.Request, v *validation.Validator, s interface{}, skip ...string) bool {
+	if err := v.StructExcept(i18n.Language(r.Context()), s, skip...); err != nil {
+		writeDomainError(w, r, err)
+		return false
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"api-otto/internal/domain"
+	"api-otto/internal/validation"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -10,28 +11,36 @@ import (
 )
 
 type VoucherHandler struct {
-    service domain.VoucherService
+    service   domain.VoucherService
+    validator *validation.Validator
 }
 
 func NewVoucherHandler(service domain.VoucherService) *VoucherHandler {
-    return &VoucherHandler{service: service}
+    return &VoucherHandler{
+        service:   service,
+        validator: validation.New(),
+    }
 }
 
 func (h *VoucherHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
     var voucher domain.Voucher
     if err := json.NewDecoder(r.Body).Decode(&voucher); err != nil {
-        writeError(w, http.StatusBadRequest, "Invalid request body")
+        writeError(w, r, http.StatusBadRequest, "invalid_request_body")
         return
     }
 
-    if err := h.service.Create(&voucher); err != nil {
-        writeError(w, http.StatusInternalServerError, err.Error())
+    if !validate(w, r, h.validator, voucher) {
+        return
+    }
+
+    if err := h.service.Create(r.Context(), &voucher); err != nil {
+        writeDomainError(w, r, err)
         return
     }
 
     resp := Response{
         Status:  http.StatusCreated,
-        Message: "Voucher created successfully",
+        Message: localize(r, "voucher_created"),
         Data:    voucher,
     }
     writeJSON(w, http.StatusCreated, resp)
@@ -40,29 +49,29 @@ func (h *VoucherHandler) Create(w http.ResponseWriter, r *http.Request, _ httpro
 func (h *VoucherHandler) GetByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
     idStr := ps.ByName("id")
     if idStr == "" {
-        writeError(w, http.StatusBadRequest, "ID is required")
+        writeError(w, r, http.StatusBadRequest, "id_required")
         return
     }
 
     id, err := strconv.ParseInt(idStr, 10, 64)
     if err != nil {
-        writeError(w, http.StatusBadRequest, "Invalid ID")
+        writeError(w, r, http.StatusBadRequest, "invalid_id")
         return
     }
 
-    voucher, err := h.service.GetByID(id)
+    voucher, err := h.service.GetByID(r.Context(), id)
     if err != nil {
-        writeError(w, http.StatusInternalServerError, err.Error())
+        writeDomainError(w, r, err)
         return
     }
     if voucher == nil {
-        writeError(w, http.StatusNotFound, "Voucher not found")
+        writeDomainError(w, r, domain.ErrVoucherNotFound)
         return
     }
 
     resp := Response{
         Status:  http.StatusOK,
-        Message: "Success",
+        Message: localize(r, "success"),
         Data:    voucher,
     }
     writeJSON(w, http.StatusOK, resp)
@@ -71,35 +80,177 @@ func (h *VoucherHandler) GetByID(w http.ResponseWriter, r *http.Request, ps http
 func (h *VoucherHandler) GetByBrandID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
     idStr := ps.ByName("id")
     if idStr == "" {
-        writeError(w, http.StatusBadRequest, "Brand ID is required")
+        writeError(w, r, http.StatusBadRequest, "brand_id_required")
         return
     }
 
     brandID, err := strconv.ParseInt(idStr, 10, 64)
     if err != nil {
-        writeError(w, http.StatusBadRequest, "Invalid brand ID")
+        writeError(w, r, http.StatusBadRequest, "invalid_brand_id")
+        return
+    }
+
+    filter, ok := parseVoucherFilter(w, r)
+    if !ok {
         return
     }
 
-    vouchers, err := h.service.GetByBrandID(brandID)
+    vouchers, next, err := h.service.GetByBrandID(r.Context(), brandID, filter)
     if err != nil {
-        if err.Error() == "brand not found" {
-            writeError(w, http.StatusNotFound, "Brand not found")
+        writeDomainError(w, r, err)
+        return
+    }
+
+    // Halaman kosong hasil filter atau cursor tetap 200, 404 hanya jika
+    // brand memang tidak punya voucher
+    if len(vouchers) == 0 {
+        if !filter.Narrowed() {
+            writeError(w, r, http.StatusNotFound, "brand_has_no_voucher")
             return
         }
-        writeError(w, http.StatusInternalServerError, err.Error())
+        vouchers = []domain.Voucher{}
+    }
+
+    resp := Response{
+        Status:     http.StatusOK,
+        Message:    localize(r, "success"),
+        Data:       vouchers,
+        NextCursor: next,
+    }
+    writeJSON(w, http.StatusOK, resp)
+} 
+
+// List mendukung filter brand_id, valid, min_points, max_points,
+// valid_until_from/_to, created_from/_to serta limit, cursor dan sort
+// (id, code, name, points, valid_until, created_at)
+func (h *VoucherHandler) List(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+    filter, ok := parseVoucherFilter(w, r)
+    if !ok {
         return
     }
 
-    if len(vouchers) == 0 {
-        writeError(w, http.StatusNotFound, "Brand tidak memiliki voucher")
+    vouchers, next, err := h.service.List(r.Context(), filter)
+    if err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    resp := Response{
+        Status:     http.StatusOK,
+        Message:    localize(r, "success"),
+        Data:       vouchers,
+        NextCursor: next,
+    }
+    writeJSON(w, http.StatusOK, resp)
+}
+
+func parseVoucherFilter(w http.ResponseWriter, r *http.Request) (domain.VoucherFilter, bool) {
+    query := newQueryParser(r)
+    filter := domain.VoucherFilter{
+        BrandID:    query.int64("brand_id"),
+        Valid:      query.bool("valid"),
+        MinPoints:  query.int("min_points"),
+        MaxPoints:  query.int("max_points"),
+        ValidUntil: query.timeRange("valid_until"),
+        Created:    query.timeRange("created"),
+        Page:       query.page(),
+    }
+    if query.err != nil {
+        writeDomainError(w, r, query.err)
+        return filter, false
+    }
+    return filter, true
+}
+
+// Update mengganti seluruh data voucher (PUT)
+func (h *VoucherHandler) Update(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_id")
+        return
+    }
+
+    existing, err := h.service.GetForUpdate(r.Context(), id)
+    if err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    var voucher domain.Voucher
+    if err := json.NewDecoder(r.Body).Decode(&voucher); err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+        return
+    }
+    voucher.ID = id
+
+    h.update(w, r, &voucher, existing.Code)
+}
+
+// Patch hanya mengubah field yang dikirim (PATCH)
+func (h *VoucherHandler) Patch(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_id")
+        return
+    }
+
+    voucher, err := h.service.GetForUpdate(r.Context(), id)
+    if err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    code := voucher.Code
+    if err := json.NewDecoder(r.Body).Decode(voucher); err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_request_body")
+        return
+    }
+    voucher.ID = id
+    voucher.Brand = nil
+
+    h.update(w, r, voucher, code)
+}
+
+// update memvalidasi lalu menyimpan voucher. Voucher yang sudah kadaluarsa
+// tetap boleh diubah, dan pola kode hanya dicek jika kode diganti supaya
+// voucher dengan kode lama tetap bisa diedit.
+func (h *VoucherHandler) update(w http.ResponseWriter, r *http.Request, voucher *domain.Voucher, code string) {
+    skip := []string{"future"}
+    if voucher.Code == code {
+        skip = append(skip, "voucher_code")
+    }
+    if !validate(w, r, h.validator, voucher, skip...) {
+        return
+    }
+
+    if err := h.service.Update(r.Context(), voucher); err != nil {
+        writeDomainError(w, r, err)
         return
     }
 
     resp := Response{
         Status:  http.StatusOK,
-        Message: "Success",
-        Data:    vouchers,
+        Message: localize(r, "voucher_updated"),
+        Data:    voucher,
     }
     writeJSON(w, http.StatusOK, resp)
-} 
\ No newline at end of file
+}
+
+func (h *VoucherHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+    id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "invalid_id")
+        return
+    }
+
+    if err := h.service.Delete(r.Context(), id); err != nil {
+        writeDomainError(w, r, err)
+        return
+    }
+
+    resp := Response{
+        Status:  http.StatusOK,
+        Message: localize(r, "voucher_deleted"),
+    }
+    writeJSON(w, http.StatusOK, resp)
+}
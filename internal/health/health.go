@@ -0,0 +1,106 @@
+// Package health menentukan apakah aplikasi siap menerima traffic dari load
+// balancer. Aplikasi tidak siap jika salah satu dependency gagal diperiksa
+// atau graceful shutdown sudah dimulai.
+package health
+
+import (
+	"api-otto/internal/migrate"
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Check memeriksa satu dependency, error berarti aplikasi belum siap
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// Result adalah hasil readiness beserta pesan setiap check
+type Result struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type Probe struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewProbe membuat probe dengan batas waktu untuk seluruh check
+func NewProbe(timeout time.Duration, checks ...Check) *Probe {
+	return &Probe{checks: checks, timeout: timeout}
+}
+
+// SetDraining menandai bahwa shutdown sudah dimulai sehingga readiness
+// langsung gagal dan load balancer berhenti mengirim request baru
+func (p *Probe) SetDraining() {
+	p.draining.Store(true)
+}
+
+func (p *Probe) Draining() bool {
+	return p.draining.Load()
+}
+
+// Ready menjalankan semua check dan mengembalikan true jika semuanya lolos
+func (p *Probe) Ready(ctx context.Context) (Result, bool) {
+	if p.Draining() {
+		return Result{Status: StatusDraining}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result := Result{Status: StatusOK, Checks: make(map[string]string, len(p.checks))}
+	for _, check := range p.checks {
+		if err := check.Run(ctx); err != nil {
+			result.Status = StatusFailing
+			result.Checks[check.Name] = err.Error()
+			continue
+		}
+		result.Checks[check.Name] = StatusOK
+	}
+	return result, result.Status == StatusOK
+}
+
+// Database memastikan database bisa dihubungi
+func Database(db *sql.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			return db.PingContext(ctx)
+		},
+	}
+}
+
+// Migrations memastikan skema database tidak dirty dan tidak lebih lama dari
+// migrasi yang disematkan di binary, aturan yang sama dengan pengecekan saat
+// start. Skema yang lebih baru tetap dianggap siap supaya rollback deploy
+// tidak membuat semua instance keluar dari load balancer.
+func Migrations(migrator *migrate.Migrator) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+			if status.Dirty {
+				return fmt.Errorf("version %d is dirty", status.Version)
+			}
+			if status.Behind() {
+				return fmt.Errorf("version %d, latest is %d (%d pending)", status.Version, status.Latest, len(status.Pending))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package i18n
+
+var en = map[string]string{
+	// Pesan umum handler
+	"success":                 "Success",
+	"invalid_request_body":    "Invalid request body",
+	"id_required":             "ID is required",
+	"invalid_id":              "Invalid ID",
+	"brand_id_required":       "Brand ID is required",
+	"invalid_brand_id":        "Invalid brand ID",
+	"invalid_customer_id":     "Invalid customer ID",
+	"transaction_id_required": "Transaction ID is required",
+	"invalid_transaction_id":  "Invalid transaction ID",
+	"invalid_item_id":         "Invalid item ID",
+	"internal_error":          "Internal server error",
+	"REQUEST_TIMEOUT":         "Request took too long to process",
+	"REQUEST_CANCELLED":       "Request was cancelled by the client",
+	"not_ready":               "Service is not ready",
+
+	"brand_created":        "Brand created successfully",
+	"brand_updated":        "Brand updated successfully",
+	"brand_deleted":        "Brand deleted successfully",
+	"brand_has_no_voucher": "Brand has no vouchers",
+	"voucher_created":      "Voucher created successfully",
+	"voucher_updated":      "Voucher updated successfully",
+	"voucher_deleted":      "Voucher deleted successfully",
+	"customer_created":     "Customer created successfully",
+	"points_credited":      "Points credited successfully",
+	"redemption_created":   "Redemption created successfully",
+	"redemption_cancelled": "Redemption cancelled successfully",
+	"item_refunded":        "Item refunded successfully",
+
+	"idempotency_key_too_long":    "Idempotency-Key is too long",
+	"IDEMPOTENCY_KEY_IN_PROGRESS": "Request with this Idempotency-Key is being processed, retry later",
+	"IDEMPOTENCY_KEY_REUSED":      "Idempotency-Key was already used with a different request body",
+	"RATE_LIMITED":                "Too many requests, retry after the time in the Retry-After header",
+
+	// Error domain, kuncinya adalah kode error
+	"ALREADY_EXISTS":          "resource already exists",
+	"INVALID_REFERENCE":       "referenced resource does not exist",
+	"RESOURCE_IN_USE":         "resource is still referenced by other data",
+	"CONSTRAINT_VIOLATION":    "value violates a data constraint",
+	"VALIDATION_FAILED":       "validation failed",
+	"INVALID_QUERY_PARAMETER": "Invalid query parameter %s",
+	"INVALID_CURSOR":          "invalid cursor",
+	"INVALID_SORT":            "invalid sort field",
+
+	"BRAND_NOT_FOUND":    "brand not found",
+	"BRAND_HAS_VOUCHERS": "brand still has vouchers",
+
+	"VOUCHER_NOT_FOUND":   "voucher not found",
+	"VOUCHER_EXPIRED":     "voucher has expired",
+	"VOUCHER_SOLD_OUT":    "voucher sold out",
+	"VOUCHER_REDEEMED":    "voucher has already been redeemed",
+	"VOUCHER_CODE_EXISTS": "voucher code already exists",
+	"VALID_UNTIL_IN_PAST": "valid_until must be in the future",
+
+	"CUSTOMER_NOT_FOUND":    "customer not found",
+	"CUSTOMER_EMAIL_EXISTS": "customer email already exists",
+	"INSUFFICIENT_POINTS":   "insufficient points",
+	"POINTS_NOT_POSITIVE":   "points must be greater than 0",
+
+	"TRANSACTION_NOT_FOUND":       "transaction not found",
+	"TRANSACTION_ITEM_NOT_FOUND":  "transaction item not found",
+	"TRANSACTION_NOT_CANCELLABLE": "transaction cannot be cancelled",
+	"TRANSACTION_ITEM_REFUNDED":   "transaction item already refunded",
+	"CANCELLATION_WINDOW_EXPIRED": "cancellation window has expired",
+	"TRANSACTION_ITEMS_REQUIRED":  "transaction must have at least one item",
+	"INVALID_STATUS_TRANSITION":   "invalid transaction status transition",
+
+	"AUTHENTICATION_REQUIRED": "authentication is required, send an X-API-Key or Bearer token",
+	"INVALID_API_KEY":         "API key is invalid or revoked",
+	"INVALID_TOKEN":           "token is invalid or expired",
+	"PERMISSION_DENIED":       "permission %s is required",
+	"BRAND_ACCESS_DENIED":     "access is limited to your own brand",
+	"CUSTOMER_ACCESS_DENIED":  "access is limited to your own data",
+
+	"INVALID_AUDIT_ENTITY":  "entity must be brand, voucher or transaction",
+	"AUDIT_ENTITY_REQUIRED": "entity is required when id is set",
+}
@@ -0,0 +1,137 @@
+// Package i18n menyimpan katalog pesan API dalam bahasa Indonesia dan
+// Inggris. Bahasa dipilih per request dari header Accept-Language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+
+	// DefaultLanguage dipakai jika request tidak membawa bahasa yang didukung
+	// dan middleware tidak dikonfigurasi dengan bahasa lain
+	DefaultLanguage = English
+)
+
+// bundles memetakan bahasa ke katalog pesannya. Kunci pesan error domain
+// adalah kode error-nya, misalnya "BRAND_NOT_FOUND".
+var bundles = map[string]map[string]string{
+	English:    en,
+	Indonesian: id,
+}
+
+// Supported melaporkan apakah lang memiliki katalog pesan
+func Supported(lang string) bool {
+	_, ok := bundles[lang]
+	return ok
+}
+
+// Languages mengembalikan semua bahasa yang didukung, terurut
+func Languages() []string {
+	langs := make([]string, 0, len(bundles))
+	for lang := range bundles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Lookup mencari pesan key pada bahasa lang. Jika tidak ada, katalog bahasa
+// Inggris dipakai sebagai cadangan.
+func Lookup(lang, key string) (string, bool) {
+	if message, ok := bundles[lang][key]; ok {
+		return message, true
+	}
+	message, ok := bundles[English][key]
+	return message, ok
+}
+
+// T menerjemahkan key dan memformatnya dengan args. Key yang tidak dikenal
+// dikembalikan apa adanya supaya tetap terlihat di response.
+func T(lang, key string, args ...interface{}) string {
+	message, ok := Lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Negotiate memilih bahasa dari header Accept-Language berdasarkan nilai q.
+// Subtag wilayah diabaikan, jadi "id-ID" cocok dengan "id". fallback dipakai
+// jika tidak ada bahasa yang didukung.
+func Negotiate(header, fallback string) string {
+	best, bestQ := fallback, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, q := parseLanguageRange(part)
+		if q <= bestQ {
+			continue
+		}
+		if tag == "*" {
+			best, bestQ = fallback, q
+			continue
+		}
+		if Supported(tag) {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+func parseLanguageRange(part string) (string, float64) {
+	fields := strings.Split(strings.TrimSpace(part), ";")
+	tag := strings.ToLower(strings.TrimSpace(fields[0]))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	q := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+		if err != nil {
+			return tag, 0
+		}
+		q = value
+	}
+	return tag, q
+}
+
+type languageKey struct{}
+
+// WithLanguage menyimpan bahasa request di context
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// Language mengembalikan bahasa request, atau DefaultLanguage jika context
+// tidak melewati Middleware
+func Language(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Middleware memilih bahasa dari Accept-Language untuk setiap request.
+// defaultLang dipakai jika header kosong atau tidak ada bahasa yang cocok.
+func Middleware(defaultLang string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Negotiate(r.Header.Get("Accept-Language"), defaultLang)
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(WithLanguage(r.Context(), lang)))
+	})
+}
@@ -0,0 +1,80 @@
+package i18n
+
+var id = map[string]string{
+	// Pesan umum handler
+	"success":                 "Berhasil",
+	"invalid_request_body":    "Body request tidak valid",
+	"id_required":             "ID wajib diisi",
+	"invalid_id":              "ID tidak valid",
+	"brand_id_required":       "ID brand wajib diisi",
+	"invalid_brand_id":        "ID brand tidak valid",
+	"invalid_customer_id":     "ID customer tidak valid",
+	"transaction_id_required": "ID transaksi wajib diisi",
+	"invalid_transaction_id":  "ID transaksi tidak valid",
+	"invalid_item_id":         "ID item tidak valid",
+	"internal_error":          "Terjadi kesalahan pada server",
+	"REQUEST_TIMEOUT":         "Request terlalu lama diproses",
+	"REQUEST_CANCELLED":       "Request dibatalkan oleh client",
+	"not_ready":               "Layanan belum siap",
+
+	"brand_created":        "Brand berhasil dibuat",
+	"brand_updated":        "Brand berhasil diperbarui",
+	"brand_deleted":        "Brand berhasil dihapus",
+	"brand_has_no_voucher": "Brand tidak memiliki voucher",
+	"voucher_created":      "Voucher berhasil dibuat",
+	"voucher_updated":      "Voucher berhasil diperbarui",
+	"voucher_deleted":      "Voucher berhasil dihapus",
+	"customer_created":     "Customer berhasil dibuat",
+	"points_credited":      "Poin berhasil ditambahkan",
+	"redemption_created":   "Penukaran berhasil dibuat",
+	"redemption_cancelled": "Penukaran berhasil dibatalkan",
+	"item_refunded":        "Item berhasil di-refund",
+
+	"idempotency_key_too_long":    "Idempotency-Key terlalu panjang",
+	"IDEMPOTENCY_KEY_IN_PROGRESS": "Request dengan Idempotency-Key ini sedang diproses, coba lagi nanti",
+	"IDEMPOTENCY_KEY_REUSED":      "Idempotency-Key sudah dipakai dengan body request yang berbeda",
+	"RATE_LIMITED":                "Terlalu banyak request, coba lagi setelah waktu di header Retry-After",
+
+	// Error domain, kuncinya adalah kode error
+	"ALREADY_EXISTS":          "data sudah ada",
+	"INVALID_REFERENCE":       "data yang dirujuk tidak ada",
+	"RESOURCE_IN_USE":         "data masih dirujuk oleh data lain",
+	"CONSTRAINT_VIOLATION":    "nilai melanggar batasan data",
+	"VALIDATION_FAILED":       "validasi gagal",
+	"INVALID_QUERY_PARAMETER": "Parameter query %s tidak valid",
+	"INVALID_CURSOR":          "cursor tidak valid",
+	"INVALID_SORT":            "field sort tidak valid",
+
+	"BRAND_NOT_FOUND":    "brand tidak ditemukan",
+	"BRAND_HAS_VOUCHERS": "brand masih memiliki voucher",
+
+	"VOUCHER_NOT_FOUND":   "voucher tidak ditemukan",
+	"VOUCHER_EXPIRED":     "voucher sudah kadaluarsa",
+	"VOUCHER_SOLD_OUT":    "stok voucher habis",
+	"VOUCHER_REDEEMED":    "voucher sudah pernah ditukar",
+	"VOUCHER_CODE_EXISTS": "kode voucher sudah dipakai",
+	"VALID_UNTIL_IN_PAST": "valid_until harus di masa depan",
+
+	"CUSTOMER_NOT_FOUND":    "customer tidak ditemukan",
+	"CUSTOMER_EMAIL_EXISTS": "email customer sudah terdaftar",
+	"INSUFFICIENT_POINTS":   "poin tidak cukup",
+	"POINTS_NOT_POSITIVE":   "poin harus lebih dari 0",
+
+	"TRANSACTION_NOT_FOUND":       "transaksi tidak ditemukan",
+	"TRANSACTION_ITEM_NOT_FOUND":  "item transaksi tidak ditemukan",
+	"TRANSACTION_NOT_CANCELLABLE": "transaksi tidak dapat dibatalkan",
+	"TRANSACTION_ITEM_REFUNDED":   "item transaksi sudah di-refund",
+	"CANCELLATION_WINDOW_EXPIRED": "batas waktu pembatalan sudah lewat",
+	"TRANSACTION_ITEMS_REQUIRED":  "transaksi harus memiliki minimal satu item",
+	"INVALID_STATUS_TRANSITION":   "perpindahan status transaksi tidak valid",
+
+	"AUTHENTICATION_REQUIRED": "autentikasi diperlukan, kirim X-API-Key atau Bearer token",
+	"INVALID_API_KEY":         "API key tidak valid atau sudah dicabut",
+	"INVALID_TOKEN":           "token tidak valid atau sudah kadaluarsa",
+	"PERMISSION_DENIED":       "butuh permission %s",
+	"BRAND_ACCESS_DENIED":     "akses hanya untuk brand milik Anda",
+	"CUSTOMER_ACCESS_DENIED":  "akses hanya untuk data milik Anda",
+
+	"INVALID_AUDIT_ENTITY":  "entity harus brand, voucher atau transaction",
+	"AUDIT_ENTITY_REQUIRED": "entity wajib diisi jika id diisi",
+}
@@ -0,0 +1,152 @@
+// Package metrics mengumpulkan metrics Prometheus untuk HTTP, pool koneksi
+// database dan event bisnis redemption. Setiap Metrics memakai registry
+// sendiri sehingga bisa dibuat berkali-kali di test tanpa bentrok.
+package metrics
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unknownBrand dipakai jika redemption gagal sebelum voucher apapun terbaca
+const unknownBrand = "unknown"
+
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	redemptions     *prometheus.CounterVec
+	pointsRedeemed  *prometheus.CounterVec
+	rejections      *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route pattern and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		redemptions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redemptions_total",
+			Help: "Redemptions by final status and voucher brand. A redemption with vouchers from several brands is counted once per brand.",
+		}, []string{"status", "brand_id"}),
+		pointsRedeemed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redemption_points_total",
+			Help: "Points spent on completed redemptions by voucher brand.",
+		}, []string{"brand_id"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redemption_rejections_total",
+			Help: "Failed redemptions by error code, e.g. VOUCHER_EXPIRED or INSUFFICIENT_POINTS.",
+		}, []string{"code"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.redemptions,
+		m.pointsRedeemed,
+		m.rejections,
+	)
+	return m
+}
+
+// RegisterDB menambahkan statistik pool sql.DB dengan label db_name
+func (m *Metrics) RegisterDB(name string, db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler melayani metrics dalam format teks Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Instrument mencatat jumlah dan durasi request dengan label route berupa
+// pola httprouter, misalnya "/brand/:id", supaya jumlah label tetap kecil
+func (m *Metrics) Instrument(method, route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r, ps)
+
+		status := strconv.Itoa(recorder.status)
+		m.requests.WithLabelValues(method, route, status).Inc()
+		m.requestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordRedemption mengimplementasikan domain.RedemptionRecorder
+func (m *Metrics) RecordRedemption(status domain.TransactionStatus, brandPoints map[int64]int, cause error) {
+	if len(brandPoints) == 0 {
+		m.redemptions.WithLabelValues(string(status), unknownBrand).Inc()
+	}
+	for brandID, points := range brandPoints {
+		brand := strconv.FormatInt(brandID, 10)
+		m.redemptions.WithLabelValues(string(status), brand).Inc()
+		if status == domain.TransactionStatusCompleted {
+			m.pointsRedeemed.WithLabelValues(brand).Add(float64(points))
+		}
+	}
+
+	if cause != nil {
+		m.rejections.WithLabelValues(errorCode(cause)).Inc()
+	}
+}
+
+// errorCode memakai kode yang sama dengan error_code pada response
+func errorCode(err error) string {
+	var domainErr *domain.Error
+	switch {
+	case errors.As(err, &domainErr):
+		return domainErr.Code
+	case errors.Is(err, context.DeadlineExceeded):
+		return "REQUEST_TIMEOUT"
+	case errors.Is(err, context.Canceled):
+		return "REQUEST_CANCELLED"
+	default:
+		return "INTERNAL_ERROR"
+	}
+}
+
+// statusRecorder menyimpan status code yang ditulis handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap dipakai http.ResponseController untuk mencapai writer aslinya
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
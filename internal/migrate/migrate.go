@@ -0,0 +1,397 @@
+// Package migrate menjalankan file migrasi SQL yang disematkan di binary.
+// Versi skema dicatat di tabel schema_migrations dengan format yang sama
+// dengan golang-migrate (satu baris version + dirty), sehingga database
+// yang sebelumnya dimigrasi dengan CLI migrate tetap bisa dilanjutkan.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table adalah nama tabel versi yang juga dipakai golang-migrate
+const Table = "schema_migrations"
+
+// lockID adalah key pg_advisory_lock supaya dua proses tidak menjalankan
+// migrasi bersamaan
+const lockID = 7431852093
+
+// nilVersion dipakai golang-migrate saat migrasi pertama gagal: tabel berisi
+// version -1 dengan dirty true
+const nilVersion = -1
+
+// Direction adalah arah eksekusi sebuah migrasi
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// Migration adalah pasangan file {version}_{name}.up.sql dan .down.sql
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// Step adalah satu migrasi yang sudah dijalankan
+type Step struct {
+	Migration Migration
+	Direction Direction
+}
+
+// Status adalah kondisi skema dibanding migrasi yang disematkan
+type Status struct {
+	// Version adalah versi yang tercatat, 0 jika belum ada migrasi
+	Version uint64
+	Dirty   bool
+	Latest  uint64
+	// Pending berisi migrasi yang belum dijalankan, urut naik
+	Pending []Migration
+}
+
+// Behind bernilai true jika skema belum di versi terbaru atau dirty
+func (s Status) Behind() bool {
+	return s.Dirty || len(s.Pending) > 0
+}
+
+// ErrDirty dikembalikan jika migrasi sebelumnya gagal di tengah jalan.
+// Perbaiki database secara manual lalu jalankan force.
+var ErrDirty = errors.New("database schema is dirty")
+
+type Migrator struct {
+	db         *sql.DB
+	fsys       fs.FS
+	migrations []Migration
+}
+
+// New membaca daftar migrasi dari fsys, biasanya migrations.FS. Setiap
+// versi wajib punya file up dan down.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, fsys: fsys, migrations: migrations}, nil
+}
+
+// Load membaca dan memvalidasi nama file migrasi di root fsys, urut naik
+// berdasarkan versi
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".sql")
+		base, direction, ok := cutLast(base, ".")
+		if !ok || (direction != string(DirectionUp) && direction != string(DirectionDown)) {
+			return nil, fmt.Errorf("migration %s: name must end with .up.sql or .down.sql", name)
+		}
+		prefix, title, ok := strings.Cut(base, "_")
+		if !ok || title == "" {
+			return nil, fmt.Errorf("migration %s: name must be {version}_{title}.%s.sql", name, direction)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 63)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", name, prefix)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, title)
+		}
+		file := &m.up
+		if direction == string(DirectionDown) {
+			file = &m.down
+		}
+		if *file != "" {
+			return nil, fmt.Errorf("migration %d: duplicate %s file", version, direction)
+		}
+		*file = name
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %s: both .up.sql and .down.sql are required", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// Migrations mengembalikan semua migrasi yang disematkan, urut naik
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Latest mengembalikan versi migrasi tertinggi, 0 jika tidak ada migrasi
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status membaca versi skema tanpa membuat tabel schema_migrations
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", Table).Scan(&exists); err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Latest: m.Latest()}
+	if exists {
+		version, dirty, err := readVersion(ctx, m.db)
+		if err != nil {
+			return Status{}, err
+		}
+		status.Version, status.Dirty = version, dirty
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > status.Version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Up menjalankan semua migrasi yang belum dijalankan
+func (m *Migrator) Up(ctx context.Context) ([]Step, error) {
+	return m.migrate(ctx, func(uint64) (uint64, error) {
+		return m.Latest(), nil
+	})
+}
+
+// Down membatalkan steps migrasi terakhir. Turun melewati migrasi pertama
+// mengosongkan schema_migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Step, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be greater than 0, got %d", steps)
+	}
+	return m.migrate(ctx, func(current uint64) (uint64, error) {
+		i, err := m.index(current)
+		if err != nil {
+			return 0, err
+		}
+		if i-steps < 0 {
+			return 0, nil
+		}
+		return m.migrations[i-steps].Version, nil
+	})
+}
+
+// Goto menjalankan migrasi up atau down sampai skema berada di version.
+// version 0 membatalkan semua migrasi.
+func (m *Migrator) Goto(ctx context.Context, version uint64) ([]Step, error) {
+	if version != 0 {
+		if _, err := m.index(version); err != nil {
+			return nil, err
+		}
+	}
+	return m.migrate(ctx, func(uint64) (uint64, error) {
+		return version, nil
+	})
+}
+
+// Force mencatat version sebagai versi skema yang bersih tanpa menjalankan
+// SQL apa pun, dipakai setelah memperbaiki migrasi yang dirty secara manual
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 {
+		if _, err := m.index(version); err != nil {
+			return err
+		}
+	}
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+// migrate mengunci database, membaca versi saat ini lalu menjalankan
+// migrasi satu per satu menuju versi dari target
+func (m *Migrator) migrate(ctx context.Context, target func(current uint64) (uint64, error)) ([]Step, error) {
+	var steps []Step
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d, fix it manually then run migrate force", ErrDirty, current)
+		}
+		to, err := target(current)
+		if err != nil {
+			return err
+		}
+
+		for _, step := range m.plan(current, to) {
+			if err := m.run(ctx, conn, step); err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		return nil
+	})
+	return steps, err
+}
+
+// plan menyusun urutan migrasi dari versi current ke to
+func (m *Migrator) plan(current, to uint64) []Step {
+	var steps []Step
+	if to >= current {
+		for _, migration := range m.migrations {
+			if migration.Version > current && migration.Version <= to {
+				steps = append(steps, Step{Migration: migration, Direction: DirectionUp})
+			}
+		}
+		return steps
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version <= current && migration.Version > to {
+			steps = append(steps, Step{Migration: migration, Direction: DirectionDown})
+		}
+	}
+	return steps
+}
+
+// run menandai versi tujuan dirty, menjalankan file SQL lalu mencatat versi
+// bersih, sama seperti golang-migrate. Postgres menjalankan query berisi
+// banyak statement dalam satu transaction implisit, jadi migrasi yang gagal
+// tidak meninggalkan perubahan sebagian; hanya tanda dirty yang tersisa.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, step Step) error {
+	file, version := step.Migration.up, step.Migration.Version
+	if step.Direction == DirectionDown {
+		file, version = step.Migration.down, m.previous(step.Migration.Version)
+	}
+	body, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return err
+	}
+
+	if err := setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != "" {
+		if _, err := conn.ExecContext(ctx, string(body)); err != nil {
+			return fmt.Errorf("migration %s: %w", file, err)
+		}
+	}
+	return setVersion(ctx, conn, version, false)
+}
+
+// index mengembalikan posisi version di daftar migrasi
+func (m *Migrator) index(version uint64) (int, error) {
+	i := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+	if i == len(m.migrations) || m.migrations[i].Version != version {
+		return 0, fmt.Errorf("version %d not found in embedded migrations", version)
+	}
+	return i, nil
+}
+
+// previous mengembalikan versi sebelum version, 0 jika version yang pertama
+func (m *Migrator) previous(version uint64) uint64 {
+	i, _ := m.index(version)
+	if i == 0 {
+		return 0
+	}
+	return m.migrations[i-1].Version
+}
+
+// locked menjalankan fn di satu koneksi yang memegang advisory lock dan
+// memastikan tabel schema_migrations ada
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	// Lock dilepas dengan context baru supaya tetap jalan saat ctx dibatalkan
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+Table+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// readVersion membaca satu-satunya baris schema_migrations. version -1 dari
+// golang-migrate dibaca sebagai 0.
+func readVersion(ctx context.Context, db queryer) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM "+Table+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if version < 0 {
+		version = 0
+	}
+	return uint64(version), dirty, nil
+}
+
+// setVersion mengganti isi schema_migrations. Versi 0 yang bersih berarti
+// tabel kosong; versi 0 yang dirty dicatat -1 seperti golang-migrate.
+func setVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "TRUNCATE "+Table); err != nil {
+		return err
+	}
+	if version != 0 || dirty {
+		value := int64(version)
+		if version == 0 {
+			value = nilVersion
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+Table+" (version, dirty) VALUES ($1, $2)", value, dirty); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type apiKeyRepository struct {
+    db dbtx
+}
+
+func NewAPIKeyRepository(db *sql.DB) domain.APIKeyRepository {
+    return &apiKeyRepository{db: traced(db)}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+    query := `
+        INSERT INTO api_keys (name, key_hash, role, brand_id, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`
+
+    key.CreatedAt = time.Now()
+    err := r.db.QueryRowContext(ctx,
+        query,
+        key.Name,
+        key.KeyHash,
+        key.Role,
+        key.BrandID,
+        key.CreatedAt,
+    ).Scan(&key.ID)
+    return translateError(err)
+}
+
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+    key := &domain.APIKey{}
+    query := `
+        SELECT id, name, key_hash, role, brand_id, created_at, revoked_at
+        FROM api_keys
+        WHERE key_hash = $1 AND revoked_at IS NULL`
+
+    err := r.db.QueryRowContext(ctx, query, keyHash).Scan(
+        &key.ID,
+        &key.Name,
+        &key.KeyHash,
+        &key.Role,
+        &key.BrandID,
+        &key.CreatedAt,
+        &key.RevokedAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    return key, err
+}
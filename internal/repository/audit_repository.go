@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type auditRepository struct {
+    db dbtx
+}
+
+func NewAuditRepository(db *sql.DB) domain.AuditRepository {
+    return &auditRepository{db: traced(db)}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+    query := `
+        INSERT INTO audit_log (actor, request_id, entity_type, entity_id, action, before_data, after_data, created_at)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8)
+        RETURNING id`
+
+    if entry.CreatedAt.IsZero() {
+        entry.CreatedAt = time.Now()
+    }
+    return r.db.QueryRowContext(ctx,
+        query,
+        entry.Actor,
+        entry.RequestID,
+        entry.EntityType,
+        entry.EntityID,
+        entry.Action,
+        nullJSON(entry.Before),
+        nullJSON(entry.After),
+        entry.CreatedAt,
+    ).Scan(&entry.ID)
+}
+
+// nullJSON menyimpan JSON kosong sebagai NULL
+func nullJSON(raw []byte) interface{} {
+    if len(raw) == 0 {
+        return nil
+    }
+    return string(raw)
+}
+
+var auditSortColumns = map[string]sortColumn{
+    "id":         {column: "id", cast: "bigint"},
+    "created_at": {column: "created_at", cast: "timestamp"},
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, string, error) {
+    page, err := newPagination(filter.Page, auditSortColumns, "id", "-created_at")
+    if err != nil {
+        return nil, "", err
+    }
+
+    q := &listQuery{}
+    if filter.EntityType != "" {
+        q.where("entity_type = %s", filter.EntityType)
+    }
+    if filter.EntityID != nil {
+        q.where("entity_id = %s", *filter.EntityID)
+    }
+    if err := page.after(q, filter.Cursor); err != nil {
+        return nil, "", err
+    }
+
+    query := fmt.Sprintf(`
+        SELECT id, actor, COALESCE(request_id, ''), entity_type, entity_id, action,
+               COALESCE(before_data::text, ''), COALESCE(after_data::text, ''), created_at, %s
+        FROM audit_log
+        %s
+        %s`, page.selectValue(), q.clause(), page.tail())
+
+    rows, err := r.db.QueryContext(ctx, query, q.args...)
+    if err != nil {
+        return nil, "", err
+    }
+    defer rows.Close()
+
+    var entries []domain.AuditEntry
+    var values []string
+    for rows.Next() {
+        var entry domain.AuditEntry
+        var before, after, value string
+        if err := rows.Scan(
+            &entry.ID,
+            &entry.Actor,
+            &entry.RequestID,
+            &entry.EntityType,
+            &entry.EntityID,
+            &entry.Action,
+            &before,
+            &after,
+            &entry.CreatedAt,
+            &value,
+        ); err != nil {
+            return nil, "", err
+        }
+        if before != "" {
+            entry.Before = []byte(before)
+        }
+        if after != "" {
+            entry.After = []byte(after)
+        }
+        entries = append(entries, entry)
+        values = append(values, value)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, "", err
+    }
+
+    if !page.hasMore(len(entries)) {
+        return entries, "", nil
+    }
+    entries = entries[:page.limit]
+    last := len(entries) - 1
+    return entries, page.next(entries[last].ID, values[last]), nil
+}
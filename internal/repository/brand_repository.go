@@ -2,44 +2,54 @@ package repository
 
 import (
 	"api-otto/internal/domain"
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
 type brandRepository struct {
-    db *sql.DB
+    db dbtx
 }
 
 func NewBrandRepository(db *sql.DB) domain.BrandRepository {
-    return &brandRepository{db: db}
+    return &brandRepository{db: traced(db)}
 }
 
-func (r *brandRepository) Create(brand *domain.Brand) error {
+func (r *brandRepository) Create(ctx context.Context, brand *domain.Brand) error {
     query := `
-        INSERT INTO brands (name, description, created_at, updated_at)
-        VALUES ($1, $2, $3, $3)
+        INSERT INTO brands (name, description, cancellation_window_minutes, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $4)
         RETURNING id`
 
+    if brand.CancellationWindowMinutes == nil {
+        window := domain.DefaultCancellationWindowMinutes
+        brand.CancellationWindowMinutes = &window
+    }
+
     now := time.Now()
-    return r.db.QueryRow(
+    err := r.db.QueryRowContext(ctx,
         query,
         brand.Name,
         brand.Description,
+        brand.CancellationWindowMinutes,
         now,
     ).Scan(&brand.ID)
+    return translateError(err)
 }
 
-func (r *brandRepository) GetByID(id int64) (*domain.Brand, error) {
+func (r *brandRepository) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
     brand := &domain.Brand{}
     query := `
-        SELECT id, name, description, created_at, updated_at
+        SELECT id, name, description, cancellation_window_minutes, created_at, updated_at
         FROM brands
         WHERE id = $1`
 
-    err := r.db.QueryRow(query, id).Scan(
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
         &brand.ID,
         &brand.Name,
         &brand.Description,
+        &brand.CancellationWindowMinutes,
         &brand.CreatedAt,
         &brand.UpdatedAt,
     )
@@ -49,67 +59,102 @@ func (r *brandRepository) GetByID(id int64) (*domain.Brand, error) {
     return brand, err
 }
 
-func (r *brandRepository) List() ([]domain.Brand, error) {
-    query := `
-        SELECT id, name, description, created_at, updated_at
+var brandSortColumns = map[string]sortColumn{
+    "id":         {column: "id", cast: "bigint"},
+    "name":       {column: "name", cast: "text"},
+    "created_at": {column: "created_at", cast: "timestamp"},
+    "updated_at": {column: "updated_at", cast: "timestamp"},
+}
+
+func (r *brandRepository) List(ctx context.Context, filter domain.BrandFilter) ([]domain.Brand, string, error) {
+    page, err := newPagination(filter.Page, brandSortColumns, "id", "id")
+    if err != nil {
+        return nil, "", err
+    }
+
+    q := &listQuery{}
+    if filter.Name != "" {
+        q.whereContains("name", filter.Name)
+    }
+    q.whereRange("created_at", filter.Created)
+    if err := page.after(q, filter.Cursor); err != nil {
+        return nil, "", err
+    }
+
+    query := fmt.Sprintf(`
+        SELECT id, name, description, cancellation_window_minutes, created_at, updated_at, %s
         FROM brands
-        ORDER BY id`
+        %s
+        %s`, page.selectValue(), q.clause(), page.tail())
 
-    rows, err := r.db.Query(query)
+    rows, err := r.db.QueryContext(ctx, query, q.args...)
     if err != nil {
-        return nil, err
+        return nil, "", err
     }
     defer rows.Close()
 
     var brands []domain.Brand
+    var values []string
     for rows.Next() {
         var brand domain.Brand
+        var value string
         if err := rows.Scan(
             &brand.ID,
             &brand.Name,
             &brand.Description,
+            &brand.CancellationWindowMinutes,
             &brand.CreatedAt,
             &brand.UpdatedAt,
+            &value,
         ); err != nil {
-            return nil, err
+            return nil, "", err
         }
         brands = append(brands, brand)
+        values = append(values, value)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, "", err
     }
-    return brands, nil
+
+    if !page.hasMore(len(brands)) {
+        return brands, "", nil
+    }
+    brands = brands[:page.limit]
+    last := len(brands) - 1
+    return brands, page.next(brands[last].ID, values[last]), nil
 }
 
-func (r *brandRepository) Update(brand *domain.Brand) error {
+func (r *brandRepository) Update(ctx context.Context, brand *domain.Brand) error {
     query := `
         UPDATE brands
-        SET name = $1, description = $2, updated_at = $3
-        WHERE id = $4`
+        SET name = $1, description = $2,
+            cancellation_window_minutes = COALESCE($3, cancellation_window_minutes),
+            updated_at = $4
+        WHERE id = $5
+        RETURNING cancellation_window_minutes, created_at, updated_at`
 
-    result, err := r.db.Exec(
+    err := r.db.QueryRowContext(ctx,
         query,
         brand.Name,
         brand.Description,
+        brand.CancellationWindowMinutes,
         time.Now(),
         brand.ID,
-    )
-    if err != nil {
-        return err
-    }
-
-    rows, err := result.RowsAffected()
-    if err != nil {
-        return err
-    }
-    if rows == 0 {
-        return sql.ErrNoRows
+    ).Scan(&brand.CancellationWindowMinutes, &brand.CreatedAt, &brand.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return domain.ErrBrandNotFound
     }
-    return nil
+    return translateError(err)
 }
 
-func (r *brandRepository) Delete(id int64) error {
+func (r *brandRepository) Delete(ctx context.Context, id int64) error {
     query := `DELETE FROM brands WHERE id = $1`
-    result, err := r.db.Exec(query, id)
+    result, err := r.db.ExecContext(ctx, query, id)
+    if isForeignKeyViolation(err) {
+        return domain.ErrBrandHasVouchers
+    }
     if err != nil {
-        return err
+        return translateError(err)
     }
 
     rows, err := result.RowsAffected()
@@ -117,7 +162,7 @@ func (r *brandRepository) Delete(id int64) error {
         return err
     }
     if rows == 0 {
-        return sql.ErrNoRows
+        return domain.ErrBrandNotFound
     }
     return nil
-} 
\ No newline at end of file
+}
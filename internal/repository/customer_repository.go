@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type customerRepository struct {
+    db dbtx
+}
+
+func NewCustomerRepository(db *sql.DB) domain.CustomerRepository {
+    return &customerRepository{db: traced(db)}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *domain.Customer) error {
+    query := `
+        INSERT INTO customers (name, email, created_at, updated_at)
+        VALUES ($1, NULLIF($2, ''), $3, $3)
+        RETURNING id, created_at, updated_at`
+
+    now := time.Now()
+    err := r.db.QueryRowContext(ctx,
+        query,
+        customer.Name,
+        customer.Email,
+        now,
+    ).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+    return translateError(err)
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+    customer := &domain.Customer{}
+    query := `
+        SELECT c.id, c.name, COALESCE(c.email, ''), c.created_at, c.updated_at,
+               COALESCE((
+                   SELECT SUM(CASE WHEN pl.entry_type = 'credit' THEN pl.points ELSE -pl.points END)
+                   FROM points_ledger pl
+                   WHERE pl.customer_id = c.id
+               ), 0)
+        FROM customers c
+        WHERE c.id = $1`
+
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
+        &customer.ID,
+        &customer.Name,
+        &customer.Email,
+        &customer.CreatedAt,
+        &customer.UpdatedAt,
+        &customer.PointsBalance,
+    )
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    return customer, err
+}
+
+func (r *customerRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Customer, error) {
+    customer := &domain.Customer{}
+    query := `
+        SELECT id, name, COALESCE(email, ''), created_at, updated_at
+        FROM customers
+        WHERE id = $1
+        FOR UPDATE`
+
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
+        &customer.ID,
+        &customer.Name,
+        &customer.Email,
+        &customer.CreatedAt,
+        &customer.UpdatedAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    customer.PointsBalance, err = r.GetBalance(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    return customer, nil
+}
+
+func (r *customerRepository) GetBalance(ctx context.Context, customerID int64) (int, error) {
+    query := `
+        SELECT COALESCE(SUM(CASE WHEN entry_type = 'credit' THEN points ELSE -points END), 0)
+        FROM points_ledger
+        WHERE customer_id = $1`
+
+    var balance int
+    err := r.db.QueryRowContext(ctx, query, customerID).Scan(&balance)
+    return balance, err
+}
+
+func (r *customerRepository) CreateLedgerEntry(ctx context.Context, entry *domain.PointsLedgerEntry) error {
+    query := `
+        INSERT INTO points_ledger (customer_id, entry_type, points, reason, transaction_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at`
+
+    err := r.db.QueryRowContext(ctx,
+        query,
+        entry.CustomerID,
+        entry.EntryType,
+        entry.Points,
+        entry.Reason,
+        entry.TransactionID,
+        time.Now(),
+    ).Scan(&entry.ID, &entry.CreatedAt)
+    return translateError(err)
+}
+
+func (r *customerRepository) GetLedgerEntries(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+    query := `
+        SELECT id, customer_id, entry_type, points, reason, transaction_id, created_at
+        FROM points_ledger
+        WHERE customer_id = $1
+        ORDER BY id`
+
+    rows, err := r.db.QueryContext(ctx, query, customerID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var entries []domain.PointsLedgerEntry
+    for rows.Next() {
+        var entry domain.PointsLedgerEntry
+        var transactionID sql.NullInt64
+        if err := rows.Scan(
+            &entry.ID,
+            &entry.CustomerID,
+            &entry.EntryType,
+            &entry.Points,
+            &entry.Reason,
+            &transactionID,
+            &entry.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        if transactionID.Valid {
+            entry.TransactionID = &transactionID.Int64
+        }
+        entries = append(entries, entry)
+    }
+    return entries, nil
+}
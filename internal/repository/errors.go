@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Kode error Postgres, lihat https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+    pqUniqueViolation     = "23505"
+    pqForeignKeyViolation = "23503"
+    pqCheckViolation      = "23514"
+)
+
+// constraintErrors memetakan constraint tertentu ke error domain yang lebih
+// spesifik daripada terjemahan umum di translateError
+var constraintErrors = map[string]error{
+    "vouchers_code_key":                           domain.ErrVoucherCodeExists,
+    "customers_email_key":                         domain.ErrCustomerEmailExists,
+    "transaction_refunds_transaction_item_id_key": domain.ErrTransactionItemRefunded,
+}
+
+func isForeignKeyViolation(err error) bool {
+    var pqErr *pq.Error
+    return errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation
+}
+
+// translateError mengubah pelanggaran constraint Postgres menjadi error
+// domain supaya pesan database tidak sampai ke client. Error lain
+// dikembalikan apa adanya.
+func translateError(err error) error {
+    var pqErr *pq.Error
+    if !errors.As(err, &pqErr) {
+        return err
+    }
+    if domainErr, ok := constraintErrors[pqErr.Constraint]; ok {
+        return domainErr
+    }
+
+    switch pqErr.Code {
+    case pqUniqueViolation:
+        return domain.ErrAlreadyExists
+    case pqForeignKeyViolation:
+        return domain.ErrInvalidReference
+    case pqCheckViolation:
+        return domain.ErrConstraintViolation
+    }
+    return err
+}
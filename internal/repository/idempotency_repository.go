@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type idempotencyRepository struct {
+    db dbtx
+}
+
+func NewIdempotencyRepository(db *sql.DB) domain.IdempotencyRepository {
+    return &idempotencyRepository{db: traced(db)}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+    // ON CONFLICT hanya menimpa key yang sudah kadaluarsa. Jika key masih
+    // aktif, tidak ada baris yang dikembalikan.
+    query := `
+        INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (scope, idempotency_key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash,
+            response_status = NULL,
+            response_body = NULL,
+            created_at = EXCLUDED.created_at,
+            expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+        RETURNING created_at`
+
+    if record.CreatedAt.IsZero() {
+        record.CreatedAt = time.Now()
+    }
+    err := r.db.QueryRowContext(ctx,
+        query,
+        record.Scope,
+        record.Key,
+        record.RequestHash,
+        record.CreatedAt,
+        record.ExpiresAt,
+    ).Scan(&record.CreatedAt)
+    if err == sql.ErrNoRows {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+    record := &domain.IdempotencyRecord{}
+    query := `
+        SELECT scope, idempotency_key, request_hash, COALESCE(response_status, 0), response_body,
+               created_at, expires_at
+        FROM idempotency_keys
+        WHERE scope = $1 AND idempotency_key = $2 AND expires_at > $3`
+
+    err := r.db.QueryRowContext(ctx, query, scope, key, time.Now()).Scan(
+        &record.Scope,
+        &record.Key,
+        &record.RequestHash,
+        &record.ResponseStatus,
+        &record.ResponseBody,
+        &record.CreatedAt,
+        &record.ExpiresAt,
+    )
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    return record, err
+}
+
+func (r *idempotencyRepository) SaveResponse(ctx context.Context, record *domain.IdempotencyRecord) error {
+    query := `
+        UPDATE idempotency_keys
+        SET response_status = $1, response_body = $2
+        WHERE scope = $3 AND idempotency_key = $4`
+
+    result, err := r.db.ExecContext(ctx,
+        query,
+        record.ResponseStatus,
+        record.ResponseBody,
+        record.Scope,
+        record.Key,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+    query := `DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`
+    _, err := r.db.ExecContext(ctx, query, scope, key)
+    return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+    query := `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+    result, err := r.db.ExecContext(ctx, query, now)
+    if err != nil {
+        return 0, err
+    }
+    return result.RowsAffected()
+}
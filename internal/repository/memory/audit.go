@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"time"
+)
+
+type auditRepository struct {
+    store *Store
+    tx    *transaction
+}
+
+func NewAuditRepository(store *Store) domain.AuditRepository {
+    return &auditRepository{store: store}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if entry.CreatedAt.IsZero() {
+        entry.CreatedAt = time.Now()
+    }
+    entry.ID = r.store.nextID("audit_log")
+    row := cloneAuditEntry(*entry)
+    row.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+    set(r.tx, r.store.audit, row.ID, row)
+    return nil
+}
+
+var auditSortColumns = map[string]sortColumn[domain.AuditEntry]{
+    "id":         func(e domain.AuditEntry) interface{} { return e.ID },
+    "created_at": func(e domain.AuditEntry) interface{} { return e.CreatedAt },
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, string, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var entries []domain.AuditEntry
+    for _, row := range r.store.audit {
+        if filter.EntityType != "" && row.EntityType != filter.EntityType {
+            continue
+        }
+        if filter.EntityID != nil && row.EntityID != *filter.EntityID {
+            continue
+        }
+        entries = append(entries, cloneAuditEntry(row))
+    }
+    return paginate(entries, filter.Page, auditSortColumns, func(e domain.AuditEntry) int64 { return e.ID }, "-created_at")
+}
+
+func cloneAuditEntry(e domain.AuditEntry) domain.AuditEntry {
+    e.Before = cloneBytes(e.Before)
+    e.After = cloneBytes(e.After)
+    return e
+}
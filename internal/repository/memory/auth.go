@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"sort"
+	"time"
+)
+
+// defaultRolePermissions sama dengan isi role_permissions setelah semua
+// migrasi dijalankan
+func defaultRolePermissions() map[domain.Role][]domain.Permission {
+    return map[domain.Role][]domain.Permission{
+        domain.RolePlatformAdmin: {
+            domain.PermissionBrandRead,
+            domain.PermissionBrandWrite,
+            domain.PermissionVoucherRead,
+            domain.PermissionVoucherWrite,
+            domain.PermissionCustomerRead,
+            domain.PermissionCustomerWrite,
+            domain.PermissionTransactionRead,
+            domain.PermissionTransactionRedeem,
+            domain.PermissionTransactionRefund,
+            domain.PermissionAuditRead,
+        },
+        domain.RoleBrandManager: {
+            domain.PermissionBrandRead,
+            domain.PermissionVoucherRead,
+            domain.PermissionVoucherWrite,
+            domain.PermissionTransactionRead,
+        },
+        domain.RoleCustomer: {
+            domain.PermissionBrandRead,
+            domain.PermissionVoucherRead,
+            domain.PermissionCustomerRead,
+            domain.PermissionTransactionRead,
+            domain.PermissionTransactionRedeem,
+        },
+    }
+}
+
+type apiKeyRepository struct {
+    store *Store
+}
+
+func NewAPIKeyRepository(store *Store) domain.APIKeyRepository {
+    return &apiKeyRepository{store: store}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+    defer r.store.lockWrite(nil)()
+
+    for _, row := range r.store.apiKeys {
+        if row.KeyHash == key.KeyHash {
+            return domain.ErrAlreadyExists
+        }
+    }
+    if _, ok := r.store.rolePermissions[key.Role]; !ok {
+        return domain.ErrInvalidReference
+    }
+    if key.BrandID != nil {
+        if _, ok := r.store.brands[*key.BrandID]; !ok {
+            return domain.ErrInvalidReference
+        }
+    }
+
+    key.ID = r.store.nextID("api_keys")
+    key.CreatedAt = time.Now()
+    set(nil, r.store.apiKeys, key.ID, cloneAPIKey(*key))
+    return nil
+}
+
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, row := range r.store.apiKeys {
+        if row.KeyHash == keyHash && row.RevokedAt == nil {
+            key := cloneAPIKey(row)
+            return &key, nil
+        }
+    }
+    return nil, nil
+}
+
+func cloneAPIKey(k domain.APIKey) domain.APIKey {
+    k.BrandID = cloneInt64(k.BrandID)
+    if k.RevokedAt != nil {
+        revokedAt := *k.RevokedAt
+        k.RevokedAt = &revokedAt
+    }
+    return k
+}
+
+type roleRepository struct {
+    store *Store
+}
+
+func NewRoleRepository(store *Store) domain.RoleRepository {
+    return &roleRepository{store: store}
+}
+
+// Permissions diurutkan berdasarkan nama seperti ORDER BY permission
+func (r *roleRepository) Permissions(ctx context.Context, role domain.Role) ([]domain.Permission, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    permissions := append([]domain.Permission(nil), r.store.rolePermissions[role]...)
+    sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+    return permissions, nil
+}
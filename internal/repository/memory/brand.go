@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"strings"
+)
+
+type brandRepository struct {
+    store *Store
+    // tx bernilai nil di luar database transaction
+    tx *transaction
+}
+
+func NewBrandRepository(store *Store) domain.BrandRepository {
+    return &brandRepository{store: store}
+}
+
+func (r *brandRepository) Create(ctx context.Context, brand *domain.Brand) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if brand.CancellationWindowMinutes == nil {
+        window := domain.DefaultCancellationWindowMinutes
+        brand.CancellationWindowMinutes = &window
+    }
+    if *brand.CancellationWindowMinutes < 0 {
+        return domain.ErrConstraintViolation
+    }
+
+    brand.ID = r.store.nextID("brands")
+    row := *brand
+    row.CancellationWindowMinutes = cloneInt(brand.CancellationWindowMinutes)
+    row.CreatedAt = now()
+    row.UpdatedAt = row.CreatedAt
+    set(r.tx, r.store.brands, row.ID, row)
+    return nil
+}
+
+func (r *brandRepository) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    row, ok := r.store.brands[id]
+    if !ok {
+        return nil, nil
+    }
+    brand := cloneBrand(row)
+    return &brand, nil
+}
+
+var brandSortColumns = map[string]sortColumn[domain.Brand]{
+    "id":         func(b domain.Brand) interface{} { return b.ID },
+    "name":       func(b domain.Brand) interface{} { return b.Name },
+    "created_at": func(b domain.Brand) interface{} { return b.CreatedAt },
+    "updated_at": func(b domain.Brand) interface{} { return b.UpdatedAt },
+}
+
+func (r *brandRepository) List(ctx context.Context, filter domain.BrandFilter) ([]domain.Brand, string, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    name := strings.ToLower(filter.Name)
+    var brands []domain.Brand
+    for _, row := range r.store.brands {
+        if name != "" && !strings.Contains(strings.ToLower(row.Name), name) {
+            continue
+        }
+        if !inRange(row.CreatedAt, filter.Created) {
+            continue
+        }
+        brands = append(brands, cloneBrand(row))
+    }
+    return paginate(brands, filter.Page, brandSortColumns, func(b domain.Brand) int64 { return b.ID }, "id")
+}
+
+func (r *brandRepository) Update(ctx context.Context, brand *domain.Brand) error {
+    defer r.store.lockWrite(r.tx)()
+
+    row, ok := r.store.brands[brand.ID]
+    if !ok {
+        return domain.ErrBrandNotFound
+    }
+    if brand.CancellationWindowMinutes != nil {
+        if *brand.CancellationWindowMinutes < 0 {
+            return domain.ErrConstraintViolation
+        }
+        row.CancellationWindowMinutes = cloneInt(brand.CancellationWindowMinutes)
+    }
+    row.Name = brand.Name
+    row.Description = brand.Description
+    row.UpdatedAt = now()
+    set(r.tx, r.store.brands, row.ID, row)
+
+    brand.CancellationWindowMinutes = cloneInt(row.CancellationWindowMinutes)
+    brand.CreatedAt = row.CreatedAt
+    brand.UpdatedAt = row.UpdatedAt
+    return nil
+}
+
+func (r *brandRepository) Delete(ctx context.Context, id int64) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if _, ok := r.store.brands[id]; !ok {
+        return domain.ErrBrandNotFound
+    }
+    for _, voucher := range r.store.vouchers {
+        if voucher.BrandID == id {
+            return domain.ErrBrandHasVouchers
+        }
+    }
+    // api_keys.brand_id memakai ON DELETE CASCADE
+    for keyID, key := range r.store.apiKeys {
+        if key.BrandID != nil && *key.BrandID == id {
+            remove(r.tx, r.store.apiKeys, keyID)
+        }
+    }
+    remove(r.tx, r.store.brands, id)
+    return nil
+}
+
+func cloneBrand(b domain.Brand) domain.Brand {
+    b.CancellationWindowMinutes = cloneInt(b.CancellationWindowMinutes)
+    return b
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+)
+
+type customerRepository struct {
+    store *Store
+    tx    *transaction
+}
+
+func NewCustomerRepository(store *Store) domain.CustomerRepository {
+    return &customerRepository{store: store}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *domain.Customer) error {
+    defer r.store.lockWrite(r.tx)()
+
+    // Email kosong disimpan sebagai NULL sehingga tidak ikut dicek unik
+    if customer.Email != "" {
+        for _, row := range r.store.customers {
+            if row.Email == customer.Email {
+                return domain.ErrCustomerEmailExists
+            }
+        }
+    }
+
+    customer.ID = r.store.nextID("customers")
+    customer.CreatedAt = now()
+    customer.UpdatedAt = customer.CreatedAt
+    row := *customer
+    row.PointsBalance = 0
+    set(r.tx, r.store.customers, row.ID, row)
+    return nil
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    row, ok := r.store.customers[id]
+    if !ok {
+        return nil, nil
+    }
+    customer := row
+    customer.PointsBalance = r.balance(id)
+    return &customer, nil
+}
+
+// GetByIDForUpdate tidak perlu mengunci baris karena database transaction
+// memory sudah berjalan satu per satu
+func (r *customerRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Customer, error) {
+    return r.GetByID(ctx, id)
+}
+
+func (r *customerRepository) GetBalance(ctx context.Context, customerID int64) (int, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    return r.balance(customerID), nil
+}
+
+// balance adalah jumlah credit dikurangi debit di ledger
+func (r *customerRepository) balance(customerID int64) int {
+    balance := 0
+    for _, entry := range r.store.ledger {
+        if entry.CustomerID != customerID {
+            continue
+        }
+        if entry.EntryType == domain.PointsEntryCredit {
+            balance += entry.Points
+        } else {
+            balance -= entry.Points
+        }
+    }
+    return balance
+}
+
+func (r *customerRepository) CreateLedgerEntry(ctx context.Context, entry *domain.PointsLedgerEntry) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if entry.Points <= 0 || (entry.EntryType != domain.PointsEntryCredit && entry.EntryType != domain.PointsEntryDebit) {
+        return domain.ErrConstraintViolation
+    }
+    if _, ok := r.store.customers[entry.CustomerID]; !ok {
+        return domain.ErrInvalidReference
+    }
+    if entry.TransactionID != nil {
+        if _, ok := r.store.transactions[*entry.TransactionID]; !ok {
+            return domain.ErrInvalidReference
+        }
+    }
+
+    entry.ID = r.store.nextID("points_ledger")
+    entry.CreatedAt = now()
+    row := *entry
+    row.TransactionID = cloneInt64(entry.TransactionID)
+    set(r.tx, r.store.ledger, row.ID, row)
+    return nil
+}
+
+func (r *customerRepository) GetLedgerEntries(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var entries []domain.PointsLedgerEntry
+    for _, entry := range r.store.ledger {
+        if entry.CustomerID == customerID {
+            entry.TransactionID = cloneInt64(entry.TransactionID)
+            entries = append(entries, entry)
+        }
+    }
+    sortByID(entries, func(e domain.PointsLedgerEntry) int64 { return e.ID })
+    return entries, nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type idempotencyKey struct {
+    scope string
+    key   string
+}
+
+type idempotencyRepository struct {
+    store *Store
+}
+
+func NewIdempotencyRepository(store *Store) domain.IdempotencyRepository {
+    return &idempotencyRepository{store: store}
+}
+
+// Reserve hanya menimpa key yang sudah kadaluarsa
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+    defer r.store.lockWrite(nil)()
+
+    if record.CreatedAt.IsZero() {
+        record.CreatedAt = time.Now()
+    }
+    key := idempotencyKey{scope: record.Scope, key: record.Key}
+    if existing, ok := r.store.idempotency[key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+        return false, nil
+    }
+
+    row := *record
+    row.ResponseStatus = 0
+    row.ResponseBody = nil
+    set(nil, r.store.idempotency, key, row)
+    return true, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    row, ok := r.store.idempotency[idempotencyKey{scope: scope, key: key}]
+    if !ok || !row.ExpiresAt.After(time.Now()) {
+        return nil, nil
+    }
+    record := row
+    record.ResponseBody = cloneBytes(row.ResponseBody)
+    return &record, nil
+}
+
+func (r *idempotencyRepository) SaveResponse(ctx context.Context, record *domain.IdempotencyRecord) error {
+    defer r.store.lockWrite(nil)()
+
+    key := idempotencyKey{scope: record.Scope, key: record.Key}
+    row, ok := r.store.idempotency[key]
+    if !ok {
+        return sql.ErrNoRows
+    }
+    row.ResponseStatus = record.ResponseStatus
+    row.ResponseBody = cloneBytes(record.ResponseBody)
+    set(nil, r.store.idempotency, key, row)
+    return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+    defer r.store.lockWrite(nil)()
+
+    remove(nil, r.store.idempotency, idempotencyKey{scope: scope, key: key})
+    return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+    defer r.store.lockWrite(nil)()
+
+    var deleted int64
+    for key, row := range r.store.idempotency {
+        if !row.ExpiresAt.After(now) {
+            remove(nil, r.store.idempotency, key)
+            deleted++
+        }
+    }
+    return deleted, nil
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortColumn mengembalikan nilai kolom sort sebuah baris. Tipe nilainya
+// int64, int, string atau time.Time dan dipakai juga untuk membaca cursor.
+type sortColumn[T any] func(row T) interface{}
+
+// cursor sama bentuknya dengan cursor repository Postgres
+type cursor struct {
+    Sort  string `json:"s"`
+    Value string `json:"v"`
+    ID    int64  `json:"id"`
+}
+
+// paginate mengurutkan rows, melanjutkan dari cursor dan memotongnya ke
+// limit, dengan aturan yang sama seperti pagination keyset di Postgres
+func paginate[T any](rows []T, page domain.Page, columns map[string]sortColumn[T], id func(T) int64, defaultSort string) ([]T, string, error) {
+    sortBy := page.Sort
+    if sortBy == "" {
+        sortBy = defaultSort
+    }
+    name := strings.TrimPrefix(sortBy, "-")
+    column, ok := columns[name]
+    if !ok {
+        return nil, "", fmt.Errorf("%w: %s", domain.ErrInvalidSort, name)
+    }
+    desc := strings.HasPrefix(sortBy, "-")
+
+    // compareRow membandingkan (nilai, id) dengan row seperti ORDER BY kolom, id
+    compareRow := func(value interface{}, rowID int64, row T) int {
+        if c := compare(value, column(row)); c != 0 {
+            return c
+        }
+        return compareInt64(rowID, id(row))
+    }
+    sort.Slice(rows, func(i, j int) bool {
+        c := compareRow(column(rows[i]), id(rows[i]), rows[j])
+        if desc {
+            return c > 0
+        }
+        return c < 0
+    })
+
+    if page.Cursor != "" {
+        var zero T
+        after, err := decodeCursor(page.Cursor, sortBy, column(zero))
+        if err != nil {
+            return nil, "", err
+        }
+        start := len(rows)
+        for i, row := range rows {
+            c := compareRow(after.value, after.id, row)
+            if (!desc && c < 0) || (desc && c > 0) {
+                start = i
+                break
+            }
+        }
+        rows = rows[start:]
+    }
+
+    limit := page.PageLimit()
+    if len(rows) <= limit {
+        if len(rows) == 0 {
+            return nil, "", nil
+        }
+        return rows, "", nil
+    }
+    rows = rows[:limit]
+    last := rows[limit-1]
+    raw, _ := json.Marshal(cursor{Sort: sortBy, Value: formatValue(column(last)), ID: id(last)})
+    return rows, base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+type position struct {
+    value interface{}
+    id    int64
+}
+
+// decodeCursor membaca cursor dan mengubah nilainya ke tipe yang sama
+// dengan zero, nilai kolom dari baris kosong
+func decodeCursor(encoded, sortBy string, zero interface{}) (position, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(encoded)
+    if err != nil {
+        return position{}, domain.ErrInvalidCursor
+    }
+    var c cursor
+    if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sortBy {
+        return position{}, domain.ErrInvalidCursor
+    }
+
+    var value interface{}
+    switch zero.(type) {
+    case int64:
+        value, err = strconv.ParseInt(c.Value, 10, 64)
+    case int:
+        // int adalah kolom integer Postgres, jadi dibatasi 32 bit
+        var parsed int64
+        parsed, err = strconv.ParseInt(c.Value, 10, 32)
+        value = int(parsed)
+    case time.Time:
+        value, err = time.Parse(time.RFC3339Nano, c.Value)
+    default:
+        value = c.Value
+    }
+    if err != nil {
+        return position{}, domain.ErrInvalidCursor
+    }
+    return position{value: value, id: c.ID}, nil
+}
+
+func formatValue(value interface{}) string {
+    switch v := value.(type) {
+    case time.Time:
+        return v.Format(time.RFC3339Nano)
+    default:
+        return fmt.Sprint(v)
+    }
+}
+
+// compare membandingkan dua nilai kolom bertipe sama
+func compare(a, b interface{}) int {
+    switch a := a.(type) {
+    case int64:
+        return compareInt64(a, b.(int64))
+    case int:
+        return compareInt64(int64(a), int64(b.(int)))
+    case time.Time:
+        return a.Compare(b.(time.Time))
+    case string:
+        return strings.Compare(a, b.(string))
+    }
+    panic(fmt.Sprintf("memory: unsupported sort value %T", a))
+}
+
+func compareInt64(a, b int64) int {
+    switch {
+    case a < b:
+        return -1
+    case a > b:
+        return 1
+    }
+    return 0
+}
@@ -0,0 +1,59 @@
+// Package memory berisi implementasi repository yang menyimpan data di
+// memory proses. Data hilang saat aplikasi berhenti dan tidak dibagi antar
+// instance.
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+    tokens    float64
+    updatedAt time.Time
+}
+
+type rateLimitRepository struct {
+    mu      sync.Mutex
+    buckets map[string]*bucket
+}
+
+// NewRateLimitRepository membuat token bucket di memory. Batasnya berlaku
+// per instance, pakai repository Postgres jika aplikasi berjalan di lebih
+// dari satu instance.
+func NewRateLimitRepository() domain.RateLimitRepository {
+    return &rateLimitRepository{buckets: map[string]*bucket{}}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitResult, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    b, ok := r.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(policy.Limit), updatedAt: now}
+        r.buckets[key] = b
+    }
+    tokens, result := policy.Take(policy.Refill(b.tokens, b.updatedAt, now))
+    b.tokens = tokens
+    if now.After(b.updatedAt) {
+        b.updatedAt = now
+    }
+    return result, nil
+}
+
+func (r *rateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var deleted int64
+    for key, b := range r.buckets {
+        if b.updatedAt.Before(before) {
+            delete(r.buckets, key)
+            deleted++
+        }
+    }
+    return deleted, nil
+}
@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store adalah database di memory yang dipakai bersama oleh semua
+// repository memory. Setiap tabel adalah map berisi salinan nilai supaya
+// caller tidak bisa mengubah data tanpa lewat repository.
+type Store struct {
+    // mu melindungi semua tabel, dipegang selama satu operasi repository
+    mu sync.Mutex
+    // txMu membuat database transaction berjalan satu per satu, pengganti
+    // row lock seperti SELECT ... FOR UPDATE. Penulisan di luar transaction
+    // juga memegang txMu, lihat lockWrite.
+    txMu sync.Mutex
+
+    // sequences tidak ikut di-rollback, sama seperti sequence Postgres
+    sequences map[string]int64
+
+    brands          map[int64]domain.Brand
+    vouchers        map[int64]domain.Voucher
+    transactions    map[int64]domain.Transaction
+    items           map[int64]domain.TransactionItem
+    history         map[int64]domain.TransactionStatusChange
+    refunds         map[int64]domain.Refund
+    customers       map[int64]domain.Customer
+    ledger          map[int64]domain.PointsLedgerEntry
+    audit           map[int64]domain.AuditEntry
+    idempotency     map[idempotencyKey]domain.IdempotencyRecord
+    apiKeys         map[int64]domain.APIKey
+    rolePermissions map[domain.Role][]domain.Permission
+}
+
+// NewStore membuat database kosong. Role dan permission diisi sama dengan
+// migrasi RBAC.
+func NewStore() *Store {
+    return &Store{
+        sequences:       map[string]int64{},
+        brands:          map[int64]domain.Brand{},
+        vouchers:        map[int64]domain.Voucher{},
+        transactions:    map[int64]domain.Transaction{},
+        items:           map[int64]domain.TransactionItem{},
+        history:         map[int64]domain.TransactionStatusChange{},
+        refunds:         map[int64]domain.Refund{},
+        customers:       map[int64]domain.Customer{},
+        ledger:          map[int64]domain.PointsLedgerEntry{},
+        audit:           map[int64]domain.AuditEntry{},
+        idempotency:     map[idempotencyKey]domain.IdempotencyRecord{},
+        apiKeys:         map[int64]domain.APIKey{},
+        rolePermissions: defaultRolePermissions(),
+    }
+}
+
+// nextID mengembalikan ID berikutnya dari sequence tabel, mulai dari 1
+func (s *Store) nextID(table string) int64 {
+    s.sequences[table]++
+    return s.sequences[table]
+}
+
+// lockWrite memegang mu untuk satu operasi tulis dan mengembalikan fungsi
+// untuk melepasnya. Penulisan di luar transaction (tx nil) juga memegang txMu
+// supaya tidak terjadi di tengah transaction lain, yang undo-nya bisa
+// menimpa penulisan tersebut saat rollback.
+func (s *Store) lockWrite(tx *transaction) func() {
+    if tx == nil {
+        s.txMu.Lock()
+    }
+    s.mu.Lock()
+    return func() {
+        s.mu.Unlock()
+        if tx == nil {
+            s.txMu.Unlock()
+        }
+    }
+}
+
+// now meniru presisi kolom TIMESTAMP Postgres
+func now() time.Time {
+    return time.Now().Truncate(time.Microsecond)
+}
+
+// transaction mencatat cara membatalkan setiap perubahan. Rollback hanya
+// mengembalikan baris yang diubah transaction ini, perubahan lain yang
+// terjadi bersamaan tidak hilang.
+type transaction struct {
+    undo []func()
+}
+
+// set menyimpan value di m dan mencatat nilai lamanya jika tx tidak nil
+func set[K comparable, V any](tx *transaction, m map[K]V, key K, value V) {
+    if tx != nil {
+        old, existed := m[key]
+        tx.undo = append(tx.undo, func() {
+            if existed {
+                m[key] = old
+            } else {
+                delete(m, key)
+            }
+        })
+    }
+    m[key] = value
+}
+
+// rollback menjalankan undo dari perubahan terakhir. Setelah commit undo
+// sudah dikosongkan sehingga rollback tidak melakukan apa pun.
+func (s *Store) rollback(tx *transaction) {
+    if len(tx.undo) == 0 {
+        return
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for i := len(tx.undo) - 1; i >= 0; i-- {
+        tx.undo[i]()
+    }
+    tx.undo = nil
+}
+
+// remove menghapus key dari m dan mencatat nilai lamanya jika tx tidak nil
+func remove[K comparable, V any](tx *transaction, m map[K]V, key K) {
+    old, existed := m[key]
+    if !existed {
+        return
+    }
+    if tx != nil {
+        tx.undo = append(tx.undo, func() { m[key] = old })
+    }
+    delete(m, key)
+}
+
+type txManager struct {
+    store *Store
+}
+
+// NewTxManager membuat TxManager untuk repository memory. Perubahan di
+// dalam transaction langsung terlihat oleh pembaca lain (read
+// uncommitted) dan dibatalkan jika fn mengembalikan error atau panic.
+func NewTxManager(store *Store) domain.TxManager {
+    return &txManager{store: store}
+}
+
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(uow domain.UnitOfWork) error) error {
+    m.store.txMu.Lock()
+    defer m.store.txMu.Unlock()
+
+    // Seperti tx.Rollback pada Postgres, rollback di-defer supaya panic di
+    // fn tetap membatalkan perubahan dan melepas txMu
+    tx := &transaction{}
+    defer m.store.rollback(tx)
+
+    if err := fn(&unitOfWork{store: m.store, tx: tx}); err != nil {
+        return err
+    }
+    tx.undo = nil
+    return nil
+}
+
+type unitOfWork struct {
+    store *Store
+    tx    *transaction
+}
+
+func (u *unitOfWork) Brands() domain.BrandRepository {
+    return &brandRepository{store: u.store, tx: u.tx}
+}
+
+func (u *unitOfWork) Vouchers() domain.VoucherRepository {
+    return &voucherRepository{store: u.store, tx: u.tx}
+}
+
+func (u *unitOfWork) Customers() domain.CustomerRepository {
+    return &customerRepository{store: u.store, tx: u.tx}
+}
+
+func (u *unitOfWork) Transactions() domain.TransactionRepository {
+    return &transactionRepository{store: u.store, tx: u.tx}
+}
+
+func (u *unitOfWork) Audit() domain.AuditRepository {
+    return &auditRepository{store: u.store, tx: u.tx}
+}
+
+func cloneInt(v *int) *int {
+    if v == nil {
+        return nil
+    }
+    c := *v
+    return &c
+}
+
+func cloneInt64(v *int64) *int64 {
+    if v == nil {
+        return nil
+    }
+    c := *v
+    return &c
+}
+
+func cloneBytes(b []byte) []byte {
+    if b == nil {
+        return nil
+    }
+    return append([]byte(nil), b...)
+}
+
+// sortByID mengurutkan rows seperti ORDER BY id
+func sortByID[T any](rows []T, id func(T) int64) {
+    sort.Slice(rows, func(i, j int) bool { return id(rows[i]) < id(rows[j]) })
+}
+
+// inRange meniru whereRange: From inklusif, To eksklusif
+func inRange(t time.Time, r domain.TimeRange) bool {
+    if r.From != nil && t.Before(*r.From) {
+        return false
+    }
+    if r.To != nil && !t.Before(*r.To) {
+        return false
+    }
+    return true
+}
@@ -0,0 +1,293 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+)
+
+type transactionRepository struct {
+    store *Store
+    tx    *transaction
+}
+
+func NewTransactionRepository(store *Store) domain.TransactionRepository {
+    return &transactionRepository{store: store}
+}
+
+// Create menyimpan transaksi baru berstatus pending beserta item-itemnya.
+// Agar atomik, panggil lewat repository dari domain.UnitOfWork.
+func (r *transactionRepository) Create(ctx context.Context, transaction *domain.Transaction, actor string) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if transaction.Status == "" {
+        transaction.Status = domain.TransactionStatusPending
+    }
+    if err := domain.ValidateTransition("", transaction.Status); err != nil {
+        return err
+    }
+    if transaction.TotalPoints < 0 {
+        return domain.ErrConstraintViolation
+    }
+    if _, ok := r.store.customers[transaction.CustomerID]; !ok {
+        return domain.ErrInvalidReference
+    }
+
+    transaction.ID = r.store.nextID("transactions")
+    transaction.CreatedAt = now()
+    transaction.UpdatedAt = transaction.CreatedAt
+    row := *transaction
+    row.Items, row.Refunds, row.History = nil, nil, nil
+    set(r.tx, r.store.transactions, row.ID, row)
+
+    for i := range transaction.Items {
+        transaction.Items[i].TransactionID = transaction.ID
+        if err := r.createTransactionItem(&transaction.Items[i]); err != nil {
+            return err
+        }
+    }
+
+    r.createStatusChange(&domain.TransactionStatusChange{
+        TransactionID: transaction.ID,
+        ToStatus:      transaction.Status,
+        Actor:         actor,
+    })
+    return nil
+}
+
+func (r *transactionRepository) GetByID(ctx context.Context, id int64) (*domain.Transaction, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    return r.getByID(id), nil
+}
+
+// GetByIDForUpdate tidak perlu mengunci baris karena database transaction
+// memory sudah berjalan satu per satu
+func (r *transactionRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Transaction, error) {
+    return r.GetByID(ctx, id)
+}
+
+func (r *transactionRepository) getByID(id int64) *domain.Transaction {
+    row, ok := r.store.transactions[id]
+    if !ok {
+        return nil
+    }
+    transaction := row
+    transaction.Items = r.transactionItems(id)
+    return &transaction
+}
+
+var transactionSortColumns = map[string]sortColumn[domain.Transaction]{
+    "id":           func(t domain.Transaction) interface{} { return t.ID },
+    "total_points": func(t domain.Transaction) interface{} { return t.TotalPoints },
+    "created_at":   func(t domain.Transaction) interface{} { return t.CreatedAt },
+    "updated_at":   func(t domain.Transaction) interface{} { return t.UpdatedAt },
+}
+
+func (r *transactionRepository) GetByCustomerID(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var transactions []domain.Transaction
+    for _, row := range r.store.transactions {
+        if row.CustomerID != customerID {
+            continue
+        }
+        if filter.Status != "" && row.Status != filter.Status {
+            continue
+        }
+        if filter.MinPoints != nil && row.TotalPoints < *filter.MinPoints {
+            continue
+        }
+        if filter.MaxPoints != nil && row.TotalPoints > *filter.MaxPoints {
+            continue
+        }
+        if !inRange(row.CreatedAt, filter.Created) {
+            continue
+        }
+        if filter.BrandID != nil && !r.hasBrand(row.ID, *filter.BrandID) {
+            continue
+        }
+        transactions = append(transactions, row)
+    }
+    return paginate(transactions, filter.Page, transactionSortColumns, func(t domain.Transaction) int64 { return t.ID }, "-created_at")
+}
+
+// hasBrand melaporkan apakah transaksi menukar voucher milik brandID
+func (r *transactionRepository) hasBrand(transactionID, brandID int64) bool {
+    for _, item := range r.store.items {
+        if item.TransactionID == transactionID && r.store.vouchers[item.VoucherID].BrandID == brandID {
+            return true
+        }
+    }
+    return false
+}
+
+// UpdateStatus mengembalikan sql.ErrNoRows jika transaksi tidak ada, sama
+// seperti repository Postgres yang membaca ulang status saat ini
+func (r *transactionRepository) UpdateStatus(ctx context.Context, transaction *domain.Transaction, to domain.TransactionStatus, actor string, reason string) error {
+    if err := domain.ValidateTransition(transaction.Status, to); err != nil {
+        return err
+    }
+
+    defer r.store.lockWrite(r.tx)()
+
+    // Kondisi status lama mencegah perpindahan berdasarkan data yang basi
+    row, ok := r.store.transactions[transaction.ID]
+    if !ok {
+        return sql.ErrNoRows
+    }
+    if row.Status != transaction.Status {
+        return &domain.InvalidTransitionError{From: row.Status, To: to}
+    }
+
+    change := &domain.TransactionStatusChange{
+        TransactionID: transaction.ID,
+        FromStatus:    transaction.Status,
+        ToStatus:      to,
+        Actor:         actor,
+        Reason:        reason,
+    }
+    r.createStatusChange(change)
+    row.Status = to
+    row.UpdatedAt = change.CreatedAt
+    set(r.tx, r.store.transactions, row.ID, row)
+
+    transaction.Status = to
+    transaction.UpdatedAt = change.CreatedAt
+    return nil
+}
+
+func (r *transactionRepository) createStatusChange(change *domain.TransactionStatusChange) {
+    change.ID = r.store.nextID("transaction_status_history")
+    change.CreatedAt = now()
+    set(r.tx, r.store.history, change.ID, *change)
+}
+
+func (r *transactionRepository) GetStatusHistory(ctx context.Context, transactionID int64) ([]domain.TransactionStatusChange, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var history []domain.TransactionStatusChange
+    for _, change := range r.store.history {
+        if change.TransactionID == transactionID {
+            history = append(history, change)
+        }
+    }
+    sortByID(history, func(c domain.TransactionStatusChange) int64 { return c.ID })
+    return history, nil
+}
+
+func (r *transactionRepository) CreateTransactionItem(ctx context.Context, item *domain.TransactionItem) error {
+    defer r.store.lockWrite(r.tx)()
+
+    return r.createTransactionItem(item)
+}
+
+func (r *transactionRepository) createTransactionItem(item *domain.TransactionItem) error {
+    if item.Status == "" {
+        item.Status = domain.TransactionItemStatusRedeemed
+    }
+    if item.PointsUsed <= 0 || !validItemStatus(item.Status) {
+        return domain.ErrConstraintViolation
+    }
+    if _, ok := r.store.transactions[item.TransactionID]; !ok {
+        return domain.ErrInvalidReference
+    }
+    if _, ok := r.store.vouchers[item.VoucherID]; !ok {
+        return domain.ErrInvalidReference
+    }
+
+    item.ID = r.store.nextID("transaction_items")
+    item.CreatedAt = now()
+    row := *item
+    row.Voucher = nil
+    set(r.tx, r.store.items, row.ID, row)
+    return nil
+}
+
+func validItemStatus(status domain.TransactionItemStatus) bool {
+    return status == domain.TransactionItemStatusRedeemed || status == domain.TransactionItemStatusRefunded
+}
+
+func (r *transactionRepository) UpdateTransactionItem(ctx context.Context, item *domain.TransactionItem) error {
+    defer r.store.lockWrite(r.tx)()
+
+    row, ok := r.store.items[item.ID]
+    if !ok {
+        return domain.ErrTransactionItemNotFound
+    }
+    if !validItemStatus(item.Status) {
+        return domain.ErrConstraintViolation
+    }
+    row.Status = item.Status
+    set(r.tx, r.store.items, row.ID, row)
+    return nil
+}
+
+// GetTransactionItems mengisi Voucher dengan code, name, points dan
+// brand_id, sama seperti JOIN di Postgres
+func (r *transactionRepository) GetTransactionItems(ctx context.Context, transactionID int64) ([]domain.TransactionItem, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    return r.transactionItems(transactionID), nil
+}
+
+func (r *transactionRepository) transactionItems(transactionID int64) []domain.TransactionItem {
+    var items []domain.TransactionItem
+    for _, item := range r.store.items {
+        if item.TransactionID != transactionID {
+            continue
+        }
+        voucher := r.store.vouchers[item.VoucherID]
+        item.Voucher = &domain.Voucher{
+            Code:    voucher.Code,
+            Name:    voucher.Name,
+            Points:  voucher.Points,
+            BrandID: voucher.BrandID,
+        }
+        items = append(items, item)
+    }
+    sortByID(items, func(i domain.TransactionItem) int64 { return i.ID })
+    return items
+}
+
+func (r *transactionRepository) CreateRefund(ctx context.Context, refund *domain.Refund) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if refund.Points <= 0 {
+        return domain.ErrConstraintViolation
+    }
+    for _, row := range r.store.refunds {
+        if row.TransactionItemID == refund.TransactionItemID {
+            return domain.ErrTransactionItemRefunded
+        }
+    }
+    if _, ok := r.store.transactions[refund.TransactionID]; !ok {
+        return domain.ErrInvalidReference
+    }
+    if _, ok := r.store.items[refund.TransactionItemID]; !ok {
+        return domain.ErrInvalidReference
+    }
+
+    refund.ID = r.store.nextID("transaction_refunds")
+    refund.CreatedAt = now()
+    set(r.tx, r.store.refunds, refund.ID, *refund)
+    return nil
+}
+
+func (r *transactionRepository) GetRefunds(ctx context.Context, transactionID int64) ([]domain.Refund, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var refunds []domain.Refund
+    for _, refund := range r.store.refunds {
+        if refund.TransactionID == transactionID {
+            refunds = append(refunds, refund)
+        }
+    }
+    sortByID(refunds, func(r domain.Refund) int64 { return r.ID })
+    return refunds, nil
+}
@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+type voucherRepository struct {
+    store *Store
+    tx    *transaction
+}
+
+func NewVoucherRepository(store *Store) domain.VoucherRepository {
+    return &voucherRepository{store: store}
+}
+
+// check meniru CHECK constraint tabel vouchers
+func (r *voucherRepository) check(voucher *domain.Voucher) error {
+    if voucher.Points <= 0 || (voucher.TotalStock != nil && *voucher.TotalStock < 0) {
+        return domain.ErrConstraintViolation
+    }
+    return nil
+}
+
+// codeTaken melaporkan apakah code sudah dipakai voucher selain id
+func (r *voucherRepository) codeTaken(code string, id int64) bool {
+    for _, row := range r.store.vouchers {
+        if row.Code == code && row.ID != id {
+            return true
+        }
+    }
+    return false
+}
+
+func (r *voucherRepository) Create(ctx context.Context, voucher *domain.Voucher) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if err := r.check(voucher); err != nil {
+        return err
+    }
+    if r.codeTaken(voucher.Code, 0) {
+        return domain.ErrVoucherCodeExists
+    }
+    if _, ok := r.store.brands[voucher.BrandID]; !ok {
+        return domain.ErrInvalidReference
+    }
+
+    voucher.ID = r.store.nextID("vouchers")
+    voucher.RemainingStock = cloneInt(voucher.TotalStock)
+    row := cloneVoucher(*voucher)
+    row.CreatedAt = now()
+    row.UpdatedAt = row.CreatedAt
+    set(r.tx, r.store.vouchers, row.ID, row)
+    return nil
+}
+
+// GetByID mengisi Brand tanpa timestamp, sama seperti JOIN di Postgres
+func (r *voucherRepository) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    row, ok := r.store.vouchers[id]
+    if !ok {
+        return nil, nil
+    }
+    voucher := cloneVoucher(row)
+    brand := r.store.brands[row.BrandID]
+    voucher.Brand = &domain.Brand{
+        ID:                        brand.ID,
+        Name:                      brand.Name,
+        Description:               brand.Description,
+        CancellationWindowMinutes: cloneInt(brand.CancellationWindowMinutes),
+    }
+    return &voucher, nil
+}
+
+var voucherSortColumns = map[string]sortColumn[domain.Voucher]{
+    "id":          func(v domain.Voucher) interface{} { return v.ID },
+    "code":        func(v domain.Voucher) interface{} { return v.Code },
+    "name":        func(v domain.Voucher) interface{} { return v.Name },
+    "points":      func(v domain.Voucher) interface{} { return v.Points },
+    "valid_until": func(v domain.Voucher) interface{} { return v.ValidUntil },
+    "created_at":  func(v domain.Voucher) interface{} { return v.CreatedAt },
+}
+
+func (r *voucherRepository) List(ctx context.Context, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    current := time.Now()
+    var vouchers []domain.Voucher
+    for _, row := range r.store.vouchers {
+        if filter.BrandID != nil && row.BrandID != *filter.BrandID {
+            continue
+        }
+        if filter.Valid != nil && row.ValidUntil.After(current) != *filter.Valid {
+            continue
+        }
+        if filter.MinPoints != nil && row.Points < *filter.MinPoints {
+            continue
+        }
+        if filter.MaxPoints != nil && row.Points > *filter.MaxPoints {
+            continue
+        }
+        if !inRange(row.ValidUntil, filter.ValidUntil) || !inRange(row.CreatedAt, filter.Created) {
+            continue
+        }
+        vouchers = append(vouchers, cloneVoucher(row))
+    }
+    return paginate(vouchers, filter.Page, voucherSortColumns, func(v domain.Voucher) int64 { return v.ID }, "id")
+}
+
+// Update menghitung ulang remaining_stock supaya unit yang sudah terjual
+// tetap dihitung: remaining = total baru - jumlah terjual
+func (r *voucherRepository) Update(ctx context.Context, voucher *domain.Voucher) error {
+    defer r.store.lockWrite(r.tx)()
+
+    row, ok := r.store.vouchers[voucher.ID]
+    if !ok {
+        return domain.ErrVoucherNotFound
+    }
+    if err := r.check(voucher); err != nil {
+        return err
+    }
+    if r.codeTaken(voucher.Code, voucher.ID) {
+        return domain.ErrVoucherCodeExists
+    }
+    if _, ok := r.store.brands[voucher.BrandID]; !ok {
+        return domain.ErrInvalidReference
+    }
+
+    var remaining *int
+    if voucher.TotalStock != nil {
+        sold := 0
+        if row.TotalStock != nil && row.RemainingStock != nil {
+            sold = *row.TotalStock - *row.RemainingStock
+        }
+        stock := max(*voucher.TotalStock-sold, 0)
+        remaining = &stock
+    }
+
+    row.BrandID = voucher.BrandID
+    row.Code = voucher.Code
+    row.Name = voucher.Name
+    row.Description = voucher.Description
+    row.Points = voucher.Points
+    row.ValidUntil = voucher.ValidUntil
+    row.TotalStock = cloneInt(voucher.TotalStock)
+    row.RemainingStock = remaining
+    row.UpdatedAt = now()
+    set(r.tx, r.store.vouchers, row.ID, row)
+
+    voucher.RemainingStock = cloneInt(remaining)
+    voucher.CreatedAt = row.CreatedAt
+    voucher.UpdatedAt = row.UpdatedAt
+    return nil
+}
+
+func (r *voucherRepository) Delete(ctx context.Context, id int64) error {
+    defer r.store.lockWrite(r.tx)()
+
+    if _, ok := r.store.vouchers[id]; !ok {
+        return domain.ErrVoucherNotFound
+    }
+    for _, item := range r.store.items {
+        if item.VoucherID == id {
+            return domain.ErrVoucherRedeemed
+        }
+    }
+    remove(r.tx, r.store.vouchers, id)
+    return nil
+}
+
+func (r *voucherRepository) DecrementStock(ctx context.Context, id int64) error {
+    defer r.store.lockWrite(r.tx)()
+
+    row, ok := r.store.vouchers[id]
+    if !ok || (row.RemainingStock != nil && *row.RemainingStock <= 0) {
+        return fmt.Errorf("%w: voucher %d", domain.ErrVoucherSoldOut, id)
+    }
+    if row.RemainingStock != nil {
+        row.RemainingStock = cloneInt(row.RemainingStock)
+        *row.RemainingStock--
+        set(r.tx, r.store.vouchers, id, row)
+    }
+    return nil
+}
+
+// IncrementStock tidak mengubah voucher tanpa kuota dan tidak pernah
+// melebihi total_stock
+func (r *voucherRepository) IncrementStock(ctx context.Context, id int64) error {
+    defer r.store.lockWrite(r.tx)()
+
+    row, ok := r.store.vouchers[id]
+    if !ok || row.RemainingStock == nil || *row.RemainingStock >= *row.TotalStock {
+        return nil
+    }
+    row.RemainingStock = cloneInt(row.RemainingStock)
+    *row.RemainingStock++
+    set(r.tx, r.store.vouchers, id, row)
+    return nil
+}
+
+func cloneVoucher(v domain.Voucher) domain.Voucher {
+    v.TotalStock = cloneInt(v.TotalStock)
+    v.RemainingStock = cloneInt(v.RemainingStock)
+    v.Brand = nil
+    return v
+}
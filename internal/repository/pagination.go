@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// sortColumn adalah kolom yang boleh dipakai untuk sort. Kolom harus NOT NULL
+// supaya perbandingan keyset pada cursor selalu menghasilkan nilai.
+type sortColumn struct {
+    column string
+    // cast adalah tipe Postgres untuk membaca kembali nilai cursor
+    cast string
+}
+
+// cursor disimpan sebagai base64 JSON. Sort ikut disimpan supaya cursor dari
+// urutan lain ditolak.
+type cursor struct {
+    Sort  string `json:"s"`
+    Value string `json:"v"`
+    ID    int64  `json:"id"`
+}
+
+// listQuery mengumpulkan kondisi WHERE dan argumennya
+type listQuery struct {
+    conditions []string
+    args       []interface{}
+}
+
+// where menambah kondisi dengan satu argumen, %s diganti placeholder $n
+func (q *listQuery) where(condition string, arg interface{}) {
+    q.args = append(q.args, arg)
+    q.conditions = append(q.conditions, fmt.Sprintf(condition, "$"+strconv.Itoa(len(q.args))))
+}
+
+// likeEscaper meng-escape wildcard LIKE supaya input dicari apa adanya
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereContains mencari sebagian nilai tanpa membedakan huruf besar kecil
+func (q *listQuery) whereContains(column, value string) {
+    q.where(column+` ILIKE '%%' || %s || '%%' ESCAPE '\'`, likeEscaper.Replace(value))
+}
+
+func (q *listQuery) whereRange(column string, timeRange domain.TimeRange) {
+    if timeRange.From != nil {
+        q.where(column+" >= %s", *timeRange.From)
+    }
+    if timeRange.To != nil {
+        q.where(column+" < %s", *timeRange.To)
+    }
+}
+
+func (q *listQuery) clause() string {
+    if len(q.conditions) == 0 {
+        return ""
+    }
+    return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// pagination menerjemahkan domain.Page menjadi ORDER BY, LIMIT dan kondisi
+// keyset. Urutan selalu ditambah kolom id supaya stabil.
+type pagination struct {
+    sort     string
+    column   sortColumn
+    idColumn string
+    desc     bool
+    limit    int
+}
+
+func newPagination(page domain.Page, columns map[string]sortColumn, idColumn, defaultSort string) (*pagination, error) {
+    sort := page.Sort
+    if sort == "" {
+        sort = defaultSort
+    }
+
+    column, ok := columns[strings.TrimPrefix(sort, "-")]
+    if !ok {
+        return nil, fmt.Errorf("%w: %s", domain.ErrInvalidSort, strings.TrimPrefix(sort, "-"))
+    }
+
+    return &pagination{
+        sort:     sort,
+        column:   column,
+        idColumn: idColumn,
+        desc:     strings.HasPrefix(sort, "-"),
+        limit:    page.PageLimit(),
+    }, nil
+}
+
+// after menambahkan kondisi keyset untuk melanjutkan dari cursor
+func (p *pagination) after(q *listQuery, encoded string) error {
+    if encoded == "" {
+        return nil
+    }
+
+    raw, err := base64.RawURLEncoding.DecodeString(encoded)
+    if err != nil {
+        return domain.ErrInvalidCursor
+    }
+    var c cursor
+    if err := json.Unmarshal(raw, &c); err != nil || c.Sort != p.sort {
+        return domain.ErrInvalidCursor
+    }
+    if !validCursorValue(p.column.cast, c.Value) {
+        return domain.ErrInvalidCursor
+    }
+
+    operator := ">"
+    if p.desc {
+        operator = "<"
+    }
+    q.args = append(q.args, c.Value, c.ID)
+    q.conditions = append(q.conditions, fmt.Sprintf(
+        "(%s, %s) %s ($%d::%s, $%d)",
+        p.column.column, p.idColumn, operator, len(q.args)-1, p.column.cast, len(q.args),
+    ))
+    return nil
+}
+
+// cursorTimestampLayout adalah format timestamp::text Postgres
+const cursorTimestampLayout = "2006-01-02 15:04:05.999999999"
+
+// validCursorValue memastikan nilai cursor bisa di-cast ke tipe kolomnya,
+// supaya cursor yang diubah client menjadi ErrInvalidCursor, bukan error
+// database
+func validCursorValue(cast, value string) bool {
+    switch cast {
+    case "bigint":
+        _, err := strconv.ParseInt(value, 10, 64)
+        return err == nil
+    case "integer":
+        _, err := strconv.ParseInt(value, 10, 32)
+        return err == nil
+    case "timestamp":
+        _, err := time.Parse(cursorTimestampLayout, value)
+        return err == nil
+    case "text":
+        return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+    }
+    return false
+}
+
+// selectValue adalah kolom tambahan berisi nilai sort dalam bentuk teks
+func (p *pagination) selectValue() string {
+    return p.column.column + "::text"
+}
+
+// tail adalah ORDER BY dan LIMIT. Satu baris ekstra diambil untuk mengetahui
+// apakah masih ada halaman berikutnya.
+func (p *pagination) tail() string {
+    direction := "ASC"
+    if p.desc {
+        direction = "DESC"
+    }
+    return fmt.Sprintf(
+        "ORDER BY %s %s, %s %s LIMIT %d",
+        p.column.column, direction, p.idColumn, direction, p.limit+1,
+    )
+}
+
+// hasMore melaporkan apakah jumlah baris yang dibaca melebihi limit
+func (p *pagination) hasMore(count int) bool {
+    return count > p.limit
+}
+
+// next membuat cursor dari baris terakhir halaman ini
+func (p *pagination) next(id int64, value string) string {
+    raw, _ := json.Marshal(cursor{Sort: p.sort, Value: value, ID: id})
+    return base64.RawURLEncoding.EncodeToString(raw)
+}
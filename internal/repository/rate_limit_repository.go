@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type rateLimitRepository struct {
+    db dbtx
+}
+
+// NewRateLimitRepository menyimpan token bucket di Postgres supaya batasnya
+// berlaku bersama di semua instance aplikasi
+func NewRateLimitRepository(db *sql.DB) domain.RateLimitRepository {
+    return &rateLimitRepository{db: traced(db)}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitResult, error) {
+    // Refill dan pengambilan token dilakukan dalam satu statement supaya
+    // request bersamaan dari beberapa instance tidak memakai token yang
+    // sama. Jika token kurang dari satu, WHERE membuat tidak ada baris yang
+    // diubah maupun dikembalikan.
+    query := `
+        INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+        VALUES ($1, $2::double precision - 1, $3::timestamptz)
+        ON CONFLICT (bucket_key) DO UPDATE
+        SET tokens = LEAST($2::double precision, rate_limit_buckets.tokens +
+                GREATEST(0, EXTRACT(EPOCH FROM ($3::timestamptz - rate_limit_buckets.updated_at))::double precision) * $4::double precision) - 1,
+            updated_at = GREATEST(rate_limit_buckets.updated_at, $3::timestamptz)
+        WHERE LEAST($2::double precision, rate_limit_buckets.tokens +
+                GREATEST(0, EXTRACT(EPOCH FROM ($3::timestamptz - rate_limit_buckets.updated_at))::double precision) * $4::double precision) >= 1
+        RETURNING tokens`
+
+    var tokens float64
+    err := r.db.QueryRowContext(ctx, query, key, float64(policy.Limit), now, policy.Rate()).Scan(&tokens)
+    if err == nil {
+        return policy.Result(true, tokens), nil
+    }
+    if err != sql.ErrNoRows {
+        return domain.RateLimitResult{}, err
+    }
+
+    // Ditolak, baca isi bucket untuk menghitung Retry-After
+    var updatedAt time.Time
+    err = r.db.QueryRowContext(ctx,
+        `SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = $1`,
+        key,
+    ).Scan(&tokens, &updatedAt)
+    if err != nil && err != sql.ErrNoRows {
+        return domain.RateLimitResult{}, err
+    }
+    return policy.Result(false, policy.Refill(tokens, updatedAt, now)), nil
+}
+
+func (r *rateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+    result, err := r.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+    if err != nil {
+        return 0, err
+    }
+    return result.RowsAffected()
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"database/sql"
+)
+
+type roleRepository struct {
+    db dbtx
+}
+
+func NewRoleRepository(db *sql.DB) domain.RoleRepository {
+    return &roleRepository{db: traced(db)}
+}
+
+func (r *roleRepository) Permissions(ctx context.Context, role domain.Role) ([]domain.Permission, error) {
+    query := `
+        SELECT permission
+        FROM role_permissions
+        WHERE role = $1
+        ORDER BY permission`
+
+    rows, err := r.db.QueryContext(ctx, query, role)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var permissions []domain.Permission
+    for rows.Next() {
+        var permission domain.Permission
+        if err := rows.Scan(&permission); err != nil {
+            return nil, err
+        }
+        permissions = append(permissions, permission)
+    }
+    return permissions, rows.Err()
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"api-otto/internal/tracing"
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "api-otto/internal/repository"
+
+// tracedDB membuat span untuk setiap statement SQL. Nilai parameter tidak
+// dicatat, hanya teks query-nya.
+type tracedDB struct {
+    db dbtx
+}
+
+func traced(db dbtx) dbtx {
+    return tracedDB{db: db}
+}
+
+func (t tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    ctx, span := startQuerySpan(ctx, query)
+    result, err := t.db.ExecContext(ctx, query, args...)
+    tracing.End(span, err)
+    return result, err
+}
+
+// QueryContext mengakhiri span saat baris pertama siap dibaca, waktu
+// membaca seluruh baris tidak termasuk
+func (t tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+    ctx, span := startQuerySpan(ctx, query)
+    rows, err := t.db.QueryContext(ctx, query, args...)
+    tracing.End(span, err)
+    return rows, err
+}
+
+func (t tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+    ctx, span := startQuerySpan(ctx, query)
+    row := t.db.QueryRowContext(ctx, query, args...)
+    // row.Err tidak pernah berisi sql.ErrNoRows, error itu baru muncul saat Scan
+    tracing.End(span, row.Err())
+    return row
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+    statement := strings.Join(strings.Fields(query), " ")
+    operation, _, _ := strings.Cut(statement, " ")
+    operation = strings.ToUpper(operation)
+    return otel.Tracer(tracerName).Start(ctx, operation,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            attribute.String("db.system", "postgresql"),
+            attribute.String("db.operation", operation),
+            attribute.String("db.statement", statement),
+        ),
+    )
+}
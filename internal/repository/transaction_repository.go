@@ -2,79 +2,87 @@ package repository
 
 import (
 	"api-otto/internal/domain"
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
 type transactionRepository struct {
-    db *sql.DB
+    db dbtx
 }
 
 func NewTransactionRepository(db *sql.DB) domain.TransactionRepository {
-    return &transactionRepository{db: db}
+    return &transactionRepository{db: traced(db)}
 }
 
-func (r *transactionRepository) Create(transaction *domain.Transaction) error {
-    tx, err := r.db.Begin()
-    if err != nil {
+// Create menyimpan transaksi baru berstatus pending beserta item-itemnya.
+// Agar atomik, panggil lewat repository dari domain.UnitOfWork.
+func (r *transactionRepository) Create(ctx context.Context, transaction *domain.Transaction, actor string) error {
+    if transaction.Status == "" {
+        transaction.Status = domain.TransactionStatusPending
+    }
+    if err := domain.ValidateTransition("", transaction.Status); err != nil {
         return err
     }
-    defer tx.Rollback()
 
     query := `
-        INSERT INTO transactions (customer_id, total_points, status, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $4)
-        RETURNING id`
+        INSERT INTO transactions (customer_id, total_points, status, failure_reason, created_at, updated_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), $5, $5)
+        RETURNING id, created_at, updated_at`
 
     now := time.Now()
-    err = tx.QueryRow(
+    err := r.db.QueryRowContext(ctx,
         query,
         transaction.CustomerID,
         transaction.TotalPoints,
         transaction.Status,
+        transaction.FailureReason,
         now,
-    ).Scan(&transaction.ID)
+    ).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
     if err != nil {
-        return err
+        return translateError(err)
     }
 
-    for _, item := range transaction.Items {
-        err = r.createTransactionItemTx(tx, transaction.ID, &item)
-        if err != nil {
+    for i := range transaction.Items {
+        transaction.Items[i].TransactionID = transaction.ID
+        if err := r.CreateTransactionItem(ctx, &transaction.Items[i]); err != nil {
             return err
         }
     }
 
-    return tx.Commit()
+    return r.createStatusChange(ctx, &domain.TransactionStatusChange{
+        TransactionID: transaction.ID,
+        ToStatus:      transaction.Status,
+        Actor:         actor,
+    })
 }
 
-func (r *transactionRepository) createTransactionItemTx(tx *sql.Tx, transactionID int64, item *domain.TransactionItem) error {
-    query := `
-        INSERT INTO transaction_items (transaction_id, voucher_id, points_used, created_at)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id`
+func (r *transactionRepository) GetByID(ctx context.Context, id int64) (*domain.Transaction, error) {
+    return r.getByID(ctx, id, false)
+}
 
-    return tx.QueryRow(
-        query,
-        transactionID,
-        item.VoucherID,
-        item.PointsUsed,
-        time.Now(),
-    ).Scan(&item.ID)
+func (r *transactionRepository) GetByIDForUpdate(ctx context.Context, id int64) (*domain.Transaction, error) {
+    return r.getByID(ctx, id, true)
 }
 
-func (r *transactionRepository) GetByID(id int64) (*domain.Transaction, error) {
+func (r *transactionRepository) getByID(ctx context.Context, id int64, forUpdate bool) (*domain.Transaction, error) {
     transaction := &domain.Transaction{}
     query := `
-        SELECT id, customer_id, total_points, status, created_at, updated_at
+        SELECT id, customer_id, total_points, status, COALESCE(failure_reason, ''), created_at, updated_at
         FROM transactions
         WHERE id = $1`
+    if forUpdate {
+        query += `
+        FOR UPDATE`
+    }
 
-    err := r.db.QueryRow(query, id).Scan(
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
         &transaction.ID,
         &transaction.CustomerID,
         &transaction.TotalPoints,
         &transaction.Status,
+        &transaction.FailureReason,
         &transaction.CreatedAt,
         &transaction.UpdatedAt,
     )
@@ -85,7 +93,7 @@ func (r *transactionRepository) GetByID(id int64) (*domain.Transaction, error) {
         return nil, err
     }
 
-    items, err := r.GetTransactionItems(id)
+    items, err := r.GetTransactionItems(ctx, id)
     if err != nil {
         return nil, err
     }
@@ -94,48 +102,102 @@ func (r *transactionRepository) GetByID(id int64) (*domain.Transaction, error) {
     return transaction, nil
 }
 
-func (r *transactionRepository) GetByCustomerID(customerID int64) ([]domain.Transaction, error) {
-    query := `
-        SELECT id, customer_id, total_points, status, created_at, updated_at
+var transactionSortColumns = map[string]sortColumn{
+    "id":           {column: "id", cast: "bigint"},
+    "total_points": {column: "total_points", cast: "integer"},
+    "created_at":   {column: "created_at", cast: "timestamp"},
+    "updated_at":   {column: "updated_at", cast: "timestamp"},
+}
+
+func (r *transactionRepository) GetByCustomerID(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+    page, err := newPagination(filter.Page, transactionSortColumns, "id", "-created_at")
+    if err != nil {
+        return nil, "", err
+    }
+
+    q := &listQuery{}
+    q.where("customer_id = %s", customerID)
+    if filter.Status != "" {
+        q.where("status = %s", filter.Status)
+    }
+    if filter.MinPoints != nil {
+        q.where("total_points >= %s", *filter.MinPoints)
+    }
+    if filter.MaxPoints != nil {
+        q.where("total_points <= %s", *filter.MaxPoints)
+    }
+    q.whereRange("created_at", filter.Created)
+    if filter.BrandID != nil {
+        q.where(`EXISTS (
+            SELECT 1 FROM transaction_items ti
+            JOIN vouchers v ON ti.voucher_id = v.id
+            WHERE ti.transaction_id = transactions.id AND v.brand_id = %s)`, *filter.BrandID)
+    }
+    if err := page.after(q, filter.Cursor); err != nil {
+        return nil, "", err
+    }
+
+    query := fmt.Sprintf(`
+        SELECT id, customer_id, total_points, status, COALESCE(failure_reason, ''), created_at, updated_at, %s
         FROM transactions
-        WHERE customer_id = $1
-        ORDER BY created_at DESC`
+        %s
+        %s`, page.selectValue(), q.clause(), page.tail())
 
-    rows, err := r.db.Query(query, customerID)
+    rows, err := r.db.QueryContext(ctx, query, q.args...)
     if err != nil {
-        return nil, err
+        return nil, "", err
     }
     defer rows.Close()
 
     var transactions []domain.Transaction
+    var values []string
     for rows.Next() {
         var transaction domain.Transaction
+        var value string
         if err := rows.Scan(
             &transaction.ID,
             &transaction.CustomerID,
             &transaction.TotalPoints,
             &transaction.Status,
+            &transaction.FailureReason,
             &transaction.CreatedAt,
             &transaction.UpdatedAt,
+            &value,
         ); err != nil {
-            return nil, err
+            return nil, "", err
         }
         transactions = append(transactions, transaction)
+        values = append(values, value)
     }
-    return transactions, nil
+    if err := rows.Err(); err != nil {
+        return nil, "", err
+    }
+
+    if !page.hasMore(len(transactions)) {
+        return transactions, "", nil
+    }
+    transactions = transactions[:page.limit]
+    last := len(transactions) - 1
+    return transactions, page.next(transactions[last].ID, values[last]), nil
 }
 
-func (r *transactionRepository) Update(transaction *domain.Transaction) error {
+func (r *transactionRepository) UpdateStatus(ctx context.Context, transaction *domain.Transaction, to domain.TransactionStatus, actor string, reason string) error {
+    if err := domain.ValidateTransition(transaction.Status, to); err != nil {
+        return err
+    }
+
+    // Kondisi status lama mencegah perpindahan berdasarkan data yang basi
     query := `
         UPDATE transactions
         SET status = $1, updated_at = $2
-        WHERE id = $3`
+        WHERE id = $3 AND status = $4`
 
-    result, err := r.db.Exec(
+    result, err := r.db.ExecContext(ctx,
         query,
-        transaction.Status,
+        to,
         time.Now(),
         transaction.ID,
+        transaction.Status,
     )
     if err != nil {
         return err
@@ -146,35 +208,130 @@ func (r *transactionRepository) Update(transaction *domain.Transaction) error {
         return err
     }
     if rows == 0 {
-        return sql.ErrNoRows
+        var current domain.TransactionStatus
+        err := r.db.QueryRowContext(ctx, `SELECT status FROM transactions WHERE id = $1`, transaction.ID).Scan(&current)
+        if err != nil {
+            return err
+        }
+        return &domain.InvalidTransitionError{From: current, To: to}
+    }
+
+    change := &domain.TransactionStatusChange{
+        TransactionID: transaction.ID,
+        FromStatus:    transaction.Status,
+        ToStatus:      to,
+        Actor:         actor,
+        Reason:        reason,
     }
+    if err := r.createStatusChange(ctx, change); err != nil {
+        return err
+    }
+
+    transaction.Status = to
+    transaction.UpdatedAt = change.CreatedAt
     return nil
 }
 
-func (r *transactionRepository) CreateTransactionItem(item *domain.TransactionItem) error {
+func (r *transactionRepository) createStatusChange(ctx context.Context, change *domain.TransactionStatusChange) error {
     query := `
-        INSERT INTO transaction_items (transaction_id, voucher_id, points_used, created_at)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id`
+        INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason, created_at)
+        VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), $6)
+        RETURNING id, created_at`
 
-    return r.db.QueryRow(
+    return r.db.QueryRowContext(ctx,
+        query,
+        change.TransactionID,
+        change.FromStatus,
+        change.ToStatus,
+        change.Actor,
+        change.Reason,
+        time.Now(),
+    ).Scan(&change.ID, &change.CreatedAt)
+}
+
+func (r *transactionRepository) GetStatusHistory(ctx context.Context, transactionID int64) ([]domain.TransactionStatusChange, error) {
+    query := `
+        SELECT id, transaction_id, COALESCE(from_status, ''), to_status, actor, COALESCE(reason, ''), created_at
+        FROM transaction_status_history
+        WHERE transaction_id = $1
+        ORDER BY id`
+
+    rows, err := r.db.QueryContext(ctx, query, transactionID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var history []domain.TransactionStatusChange
+    for rows.Next() {
+        var change domain.TransactionStatusChange
+        if err := rows.Scan(
+            &change.ID,
+            &change.TransactionID,
+            &change.FromStatus,
+            &change.ToStatus,
+            &change.Actor,
+            &change.Reason,
+            &change.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        history = append(history, change)
+    }
+    return history, nil
+}
+
+func (r *transactionRepository) CreateTransactionItem(ctx context.Context, item *domain.TransactionItem) error {
+    query := `
+        INSERT INTO transaction_items (transaction_id, voucher_id, points_used, status, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+
+    if item.Status == "" {
+        item.Status = domain.TransactionItemStatusRedeemed
+    }
+    err := r.db.QueryRowContext(ctx,
         query,
         item.TransactionID,
         item.VoucherID,
         item.PointsUsed,
+        item.Status,
         time.Now(),
-    ).Scan(&item.ID)
+    ).Scan(&item.ID, &item.CreatedAt)
+    return translateError(err)
 }
 
-func (r *transactionRepository) GetTransactionItems(transactionID int64) ([]domain.TransactionItem, error) {
+func (r *transactionRepository) UpdateTransactionItem(ctx context.Context, item *domain.TransactionItem) error {
     query := `
-        SELECT ti.id, ti.transaction_id, ti.voucher_id, ti.points_used, ti.created_at,
-               v.code, v.name, v.points
+        UPDATE transaction_items
+        SET status = $1
+        WHERE id = $2`
+
+    result, err := r.db.ExecContext(ctx, query, item.Status, item.ID)
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return domain.ErrTransactionItemNotFound
+    }
+    return nil
+}
+
+func (r *transactionRepository) GetTransactionItems(ctx context.Context, transactionID int64) ([]domain.TransactionItem, error) {
+    query := `
+        SELECT ti.id, ti.transaction_id, ti.voucher_id, ti.points_used, ti.status, ti.created_at,
+               v.code, v.name, v.points, v.brand_id
         FROM transaction_items ti
         LEFT JOIN vouchers v ON ti.voucher_id = v.id
-        WHERE ti.transaction_id = $1`
+        WHERE ti.transaction_id = $1
+        ORDER BY ti.id`
 
-    rows, err := r.db.Query(query, transactionID)
+    rows, err := r.db.QueryContext(ctx, query, transactionID)
     if err != nil {
         return nil, err
     }
@@ -190,14 +347,66 @@ func (r *transactionRepository) GetTransactionItems(transactionID int64) ([]doma
             &item.TransactionID,
             &item.VoucherID,
             &item.PointsUsed,
+            &item.Status,
             &item.CreatedAt,
             &item.Voucher.Code,
             &item.Voucher.Name,
             &item.Voucher.Points,
+            &item.Voucher.BrandID,
         ); err != nil {
             return nil, err
         }
         items = append(items, item)
     }
     return items, nil
-} 
\ No newline at end of file
+} 
+
+func (r *transactionRepository) CreateRefund(ctx context.Context, refund *domain.Refund) error {
+    query := `
+        INSERT INTO transaction_refunds (transaction_id, transaction_item_id, points, actor, reason, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at`
+
+    err := r.db.QueryRowContext(ctx,
+        query,
+        refund.TransactionID,
+        refund.TransactionItemID,
+        refund.Points,
+        refund.Actor,
+        refund.Reason,
+        time.Now(),
+    ).Scan(&refund.ID, &refund.CreatedAt)
+    return translateError(err)
+}
+
+func (r *transactionRepository) GetRefunds(ctx context.Context, transactionID int64) ([]domain.Refund, error) {
+    query := `
+        SELECT id, transaction_id, transaction_item_id, points, actor, reason, created_at
+        FROM transaction_refunds
+        WHERE transaction_id = $1
+        ORDER BY id`
+
+    rows, err := r.db.QueryContext(ctx, query, transactionID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var refunds []domain.Refund
+    for rows.Next() {
+        var refund domain.Refund
+        if err := rows.Scan(
+            &refund.ID,
+            &refund.TransactionID,
+            &refund.TransactionItemID,
+            &refund.Points,
+            &refund.Actor,
+            &refund.Reason,
+            &refund.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        refunds = append(refunds, refund)
+    }
+    return refunds, nil
+}
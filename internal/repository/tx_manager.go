@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/tracing"
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dbtx dipenuhi oleh *sql.DB maupun *sql.Tx, sehingga repository yang sama
+// bisa dipakai di dalam maupun di luar database transaction.
+type dbtx interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txManager struct {
+    db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) domain.TxManager {
+    return &txManager{db: db}
+}
+
+// WithinTransaction membuat satu span untuk seluruh database transaction,
+// statement di dalamnya menjadi child span
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(uow domain.UnitOfWork) error) (err error) {
+    ctx, span := otel.Tracer(tracerName).Start(ctx, "db.transaction", trace.WithSpanKind(trace.SpanKindClient))
+    defer func() { tracing.End(span, err) }()
+
+    tx, err := m.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := fn(&unitOfWork{tx: tx}); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+type unitOfWork struct {
+    tx *sql.Tx
+}
+
+func (u *unitOfWork) Brands() domain.BrandRepository {
+    return &brandRepository{db: traced(u.tx)}
+}
+
+func (u *unitOfWork) Vouchers() domain.VoucherRepository {
+    return &voucherRepository{db: traced(u.tx)}
+}
+
+func (u *unitOfWork) Customers() domain.CustomerRepository {
+    return &customerRepository{db: traced(u.tx)}
+}
+
+func (u *unitOfWork) Transactions() domain.TransactionRepository {
+    return &transactionRepository{db: traced(u.tx)}
+}
+
+func (u *unitOfWork) Audit() domain.AuditRepository {
+    return &auditRepository{db: traced(u.tx)}
+}
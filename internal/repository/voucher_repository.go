@@ -2,26 +2,29 @@ package repository
 
 import (
 	"api-otto/internal/domain"
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
 type voucherRepository struct {
-    db *sql.DB
+    db dbtx
 }
 
 func NewVoucherRepository(db *sql.DB) domain.VoucherRepository {
-    return &voucherRepository{db: db}
+    return &voucherRepository{db: traced(db)}
 }
 
-func (r *voucherRepository) Create(voucher *domain.Voucher) error {
+func (r *voucherRepository) Create(ctx context.Context, voucher *domain.Voucher) error {
     query := `
-        INSERT INTO vouchers (brand_id, code, name, description, points, valid_until, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
-        RETURNING id`
+        INSERT INTO vouchers (brand_id, code, name, description, points, valid_until,
+                              total_stock, remaining_stock, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8, $8)
+        RETURNING id, remaining_stock`
 
     now := time.Now()
-    return r.db.QueryRow(
+    err := r.db.QueryRowContext(ctx,
         query,
         voucher.BrandID,
         voucher.Code,
@@ -29,15 +32,17 @@ func (r *voucherRepository) Create(voucher *domain.Voucher) error {
         voucher.Description,
         voucher.Points,
         voucher.ValidUntil,
+        voucher.TotalStock,
         now,
-    ).Scan(&voucher.ID)
+    ).Scan(&voucher.ID, &voucher.RemainingStock)
+    return translateError(err)
 }
 
-func (r *voucherRepository) GetByID(id int64) (*domain.Voucher, error) {
+func (r *voucherRepository) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
     query := `
         SELECT v.id, v.brand_id, v.code, v.name, v.description, v.points, v.valid_until, 
-               v.created_at, v.updated_at,
-               b.id, b.name, b.description
+               v.total_stock, v.remaining_stock, v.created_at, v.updated_at,
+               b.id, b.name, b.description, b.cancellation_window_minutes
         FROM vouchers v
         LEFT JOIN brands b ON v.brand_id = b.id
         WHERE v.id = $1`
@@ -46,7 +51,7 @@ func (r *voucherRepository) GetByID(id int64) (*domain.Voucher, error) {
         Brand: &domain.Brand{},
     }
 
-    err := r.db.QueryRow(query, id).Scan(
+    err := r.db.QueryRowContext(ctx, query, id).Scan(
         &voucher.ID,
         &voucher.BrandID,
         &voucher.Code,
@@ -54,11 +59,14 @@ func (r *voucherRepository) GetByID(id int64) (*domain.Voucher, error) {
         &voucher.Description,
         &voucher.Points,
         &voucher.ValidUntil,
+        &voucher.TotalStock,
+        &voucher.RemainingStock,
         &voucher.CreatedAt,
         &voucher.UpdatedAt,
         &voucher.Brand.ID,
         &voucher.Brand.Name,
         &voucher.Brand.Description,
+        &voucher.Brand.CancellationWindowMinutes,
     )
     if err == sql.ErrNoRows {
         return nil, nil
@@ -66,57 +74,62 @@ func (r *voucherRepository) GetByID(id int64) (*domain.Voucher, error) {
     return voucher, err
 }
 
-func (r *voucherRepository) GetByBrandID(brandID int64) ([]domain.Voucher, error) {
-    query := `
-        SELECT v.id, v.brand_id, v.code, v.name, v.description, v.points, v.valid_until, 
-               v.created_at, v.updated_at
-        FROM vouchers v
-        WHERE v.brand_id = $1
-        ORDER BY v.id`
+var voucherSortColumns = map[string]sortColumn{
+    "id":          {column: "v.id", cast: "bigint"},
+    "code":        {column: "v.code", cast: "text"},
+    "name":        {column: "v.name", cast: "text"},
+    "points":      {column: "v.points", cast: "integer"},
+    "valid_until": {column: "v.valid_until", cast: "timestamp"},
+    "created_at":  {column: "v.created_at", cast: "timestamp"},
+}
 
-    rows, err := r.db.Query(query, brandID)
+func (r *voucherRepository) List(ctx context.Context, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+    page, err := newPagination(filter.Page, voucherSortColumns, "v.id", "id")
     if err != nil {
-        return nil, err
+        return nil, "", err
     }
-    defer rows.Close()
 
-    var vouchers []domain.Voucher
-    for rows.Next() {
-        var voucher domain.Voucher
-        if err := rows.Scan(
-            &voucher.ID,
-            &voucher.BrandID,
-            &voucher.Code,
-            &voucher.Name,
-            &voucher.Description,
-            &voucher.Points,
-            &voucher.ValidUntil,
-            &voucher.CreatedAt,
-            &voucher.UpdatedAt,
-        ); err != nil {
-            return nil, err
+    q := &listQuery{}
+    if filter.BrandID != nil {
+        q.where("v.brand_id = %s", *filter.BrandID)
+    }
+    if filter.Valid != nil {
+        if *filter.Valid {
+            q.where("v.valid_until > %s", time.Now())
+        } else {
+            q.where("v.valid_until <= %s", time.Now())
         }
-        vouchers = append(vouchers, voucher)
     }
-    return vouchers, nil
-}
+    if filter.MinPoints != nil {
+        q.where("v.points >= %s", *filter.MinPoints)
+    }
+    if filter.MaxPoints != nil {
+        q.where("v.points <= %s", *filter.MaxPoints)
+    }
+    q.whereRange("v.valid_until", filter.ValidUntil)
+    q.whereRange("v.created_at", filter.Created)
+    if err := page.after(q, filter.Cursor); err != nil {
+        return nil, "", err
+    }
 
-func (r *voucherRepository) List() ([]domain.Voucher, error) {
-    query := `
+    query := fmt.Sprintf(`
         SELECT v.id, v.brand_id, v.code, v.name, v.description, v.points, v.valid_until, 
-               v.created_at, v.updated_at
+               v.total_stock, v.remaining_stock, v.created_at, v.updated_at, %s
         FROM vouchers v
-        ORDER BY v.id`
+        %s
+        %s`, page.selectValue(), q.clause(), page.tail())
 
-    rows, err := r.db.Query(query)
+    rows, err := r.db.QueryContext(ctx, query, q.args...)
     if err != nil {
-        return nil, err
+        return nil, "", err
     }
     defer rows.Close()
 
     var vouchers []domain.Voucher
+    var values []string
     for rows.Next() {
         var voucher domain.Voucher
+        var value string
         if err := rows.Scan(
             &voucher.ID,
             &voucher.BrandID,
@@ -125,24 +138,46 @@ func (r *voucherRepository) List() ([]domain.Voucher, error) {
             &voucher.Description,
             &voucher.Points,
             &voucher.ValidUntil,
+            &voucher.TotalStock,
+            &voucher.RemainingStock,
             &voucher.CreatedAt,
             &voucher.UpdatedAt,
+            &value,
         ); err != nil {
-            return nil, err
+            return nil, "", err
         }
         vouchers = append(vouchers, voucher)
+        values = append(values, value)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, "", err
+    }
+
+    if !page.hasMore(len(vouchers)) {
+        return vouchers, "", nil
     }
-    return vouchers, nil
+    vouchers = vouchers[:page.limit]
+    last := len(vouchers) - 1
+    return vouchers, page.next(vouchers[last].ID, values[last]), nil
 }
 
-func (r *voucherRepository) Update(voucher *domain.Voucher) error {
+func (r *voucherRepository) Update(ctx context.Context, voucher *domain.Voucher) error {
+    // Saat total_stock diubah, unit yang sudah terjual tetap dihitung
+    // sehingga remaining_stock = total baru - jumlah terjual
     query := `
         UPDATE vouchers
         SET brand_id = $1, code = $2, name = $3, description = $4, 
-            points = $5, valid_until = $6, updated_at = $7
-        WHERE id = $8`
-
-    result, err := r.db.Exec(
+            points = $5, valid_until = $6,
+            total_stock = $7::integer,
+            remaining_stock = CASE
+                WHEN $7::integer IS NULL THEN NULL
+                ELSE GREATEST($7::integer - COALESCE(total_stock - remaining_stock, 0), 0)
+            END,
+            updated_at = $8
+        WHERE id = $9
+        RETURNING remaining_stock, created_at, updated_at`
+
+    err := r.db.QueryRowContext(ctx,
         query,
         voucher.BrandID,
         voucher.Code,
@@ -150,28 +185,24 @@ func (r *voucherRepository) Update(voucher *domain.Voucher) error {
         voucher.Description,
         voucher.Points,
         voucher.ValidUntil,
+        voucher.TotalStock,
         time.Now(),
         voucher.ID,
-    )
-    if err != nil {
-        return err
-    }
-
-    rows, err := result.RowsAffected()
-    if err != nil {
-        return err
-    }
-    if rows == 0 {
-        return sql.ErrNoRows
+    ).Scan(&voucher.RemainingStock, &voucher.CreatedAt, &voucher.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return domain.ErrVoucherNotFound
     }
-    return nil
+    return translateError(err)
 }
 
-func (r *voucherRepository) Delete(id int64) error {
+func (r *voucherRepository) Delete(ctx context.Context, id int64) error {
     query := `DELETE FROM vouchers WHERE id = $1`
-    result, err := r.db.Exec(query, id)
+    result, err := r.db.ExecContext(ctx, query, id)
+    if isForeignKeyViolation(err) {
+        return domain.ErrVoucherRedeemed
+    }
     if err != nil {
-        return err
+        return translateError(err)
     }
 
     rows, err := result.RowsAffected()
@@ -179,7 +210,36 @@ func (r *voucherRepository) Delete(id int64) error {
         return err
     }
     if rows == 0 {
-        return sql.ErrNoRows
+        return domain.ErrVoucherNotFound
     }
     return nil
-} 
\ No newline at end of file
+} 
+
+func (r *voucherRepository) DecrementStock(ctx context.Context, id int64) error {
+    // Conditional update mengambil row lock, jadi redemption paralel tidak
+    // bisa membuat stok negatif. remaining_stock NULL berarti tanpa kuota.
+    query := `
+        UPDATE vouchers
+        SET remaining_stock = remaining_stock - 1
+        WHERE id = $1 AND (remaining_stock IS NULL OR remaining_stock > 0)
+        RETURNING id`
+
+    var voucherID int64
+    err := r.db.QueryRowContext(ctx, query, id).Scan(&voucherID)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("%w: voucher %d", domain.ErrVoucherSoldOut, id)
+    }
+    return err
+}
+
+func (r *voucherRepository) IncrementStock(ctx context.Context, id int64) error {
+    // Voucher tanpa kuota (NULL) tidak berubah, dan stok tidak pernah
+    // melebihi total_stock
+    query := `
+        UPDATE vouchers
+        SET remaining_stock = remaining_stock + 1
+        WHERE id = $1 AND remaining_stock IS NOT NULL AND remaining_stock < total_stock`
+
+    _, err := r.db.ExecContext(ctx, query, id)
+    return err
+}
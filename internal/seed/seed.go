@@ -0,0 +1,110 @@
+// Package seed mengisi database dengan data demo: beberapa brand, voucher
+// dan customer yang sudah punya saldo poin, supaya API bisa langsung dicoba.
+package seed
+
+import (
+	"api-otto/internal/domain"
+	"context"
+	"errors"
+	"time"
+)
+
+// voucherValidity adalah masa berlaku voucher demo sejak seed dijalankan
+const voucherValidity = 365 * 24 * time.Hour
+
+// PointsReason adalah reason ledger untuk saldo awal customer demo
+const PointsReason = "seed"
+
+type brandSeed struct {
+	name               string
+	description        string
+	cancellationWindow int
+	vouchers           []voucherSeed
+}
+
+type voucherSeed struct {
+	code   string
+	name   string
+	points int
+	// stock 0 berarti voucher tanpa kuota
+	stock int
+}
+
+type customerSeed struct {
+	name   string
+	email  string
+	points int
+}
+
+var brands = []brandSeed{
+	{"Kopi Nusantara", "Kedai kopi dengan gerai di seluruh Indonesia", 60, []voucherSeed{
+		{"KOPI-25K", "Voucher Kopi Rp25.000", 250, 100},
+		{"KOPI-50K", "Voucher Kopi Rp50.000", 500, 0},
+	}},
+	{"Toko Serba Ada", "Minimarket kebutuhan sehari-hari", 24 * 60, []voucherSeed{
+		{"TOKO-100K", "Voucher Belanja Rp100.000", 1000, 50},
+	}},
+	{"Bioskop Layar Emas", "Jaringan bioskop", 0, []voucherSeed{
+		{"BIOSKOP-TIKET", "Tiket Nonton Reguler", 750, 20},
+	}},
+}
+
+var customers = []customerSeed{
+	{"Budi Santoso", "budi@example.com", 5000},
+	{"Siti Rahmawati", "siti@example.com", 1000},
+}
+
+// Run membuat data demo dalam satu database transaction dan mengembalikan
+// false tanpa mengubah apa pun jika data demo sudah pernah dibuat
+func Run(ctx context.Context, txManager domain.TxManager, now time.Time) (bool, error) {
+	err := txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		for _, b := range brands {
+			window := b.cancellationWindow
+			brand := &domain.Brand{Name: b.name, Description: b.description, CancellationWindowMinutes: &window}
+			if err := uow.Brands().Create(ctx, brand); err != nil {
+				return err
+			}
+			for _, v := range b.vouchers {
+				voucher := &domain.Voucher{
+					BrandID:    brand.ID,
+					Code:       v.code,
+					Name:       v.name,
+					Points:     v.points,
+					ValidUntil: now.Add(voucherValidity),
+				}
+				if v.stock > 0 {
+					stock := v.stock
+					voucher.TotalStock = &stock
+				}
+				if err := uow.Vouchers().Create(ctx, voucher); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, c := range customers {
+			customer := &domain.Customer{Name: c.name, Email: c.email}
+			if err := uow.Customers().Create(ctx, customer); err != nil {
+				return err
+			}
+			entry := &domain.PointsLedgerEntry{
+				CustomerID: customer.ID,
+				EntryType:  domain.PointsEntryCredit,
+				Points:     c.points,
+				Reason:     PointsReason,
+			}
+			if err := uow.Customers().CreateLedgerEntry(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// Kode voucher demo unik, jadi konflik berarti seed sudah pernah jalan
+	if errors.Is(err, domain.ErrVoucherCodeExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
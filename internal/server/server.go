@@ -0,0 +1,177 @@
+// Package server mengatur siklus hidup proses: menjalankan http.Server dan
+// worker background, lalu saat context selesai (SIGINT/SIGTERM) menunggu
+// request yang sedang berjalan, menghentikan worker dan menutup resource.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Worker adalah proses background yang harus berhenti saat ctx selesai
+type Worker func(ctx context.Context)
+
+type worker struct {
+	name string
+	run  Worker
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+type Server struct {
+	http            *http.Server
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	drainHooks      []func()
+	workers         []worker
+	closers         []closer
+}
+
+// Options berisi timeout http.Server dan batas waktu shutdown. DrainDelay
+// adalah jeda antara shutdown dimulai dan listener ditutup, supaya load
+// balancer sempat melihat readiness gagal dan berhenti mengirim request.
+type Options struct {
+	Addr            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration
+}
+
+func New(opts Options, handler http.Handler) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:         opts.Addr,
+			Handler:      handler,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+			IdleTimeout:  opts.IdleTimeout,
+		},
+		shutdownTimeout: opts.ShutdownTimeout,
+		drainDelay:      opts.DrainDelay,
+	}
+}
+
+// OnDrain mendaftarkan fungsi yang dipanggil begitu shutdown dimulai,
+// sebelum server berhenti menerima koneksi baru
+func (s *Server) OnDrain(hook func()) {
+	s.drainHooks = append(s.drainHooks, hook)
+}
+
+// AddWorker mendaftarkan worker yang dijalankan bersama server
+func (s *Server) AddWorker(name string, run Worker) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// OnShutdown mendaftarkan resource yang ditutup setelah semua request dan
+// worker selesai, dengan urutan terbalik dari pendaftarannya
+func (s *Server) OnShutdown(name string, close func() error) {
+	s.closers = append(s.closers, closer{name: name, close: close})
+}
+
+// Run membuka listener di alamat server lalu memanggil Serve
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		s.closeResources()
+		return fmt.Errorf("listen on %s: %w", s.http.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve melayani request dari listener sampai ctx selesai atau server
+// gagal, lalu mematikan semuanya dengan rapi. Error dikembalikan jika
+// server gagal atau request tidak selesai sebelum batas waktu shutdown.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, w := range s.workers {
+		wg.Add(1)
+		go func(w worker) {
+			defer wg.Done()
+			w.run(workerCtx)
+			slog.Info("worker stopped", "worker", w.name)
+		}(w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", listener.Addr().String())
+		serveErr <- s.http.Serve(listener)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		errs = append(errs, fmt.Errorf("serve: %w", err))
+	case <-ctx.Done():
+		if err := s.drain(serveErr); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		slog.Info("shutting down, waiting for in-flight requests", "timeout", s.shutdownTimeout.String())
+		if err := s.shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	stopWorkers()
+	wg.Wait()
+
+	errs = append(errs, s.closeResources())
+	return errors.Join(errs...)
+}
+
+// drain menjalankan hook OnDrain lalu tetap melayani request selama
+// drainDelay. Error dikembalikan jika server gagal selama masa tersebut.
+func (s *Server) drain(serveErr <-chan error) error {
+	for _, hook := range s.drainHooks {
+		hook()
+	}
+	if s.drainDelay <= 0 {
+		return nil
+	}
+
+	slog.Info("draining, still serving requests", "delay", s.drainDelay.String())
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serve: %w", err)
+	case <-time.After(s.drainDelay):
+		return nil
+	}
+}
+
+// shutdown berhenti menerima koneksi baru dan menunggu request yang sedang
+// berjalan. Koneksi yang tersisa setelah batas waktu ditutup paksa.
+func (s *Server) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.http.Shutdown(ctx); err != nil {
+		s.http.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) closeResources() error {
+	var errs []error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		c := s.closers[i]
+		if err := c.close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", c.name, err))
+		}
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/logging"
+	"context"
+)
+
+type auditService struct {
+    repository domain.AuditRepository
+}
+
+func NewAuditService(repository domain.AuditRepository) domain.AuditService {
+    return &auditService{
+        repository: repository,
+    }
+}
+
+func (s *auditService) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, string, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, "", err
+    }
+    return s.repository.List(ctx, filter)
+}
+
+// audit mencatat perubahan lewat unit of work yang sama dengan perubahannya,
+// sehingga entry ikut di-rollback jika perubahan gagal
+func audit(ctx context.Context, uow domain.UnitOfWork, entityType domain.AuditEntityType, entityID int64, action domain.AuditAction, before, after interface{}) error {
+    entry := &domain.AuditEntry{
+        Actor:      auditActor(ctx),
+        RequestID:  logging.RequestID(ctx),
+        EntityType: entityType,
+        EntityID:   entityID,
+        Action:     action,
+    }
+    if err := entry.SetDiff(before, after); err != nil {
+        return err
+    }
+    return uow.Audit().Create(ctx, entry)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"api-otto/internal/domain"
+	"context"
+)
+
+// Permission per route sudah dicek di router, fungsi di sini membatasi data
+// yang boleh diakses principal. Tanpa principal, yaitu saat autentikasi
+// dimatikan atau service dipanggil dari dalam aplikasi, semua data boleh
+// diakses.
+
+// brandScope mengembalikan brand principal jika principal hanya boleh
+// mengakses satu brand
+func brandScope(ctx context.Context) (int64, bool) {
+    principal, ok := domain.PrincipalFromContext(ctx)
+    if !ok || principal.BrandID == 0 {
+        return 0, false
+    }
+    return principal.BrandID, true
+}
+
+// authorizeBrand menolak principal brand-scoped yang mengakses brand lain
+func authorizeBrand(ctx context.Context, brandID int64) error {
+    if scope, ok := brandScope(ctx); ok && scope != brandID {
+        return domain.ErrBrandAccessDenied
+    }
+    return nil
+}
+
+// authorizeCustomer menolak customer yang mengakses data customer lain
+func authorizeCustomer(ctx context.Context, customerID int64) error {
+    principal, ok := domain.PrincipalFromContext(ctx)
+    if ok && principal.Type == domain.PrincipalCustomer && principal.ID != customerID {
+        return domain.ErrCustomerAccessDenied
+    }
+    return nil
+}
+
+// authorizeTransaction mengizinkan customer pemilik transaksi dan principal
+// brand-scoped yang voucher brand-nya ditukar di transaksi tersebut.
+// transaction.Items harus sudah terisi. Sebelum dikembalikan ke principal
+// brand-scoped, transaksi tetap harus difilter dengan scopeTransaction.
+func authorizeTransaction(ctx context.Context, transaction *domain.Transaction) error {
+    if err := authorizeCustomer(ctx, transaction.CustomerID); err != nil {
+        return err
+    }
+    scope, ok := brandScope(ctx)
+    if !ok {
+        return nil
+    }
+    for _, item := range transaction.Items {
+        if item.Voucher != nil && item.Voucher.BrandID == scope {
+            return nil
+        }
+    }
+    return domain.ErrBrandAccessDenied
+}
+
+// scopeTransaction membuang item dan refund milik brand lain dari transaksi
+// yang dilihat principal brand-scoped
+func scopeTransaction(ctx context.Context, transaction *domain.Transaction) {
+    scope, ok := brandScope(ctx)
+    if !ok {
+        return
+    }
+
+    items := make([]domain.TransactionItem, 0, len(transaction.Items))
+    visible := make(map[int64]bool, len(transaction.Items))
+    for _, item := range transaction.Items {
+        if item.Voucher != nil && item.Voucher.BrandID == scope {
+            items = append(items, item)
+            visible[item.ID] = true
+        }
+    }
+    transaction.Items = items
+
+    refunds := make([]domain.Refund, 0, len(transaction.Refunds))
+    for _, refund := range transaction.Refunds {
+        if visible[refund.TransactionItemID] {
+            refunds = append(refunds, refund)
+        }
+    }
+    transaction.Refunds = refunds
+}
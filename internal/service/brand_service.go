@@ -2,34 +2,68 @@ package service
 
 import (
 	"api-otto/internal/domain"
+	"context"
 )
 
 type brandService struct {
+	txManager  domain.TxManager
 	repository domain.BrandRepository
 }
 
-func NewBrandService(repository domain.BrandRepository) domain.BrandService {
+// NewBrandService membuat service brand. Setiap perubahan dicatat ke audit
+// log dalam database transaction yang sama.
+func NewBrandService(txManager domain.TxManager, repository domain.BrandRepository) domain.BrandService {
 	return &brandService{
+		txManager:  txManager,
 		repository: repository,
 	}
 }
 
-func (s *brandService) Create(brand *domain.Brand) error {
-	return s.repository.Create(brand)
+func (s *brandService) Create(ctx context.Context, brand *domain.Brand) error {
+	return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		if err := uow.Brands().Create(ctx, brand); err != nil {
+			return err
+		}
+		return audit(ctx, uow, domain.AuditEntityBrand, brand.ID, domain.AuditActionCreate, nil, brand)
+	})
 }
 
-func (s *brandService) GetByID(id int64) (*domain.Brand, error) {
-	return s.repository.GetByID(id)
+func (s *brandService) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+	return s.repository.GetByID(ctx, id)
 }
 
-func (s *brandService) Update(brand *domain.Brand) error {
-	return s.repository.Update(brand)
+func (s *brandService) Update(ctx context.Context, brand *domain.Brand) error {
+	return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		existing, err := uow.Brands().GetByID(ctx, brand.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return domain.ErrBrandNotFound
+		}
+		if err := uow.Brands().Update(ctx, brand); err != nil {
+			return err
+		}
+		return audit(ctx, uow, domain.AuditEntityBrand, brand.ID, domain.AuditActionUpdate, existing, brand)
+	})
 }
 
-func (s *brandService) Delete(id int64) error {
-	return s.repository.Delete(id)
+func (s *brandService) Delete(ctx context.Context, id int64) error {
+	return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		existing, err := uow.Brands().GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return domain.ErrBrandNotFound
+		}
+		if err := uow.Brands().Delete(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, uow, domain.AuditEntityBrand, id, domain.AuditActionDelete, existing, nil)
+	})
 }
 
-func (s *brandService) List() ([]domain.Brand, error) {
-	return s.repository.List()
-} 
\ No newline at end of file
+func (s *brandService) List(ctx context.Context, filter domain.BrandFilter) ([]domain.Brand, string, error) {
+	return s.repository.List(ctx, filter)
+}
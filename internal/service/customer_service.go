@@ -0,0 +1,65 @@
+package service
+
+import (
+	"api-otto/internal/domain"
+	"context"
+)
+
+type customerService struct {
+	repository domain.CustomerRepository
+}
+
+func NewCustomerService(repository domain.CustomerRepository) domain.CustomerService {
+	return &customerService{
+		repository: repository,
+	}
+}
+
+func (s *customerService) Create(ctx context.Context, customer *domain.Customer) error {
+	return s.repository.Create(ctx, customer)
+}
+
+func (s *customerService) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	if err := authorizeCustomer(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repository.GetByID(ctx, id)
+}
+
+func (s *customerService) CreditPoints(ctx context.Context, entry *domain.PointsLedgerEntry) error {
+	if err := authorizeCustomer(ctx, entry.CustomerID); err != nil {
+		return err
+	}
+	if entry.Points <= 0 {
+		return domain.ErrPointsNotPositive
+	}
+
+	// Validasi customer exists
+	customer, err := s.repository.GetByID(ctx, entry.CustomerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return domain.ErrCustomerNotFound
+	}
+
+	// Debit hanya boleh lewat redemption, endpoint ini khusus top up
+	entry.EntryType = domain.PointsEntryCredit
+	entry.TransactionID = nil
+	return s.repository.CreateLedgerEntry(ctx, entry)
+}
+
+func (s *customerService) GetLedger(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+	if err := authorizeCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	customer, err := s.repository.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, domain.ErrCustomerNotFound
+	}
+
+	return s.repository.GetLedgerEntries(ctx, customerID)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "api-otto/internal/service"
+
+// startSpan mengambil tracer dari provider global setiap kali dipanggil
+// supaya provider yang dipasang belakangan, termasuk di test, selalu dipakai
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+    return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func voucherIDs(items []domain.TransactionItem) attribute.KeyValue {
+    ids := make([]int64, 0, len(items))
+    for _, item := range items {
+        ids = append(ids, item.VoucherID)
+    }
+    return attribute.Int64Slice("voucher.ids", ids)
+}
+
+// tracedBrandService membuat child span untuk setiap pemanggilan service
+type tracedBrandService struct {
+    next domain.BrandService
+}
+
+// NewTracedBrandService membungkus service dengan span OpenTelemetry
+func NewTracedBrandService(next domain.BrandService) domain.BrandService {
+    return &tracedBrandService{next: next}
+}
+
+func (s *tracedBrandService) Create(ctx context.Context, brand *domain.Brand) error {
+    ctx, span := startSpan(ctx, "BrandService.Create")
+    err := s.next.Create(ctx, brand)
+    span.SetAttributes(attribute.Int64("brand.id", brand.ID))
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedBrandService) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+    ctx, span := startSpan(ctx, "BrandService.GetByID", attribute.Int64("brand.id", id))
+    brand, err := s.next.GetByID(ctx, id)
+    tracing.End(span, err)
+    return brand, err
+}
+
+func (s *tracedBrandService) Update(ctx context.Context, brand *domain.Brand) error {
+    ctx, span := startSpan(ctx, "BrandService.Update", attribute.Int64("brand.id", brand.ID))
+    err := s.next.Update(ctx, brand)
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedBrandService) Delete(ctx context.Context, id int64) error {
+    ctx, span := startSpan(ctx, "BrandService.Delete", attribute.Int64("brand.id", id))
+    err := s.next.Delete(ctx, id)
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedBrandService) List(ctx context.Context, filter domain.BrandFilter) ([]domain.Brand, string, error) {
+    ctx, span := startSpan(ctx, "BrandService.List")
+    brands, next, err := s.next.List(ctx, filter)
+    tracing.End(span, err)
+    return brands, next, err
+}
+
+type tracedVoucherService struct {
+    next domain.VoucherService
+}
+
+// NewTracedVoucherService membungkus service dengan span OpenTelemetry
+func NewTracedVoucherService(next domain.VoucherService) domain.VoucherService {
+    return &tracedVoucherService{next: next}
+}
+
+func (s *tracedVoucherService) Create(ctx context.Context, voucher *domain.Voucher) error {
+    ctx, span := startSpan(ctx, "VoucherService.Create", attribute.Int64("brand.id", voucher.BrandID))
+    err := s.next.Create(ctx, voucher)
+    span.SetAttributes(attribute.Int64("voucher.id", voucher.ID))
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedVoucherService) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
+    ctx, span := startSpan(ctx, "VoucherService.GetByID", attribute.Int64("voucher.id", id))
+    voucher, err := s.next.GetByID(ctx, id)
+    tracing.End(span, err)
+    return voucher, err
+}
+
+func (s *tracedVoucherService) GetForUpdate(ctx context.Context, id int64) (*domain.Voucher, error) {
+    ctx, span := startSpan(ctx, "VoucherService.GetForUpdate", attribute.Int64("voucher.id", id))
+    voucher, err := s.next.GetForUpdate(ctx, id)
+    tracing.End(span, err)
+    return voucher, err
+}
+
+func (s *tracedVoucherService) GetByBrandID(ctx context.Context, brandID int64, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+    ctx, span := startSpan(ctx, "VoucherService.GetByBrandID", attribute.Int64("brand.id", brandID))
+    vouchers, next, err := s.next.GetByBrandID(ctx, brandID, filter)
+    tracing.End(span, err)
+    return vouchers, next, err
+}
+
+func (s *tracedVoucherService) Update(ctx context.Context, voucher *domain.Voucher) error {
+    ctx, span := startSpan(ctx, "VoucherService.Update", attribute.Int64("voucher.id", voucher.ID))
+    err := s.next.Update(ctx, voucher)
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedVoucherService) Delete(ctx context.Context, id int64) error {
+    ctx, span := startSpan(ctx, "VoucherService.Delete", attribute.Int64("voucher.id", id))
+    err := s.next.Delete(ctx, id)
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedVoucherService) List(ctx context.Context, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+    ctx, span := startSpan(ctx, "VoucherService.List")
+    vouchers, next, err := s.next.List(ctx, filter)
+    tracing.End(span, err)
+    return vouchers, next, err
+}
+
+type tracedCustomerService struct {
+    next domain.CustomerService
+}
+
+// NewTracedCustomerService membungkus service dengan span OpenTelemetry
+func NewTracedCustomerService(next domain.CustomerService) domain.CustomerService {
+    return &tracedCustomerService{next: next}
+}
+
+func (s *tracedCustomerService) Create(ctx context.Context, customer *domain.Customer) error {
+    ctx, span := startSpan(ctx, "CustomerService.Create")
+    err := s.next.Create(ctx, customer)
+    span.SetAttributes(attribute.Int64("customer.id", customer.ID))
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedCustomerService) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+    ctx, span := startSpan(ctx, "CustomerService.GetByID", attribute.Int64("customer.id", id))
+    customer, err := s.next.GetByID(ctx, id)
+    tracing.End(span, err)
+    return customer, err
+}
+
+func (s *tracedCustomerService) CreditPoints(ctx context.Context, entry *domain.PointsLedgerEntry) error {
+    ctx, span := startSpan(ctx, "CustomerService.CreditPoints",
+        attribute.Int64("customer.id", entry.CustomerID),
+        attribute.Int("points", entry.Points),
+    )
+    err := s.next.CreditPoints(ctx, entry)
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedCustomerService) GetLedger(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+    ctx, span := startSpan(ctx, "CustomerService.GetLedger", attribute.Int64("customer.id", customerID))
+    entries, err := s.next.GetLedger(ctx, customerID)
+    tracing.End(span, err)
+    return entries, err
+}
+
+type tracedTransactionService struct {
+    next domain.TransactionService
+}
+
+// NewTracedTransactionService membungkus service dengan span OpenTelemetry.
+// Span redemption mencatat customer, voucher dan ID transaksi yang dibuat,
+// termasuk transaksi failed.
+func NewTracedTransactionService(next domain.TransactionService) domain.TransactionService {
+    return &tracedTransactionService{next: next}
+}
+
+func (s *tracedTransactionService) CreateRedemption(ctx context.Context, transaction *domain.Transaction) error {
+    ctx, span := startSpan(ctx, "TransactionService.CreateRedemption",
+        attribute.Int64("customer.id", transaction.CustomerID),
+        voucherIDs(transaction.Items),
+    )
+    err := s.next.CreateRedemption(ctx, transaction)
+    span.SetAttributes(
+        attribute.Int64("transaction.id", transaction.ID),
+        attribute.String("transaction.status", string(transaction.Status)),
+        attribute.Int("transaction.total_points", transaction.TotalPoints),
+    )
+    tracing.End(span, err)
+    return err
+}
+
+func (s *tracedTransactionService) GetTransactionByID(ctx context.Context, id int64) (*domain.Transaction, error) {
+    ctx, span := startSpan(ctx, "TransactionService.GetTransactionByID", attribute.Int64("transaction.id", id))
+    transaction, err := s.next.GetTransactionByID(ctx, id)
+    tracing.End(span, err)
+    return transaction, err
+}
+
+func (s *tracedTransactionService) GetCustomerTransactions(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+    ctx, span := startSpan(ctx, "TransactionService.GetCustomerTransactions", attribute.Int64("customer.id", customerID))
+    transactions, next, err := s.next.GetCustomerTransactions(ctx, customerID, filter)
+    tracing.End(span, err)
+    return transactions, next, err
+}
+
+func (s *tracedTransactionService) CancelRedemption(ctx context.Context, id int64, request domain.RefundRequest) (*domain.Transaction, error) {
+    ctx, span := startSpan(ctx, "TransactionService.CancelRedemption", attribute.Int64("transaction.id", id))
+    transaction, err := s.next.CancelRedemption(ctx, id, request)
+    if transaction != nil {
+        span.SetAttributes(attribute.Int64("customer.id", transaction.CustomerID), voucherIDs(transaction.Items))
+    }
+    tracing.End(span, err)
+    return transaction, err
+}
+
+func (s *tracedTransactionService) RefundItem(ctx context.Context, id int64, itemID int64, request domain.RefundRequest) (*domain.Transaction, error) {
+    ctx, span := startSpan(ctx, "TransactionService.RefundItem",
+        attribute.Int64("transaction.id", id),
+        attribute.Int64("transaction_item.id", itemID),
+    )
+    transaction, err := s.next.RefundItem(ctx, id, itemID, request)
+    if transaction != nil {
+        span.SetAttributes(attribute.Int64("customer.id", transaction.CustomerID))
+    }
+    tracing.End(span, err)
+    return transaction, err
+}
+
+type tracedAuditService struct {
+    next domain.AuditService
+}
+
+// NewTracedAuditService membungkus service dengan span OpenTelemetry
+func NewTracedAuditService(next domain.AuditService) domain.AuditService {
+    return &tracedAuditService{next: next}
+}
+
+func (s *tracedAuditService) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, string, error) {
+    ctx, span := startSpan(ctx, "AuditService.List", attribute.String("audit.entity_type", string(filter.EntityType)))
+    entries, next, err := s.next.List(ctx, filter)
+    tracing.End(span, err)
+    return entries, next, err
+}
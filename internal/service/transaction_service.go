@@ -2,45 +2,108 @@ package service
 
 import (
 	"api-otto/internal/domain"
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
 	"time"
 )
 
+// recordFailureTimeout membatasi waktu pencatatan transaksi yang gagal
+const recordFailureTimeout = 5 * time.Second
+
 type transactionService struct {
-    repository     domain.TransactionRepository
-    voucherRepo    domain.VoucherRepository
+    txManager  domain.TxManager
+    repository domain.TransactionRepository
+    recorder   domain.RedemptionRecorder
 }
 
+// NewTransactionService membuat service transaksi. recorder boleh nil jika
+// hasil redemption tidak perlu dicatat.
 func NewTransactionService(
+    txManager domain.TxManager,
     repository domain.TransactionRepository,
-    voucherRepo domain.VoucherRepository,
+    recorder domain.RedemptionRecorder,
 ) domain.TransactionService {
     return &transactionService{
-        repository:     repository,
-        voucherRepo:    voucherRepo,
+        txManager:  txManager,
+        repository: repository,
+        recorder:   recorder,
     }
 }
 
-func (s *transactionService) CreateRedemption(transaction *domain.Transaction) error {
+func (s *transactionService) CreateRedemption(ctx context.Context, transaction *domain.Transaction) error {
+    if err := authorizeCustomer(ctx, transaction.CustomerID); err != nil {
+        return err
+    }
+
     // Validasi items tidak kosong
     if len(transaction.Items) == 0 {
-        return errors.New("transaction must have at least one item")
+        return domain.ErrTransactionItemsRequired
+    }
+
+    // Lookup voucher, validasi, stok, poin, insert dan status dijalankan
+    // dalam satu unit of work sehingga tidak ada transaksi setengah jadi
+    brandPoints := make(map[int64]int)
+    err := s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        return s.redeem(ctx, uow, transaction, brandPoints)
+    })
+    if err != nil {
+        s.recordFailure(ctx, transaction, err)
+        s.record(domain.TransactionStatusFailed, brandPoints, err)
+        slog.InfoContext(ctx, "redemption failed",
+            "transaction_id", transaction.ID, "customer_id", transaction.CustomerID, "error", err)
+        return err
+    }
+    s.record(domain.TransactionStatusCompleted, brandPoints, nil)
+    slog.InfoContext(ctx, "redemption completed",
+        "transaction_id", transaction.ID, "customer_id", transaction.CustomerID, "total_points", transaction.TotalPoints)
+    return nil
+}
+
+func (s *transactionService) record(status domain.TransactionStatus, brandPoints map[int64]int, cause error) {
+    if s.recorder != nil {
+        s.recorder.RecordRedemption(status, brandPoints, cause)
+    }
+}
+
+// redeem mengisi brandPoints dengan poin setiap voucher per brand supaya
+// hasilnya bisa dicatat walaupun redemption gagal
+func (s *transactionService) redeem(ctx context.Context, uow domain.UnitOfWork, transaction *domain.Transaction, brandPoints map[int64]int) error {
+    // Kunci customer supaya cek saldo dan debit tidak balapan dengan
+    // redemption lain milik customer yang sama
+    customer, err := uow.Customers().GetByIDForUpdate(ctx, transaction.CustomerID)
+    if err != nil {
+        return err
+    }
+    if customer == nil {
+        return domain.ErrCustomerNotFound
     }
 
+    // Total points selalu dihitung ulang dari voucher, abaikan nilai dari client
+    transaction.TotalPoints = 0
+
     // Hitung total points dan validasi voucher
     var totalPoints int
     for i, item := range transaction.Items {
-        voucher, err := s.voucherRepo.GetByID(item.VoucherID)
+        voucher, err := uow.Vouchers().GetByID(ctx, item.VoucherID)
         if err != nil {
             return err
         }
         if voucher == nil {
-            return errors.New("voucher not found")
+            return fmt.Errorf("%w: voucher %d", domain.ErrVoucherNotFound, item.VoucherID)
         }
+        brandPoints[voucher.BrandID] += voucher.Points
 
         // Validasi voucher masih berlaku
         if !voucher.ValidUntil.IsZero() && voucher.ValidUntil.Before(time.Now()) {
-            return errors.New("voucher has expired")
+            return fmt.Errorf("%w: voucher %d", domain.ErrVoucherExpired, voucher.ID)
+        }
+
+        // Cek awal stok voucher, pengurangan sebenarnya lewat DecrementStock
+        if voucher.RemainingStock != nil && *voucher.RemainingStock <= 0 {
+            return fmt.Errorf("%w: voucher %d", domain.ErrVoucherSoldOut, voucher.ID)
         }
 
         // Set points yang digunakan sesuai dengan voucher
@@ -50,52 +113,348 @@ func (s *transactionService) CreateRedemption(transaction *domain.Transaction) e
 
     // Set total points transaksi
     transaction.TotalPoints = totalPoints
+
+    if customer.PointsBalance < totalPoints {
+        return fmt.Errorf("%w: balance %d, required %d", domain.ErrInsufficientPoints, customer.PointsBalance, totalPoints)
+    }
+
+    // Kurangi stok voucher berurutan berdasarkan ID supaya urutan row lock
+    // konsisten dan tidak terjadi deadlock antar redemption
+    voucherIDs := make([]int64, 0, len(transaction.Items))
+    for _, item := range transaction.Items {
+        voucherIDs = append(voucherIDs, item.VoucherID)
+    }
+    sort.Slice(voucherIDs, func(i, j int) bool { return voucherIDs[i] < voucherIDs[j] })
+    for _, voucherID := range voucherIDs {
+        if err := uow.Vouchers().DecrementStock(ctx, voucherID); err != nil {
+            return err
+        }
+    }
+
+    // Buat transaksi pending, lalu completed setelah poin didebit. Semuanya
+    // di-commit bersama perubahan stok dan poin.
     transaction.Status = domain.TransactionStatusPending
+    transaction.FailureReason = ""
+    if err := uow.Transactions().Create(ctx, transaction, auditActor(ctx)); err != nil {
+        return err
+    }
+    if err := audit(ctx, uow, domain.AuditEntityTransaction, transaction.ID, domain.AuditActionCreate, nil, transaction); err != nil {
+        return err
+    }
 
-    // Buat transaksi
-    err := s.repository.Create(transaction)
+    // Debit poin customer
+    err = uow.Customers().CreateLedgerEntry(ctx, &domain.PointsLedgerEntry{
+        CustomerID:    transaction.CustomerID,
+        EntryType:     domain.PointsEntryDebit,
+        Points:        totalPoints,
+        Reason:        domain.PointsReasonRedemption,
+        TransactionID: &transaction.ID,
+    })
     if err != nil {
         return err
     }
 
-    // Update status transaksi menjadi completed
-    transaction.Status = domain.TransactionStatusCompleted
-    return s.repository.Update(transaction)
+    return s.updateStatus(ctx, uow, transaction, domain.TransactionStatusCompleted, domain.ActorSystem, "")
+}
+
+// auditStatus adalah data audit log untuk perubahan status transaksi
+type auditStatus struct {
+    Status domain.TransactionStatus `json:"status"`
+    Reason string                   `json:"reason,omitempty"`
 }
 
-func (s *transactionService) GetTransactionByID(id int64) (*domain.Transaction, error) {
-    transaction, err := s.repository.GetByID(id)
+// auditItemRefund adalah data audit log untuk item yang direfund
+type auditItemRefund struct {
+    ItemID    int64                        `json:"item_id"`
+    VoucherID int64                        `json:"voucher_id"`
+    Status    domain.TransactionItemStatus `json:"status"`
+    Points    int                          `json:"points"`
+    Reason    string                       `json:"reason"`
+}
+
+// updateStatus memindahkan status transaksi dan mencatatnya ke audit log
+// dalam unit of work yang sama
+func (s *transactionService) updateStatus(ctx context.Context, uow domain.UnitOfWork, transaction *domain.Transaction, to domain.TransactionStatus, actor string, reason string) error {
+    from := transaction.Status
+    if err := uow.Transactions().UpdateStatus(ctx, transaction, to, actor, reason); err != nil {
+        return err
+    }
+    return audit(ctx, uow, domain.AuditEntityTransaction, transaction.ID, domain.AuditActionStatusChange,
+        auditStatus{Status: from}, auditStatus{Status: to, Reason: reason})
+}
+
+// recordFailure mencatat redemption yang gagal sebagai transaksi berstatus
+// failed beserta alasannya. Item tidak disimpan karena vouchernya bisa saja
+// tidak valid. Pencatatan tetap dilakukan walaupun request sudah dibatalkan
+// atau melewati deadline, dengan batas waktunya sendiri.
+func (s *transactionService) recordFailure(ctx context.Context, transaction *domain.Transaction, cause error) {
+    // Tanpa customer yang valid, transaksi gagal tidak bisa disimpan
+    if errors.Is(cause, domain.ErrCustomerNotFound) {
+        return
+    }
+
+    ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordFailureTimeout)
+    defer cancel()
+
+    failed := &domain.Transaction{
+        CustomerID:    transaction.CustomerID,
+        TotalPoints:   transaction.TotalPoints,
+        Status:        domain.TransactionStatusPending,
+        FailureReason: cause.Error(),
+    }
+    err := s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        if err := uow.Transactions().Create(ctx, failed, domain.ActorSystem); err != nil {
+            return err
+        }
+        if err := audit(ctx, uow, domain.AuditEntityTransaction, failed.ID, domain.AuditActionCreate, nil, failed); err != nil {
+            return err
+        }
+        return s.updateStatus(ctx, uow, failed, domain.TransactionStatusFailed, domain.ActorSystem, cause.Error())
+    })
+    if err != nil {
+        slog.ErrorContext(ctx, "failed to record failed transaction", "customer_id", transaction.CustomerID, "error", err)
+        return
+    }
+
+    transaction.ID = failed.ID
+    transaction.Status = failed.Status
+    transaction.FailureReason = failed.FailureReason
+}
+
+func (s *transactionService) GetTransactionByID(ctx context.Context, id int64) (*domain.Transaction, error) {
+    transaction, err := s.repository.GetByID(ctx, id)
     if err != nil {
         return nil, err
     }
     if transaction == nil {
-        return nil, errors.New("transaction not found")
+        return nil, domain.ErrTransactionNotFound
+    }
+
+    // Items sudah diisi oleh repository
+
+    if err := authorizeTransaction(ctx, transaction); err != nil {
+        return nil, err
+    }
+
+    // Ambil riwayat refund
+    refunds, err := s.repository.GetRefunds(ctx, transaction.ID)
+    if err != nil {
+        return nil, err
     }
+    transaction.Refunds = refunds
 
-    // Ambil detail items
-    items, err := s.repository.GetTransactionItems(transaction.ID)
+    // Ambil riwayat status
+    history, err := s.repository.GetStatusHistory(ctx, transaction.ID)
     if err != nil {
         return nil, err
     }
-    transaction.Items = items
+    transaction.History = history
 
+    scopeTransaction(ctx, transaction)
     return transaction, nil
 }
 
-func (s *transactionService) GetCustomerTransactions(customerID int64) ([]domain.Transaction, error) {
-    transactions, err := s.repository.GetByCustomerID(customerID)
+func (s *transactionService) GetCustomerTransactions(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+    if err := authorizeCustomer(ctx, customerID); err != nil {
+        return nil, "", err
+    }
+    // Principal brand-scoped hanya melihat redemption voucher brand-nya
+    if brandID, ok := brandScope(ctx); ok {
+        filter.BrandID = &brandID
+    }
+
+    transactions, next, err := s.repository.GetByCustomerID(ctx, customerID, filter)
     if err != nil {
-        return nil, err
+        return nil, "", err
     }
 
-    // Ambil detail items untuk setiap transaksi
+    // Ambil detail items untuk setiap transaksi di halaman ini
     for i, transaction := range transactions {
-        items, err := s.repository.GetTransactionItems(transaction.ID)
+        items, err := s.repository.GetTransactionItems(ctx, transaction.ID)
         if err != nil {
-            return nil, err
+            return nil, "", err
         }
         transactions[i].Items = items
+        scopeTransaction(ctx, &transactions[i])
+    }
+
+    return transactions, next, nil
+} 
+
+func (s *transactionService) CancelRedemption(ctx context.Context, id int64, request domain.RefundRequest) (*domain.Transaction, error) {
+    err := s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        transaction, err := s.lockRefundableTransaction(ctx, uow, id)
+        if err != nil {
+            return err
+        }
+
+        // Semua item yang belum direfund harus masih dalam batas waktu brand-nya
+        var refundable []domain.TransactionItem
+        for _, item := range transaction.Items {
+            if item.Status == domain.TransactionItemStatusRefunded {
+                continue
+            }
+            if err := s.checkCancellationWindow(ctx, uow, transaction, item); err != nil {
+                return err
+            }
+            refundable = append(refundable, item)
+        }
+
+        var points int
+        for _, item := range refundable {
+            if err := s.refundItem(ctx, uow, transaction, item, request); err != nil {
+                return err
+            }
+            points += item.PointsUsed
+        }
+
+        if points > 0 {
+            if err := uow.Customers().CreateLedgerEntry(ctx, &domain.PointsLedgerEntry{
+                CustomerID:    transaction.CustomerID,
+                EntryType:     domain.PointsEntryCredit,
+                Points:        points,
+                Reason:        domain.PointsReasonCancellation,
+                TransactionID: &transaction.ID,
+            }); err != nil {
+                return err
+            }
+        }
+
+        return s.updateStatus(ctx, uow, transaction, domain.TransactionStatusCancelled, auditActor(ctx), request.Reason)
+    })
+    if err != nil {
+        return nil, err
     }
+    return s.GetTransactionByID(ctx, id)
+}
+
+func (s *transactionService) RefundItem(ctx context.Context, id int64, itemID int64, request domain.RefundRequest) (*domain.Transaction, error) {
+    err := s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        transaction, err := s.lockRefundableTransaction(ctx, uow, id)
+        if err != nil {
+            return err
+        }
 
-    return transactions, nil
-} 
\ No newline at end of file
+        var target *domain.TransactionItem
+        remaining := 0
+        for i, item := range transaction.Items {
+            if item.ID == itemID {
+                target = &transaction.Items[i]
+                continue
+            }
+            if item.Status != domain.TransactionItemStatusRefunded {
+                remaining++
+            }
+        }
+        if target == nil {
+            return fmt.Errorf("%w: item %d", domain.ErrTransactionItemNotFound, itemID)
+        }
+        if target.Status == domain.TransactionItemStatusRefunded {
+            return fmt.Errorf("%w: item %d", domain.ErrTransactionItemRefunded, itemID)
+        }
+        if err := s.checkCancellationWindow(ctx, uow, transaction, *target); err != nil {
+            return err
+        }
+
+        if err := s.refundItem(ctx, uow, transaction, *target, request); err != nil {
+            return err
+        }
+        if err := uow.Customers().CreateLedgerEntry(ctx, &domain.PointsLedgerEntry{
+            CustomerID:    transaction.CustomerID,
+            EntryType:     domain.PointsEntryCredit,
+            Points:        target.PointsUsed,
+            Reason:        domain.PointsReasonRefund,
+            TransactionID: &transaction.ID,
+        }); err != nil {
+            return err
+        }
+
+        // Transaksi menjadi refunded setelah item terakhir direfund
+        if remaining == 0 {
+            return s.updateStatus(ctx, uow, transaction, domain.TransactionStatusRefunded, auditActor(ctx), request.Reason)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return s.GetTransactionByID(ctx, id)
+}
+
+// lockRefundableTransaction mengunci transaksi dan memastikan statusnya
+// masih bisa dibatalkan atau direfund
+func (s *transactionService) lockRefundableTransaction(ctx context.Context, uow domain.UnitOfWork, id int64) (*domain.Transaction, error) {
+    transaction, err := uow.Transactions().GetByIDForUpdate(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if transaction == nil {
+        return nil, domain.ErrTransactionNotFound
+    }
+    if err := authorizeTransaction(ctx, transaction); err != nil {
+        return nil, err
+    }
+    if !transaction.Status.CanTransitionTo(domain.TransactionStatusCancelled) {
+        return nil, fmt.Errorf("%w: status is %s", domain.ErrTransactionNotCancellable, transaction.Status)
+    }
+    return transaction, nil
+}
+
+func (s *transactionService) checkCancellationWindow(ctx context.Context, uow domain.UnitOfWork, transaction *domain.Transaction, item domain.TransactionItem) error {
+    voucher, err := uow.Vouchers().GetByID(ctx, item.VoucherID)
+    if err != nil {
+        return err
+    }
+    if voucher == nil {
+        return fmt.Errorf("%w: voucher %d", domain.ErrVoucherNotFound, item.VoucherID)
+    }
+
+    window := voucher.Brand.CancellationWindow()
+    if time.Since(transaction.CreatedAt) > window {
+        return fmt.Errorf("%w: brand %d allows %s", domain.ErrCancellationWindowExpired, voucher.BrandID, window)
+    }
+    return nil
+}
+
+// refundItem mengembalikan stok voucher, menandai item sebagai refunded,
+// mencatat principal yang melakukan refund dan menulis audit log. Poin
+// dikreditkan oleh pemanggil.
+func (s *transactionService) refundItem(ctx context.Context, uow domain.UnitOfWork, transaction *domain.Transaction, item domain.TransactionItem, request domain.RefundRequest) error {
+    if err := uow.Vouchers().IncrementStock(ctx, item.VoucherID); err != nil {
+        return err
+    }
+
+    item.Status = domain.TransactionItemStatusRefunded
+    if err := uow.Transactions().UpdateTransactionItem(ctx, &item); err != nil {
+        return err
+    }
+
+    if err := uow.Transactions().CreateRefund(ctx, &domain.Refund{
+        TransactionID:     transaction.ID,
+        TransactionItemID: item.ID,
+        Points:            item.PointsUsed,
+        Actor:             auditActor(ctx),
+        Reason:            request.Reason,
+    }); err != nil {
+        return err
+    }
+
+    // Refund sebagian tidak mengubah status transaksi, sehingga tanpa entry
+    // ini tidak tercatat di audit log
+    return audit(ctx, uow, domain.AuditEntityTransaction, transaction.ID, domain.AuditActionItemRefund, nil, auditItemRefund{
+        ItemID:    item.ID,
+        VoucherID: item.VoucherID,
+        Status:    item.Status,
+        Points:    item.PointsUsed,
+        Reason:    request.Reason,
+    })
+}
+
+// auditActor adalah principal request yang dicatat sebagai actor audit log,
+// riwayat status dan refund, atau anonymous jika autentikasi dimatikan
+func auditActor(ctx context.Context) string {
+    if principal, ok := domain.PrincipalFromContext(ctx); ok {
+        return principal.String()
+    }
+    return domain.ActorAnonymous
+}
@@ -2,83 +2,149 @@ package service
 
 import (
 	"api-otto/internal/domain"
-	"errors"
+	"context"
 	"time"
 )
 
 type voucherService struct {
+    txManager  domain.TxManager
     repository domain.VoucherRepository
     brandRepo  domain.BrandRepository
 }
 
-func NewVoucherService(repository domain.VoucherRepository, brandRepo domain.BrandRepository) domain.VoucherService {
+// NewVoucherService membuat service voucher. Setiap perubahan dicatat ke
+// audit log dalam database transaction yang sama.
+func NewVoucherService(txManager domain.TxManager, repository domain.VoucherRepository, brandRepo domain.BrandRepository) domain.VoucherService {
     return &voucherService{
+        txManager:  txManager,
         repository: repository,
         brandRepo:  brandRepo,
     }
 }
 
-func (s *voucherService) Create(voucher *domain.Voucher) error {
-    // Validasi brand exists
-    brand, err := s.brandRepo.GetByID(voucher.BrandID)
-    if err != nil {
+func (s *voucherService) Create(ctx context.Context, voucher *domain.Voucher) error {
+    if err := authorizeBrand(ctx, voucher.BrandID); err != nil {
         return err
     }
-    if brand == nil {
-        return errors.New("brand not found")
-    }
 
-    // Validasi valid_until harus di masa depan
-    if !voucher.ValidUntil.IsZero() && voucher.ValidUntil.Before(time.Now()) {
-        return errors.New("valid_until must be in the future")
-    }
+    return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        // Validasi brand exists
+        brand, err := uow.Brands().GetByID(ctx, voucher.BrandID)
+        if err != nil {
+            return err
+        }
+        if brand == nil {
+            return domain.ErrBrandNotFound
+        }
+
+        // Validasi valid_until harus di masa depan
+        if !voucher.ValidUntil.IsZero() && voucher.ValidUntil.Before(time.Now()) {
+            return domain.ErrValidUntilPast
+        }
 
-    return s.repository.Create(voucher)
+        if err := uow.Vouchers().Create(ctx, voucher); err != nil {
+            return err
+        }
+        return audit(ctx, uow, domain.AuditEntityVoucher, voucher.ID, domain.AuditActionCreate, nil, auditVoucher(voucher))
+    })
 }
 
-func (s *voucherService) GetByID(id int64) (*domain.Voucher, error) {
-    return s.repository.GetByID(id)
+func (s *voucherService) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
+    return s.repository.GetByID(ctx, id)
 }
 
-func (s *voucherService) GetByBrandID(brandID int64) ([]domain.Voucher, error) {
-    // Validasi brand exists
-    brand, err := s.brandRepo.GetByID(brandID)
+func (s *voucherService) GetForUpdate(ctx context.Context, id int64) (*domain.Voucher, error) {
+    voucher, err := s.repository.GetByID(ctx, id)
     if err != nil {
         return nil, err
     }
-    if brand == nil {
-        return nil, errors.New("brand not found")
+    if voucher == nil {
+        return nil, domain.ErrVoucherNotFound
     }
-
-    return s.repository.GetByBrandID(brandID)
+    if err := authorizeBrand(ctx, voucher.BrandID); err != nil {
+        return nil, err
+    }
+    return voucher, nil
 }
 
-func (s *voucherService) Update(voucher *domain.Voucher) error {
+func (s *voucherService) GetByBrandID(ctx context.Context, brandID int64, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
     // Validasi brand exists
-    brand, err := s.brandRepo.GetByID(voucher.BrandID)
+    brand, err := s.brandRepo.GetByID(ctx, brandID)
     if err != nil {
-        return err
+        return nil, "", err
     }
     if brand == nil {
-        return errors.New("brand not found")
+        return nil, "", domain.ErrBrandNotFound
     }
 
-    // Validasi voucher exists
-    existing, err := s.repository.GetByID(voucher.ID)
-    if err != nil {
+    filter.BrandID = &brandID
+    return s.repository.List(ctx, filter)
+}
+
+func (s *voucherService) Update(ctx context.Context, voucher *domain.Voucher) error {
+    if err := authorizeBrand(ctx, voucher.BrandID); err != nil {
         return err
     }
-    if existing == nil {
-        return errors.New("voucher not found")
-    }
 
-    return s.repository.Update(voucher)
+    return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        // Validasi brand exists
+        brand, err := uow.Brands().GetByID(ctx, voucher.BrandID)
+        if err != nil {
+            return err
+        }
+        if brand == nil {
+            return domain.ErrBrandNotFound
+        }
+
+        // Validasi voucher exists
+        existing, err := uow.Vouchers().GetByID(ctx, voucher.ID)
+        if err != nil {
+            return err
+        }
+        if existing == nil {
+            return domain.ErrVoucherNotFound
+        }
+
+        // Voucher brand lain tidak boleh dipindahkan ke brand sendiri
+        if err := authorizeBrand(ctx, existing.BrandID); err != nil {
+            return err
+        }
+
+        if err := uow.Vouchers().Update(ctx, voucher); err != nil {
+            return err
+        }
+        return audit(ctx, uow, domain.AuditEntityVoucher, voucher.ID, domain.AuditActionUpdate, auditVoucher(existing), auditVoucher(voucher))
+    })
+}
+
+func (s *voucherService) Delete(ctx context.Context, id int64) error {
+    return s.txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+        existing, err := uow.Vouchers().GetByID(ctx, id)
+        if err != nil {
+            return err
+        }
+        if existing == nil {
+            return domain.ErrVoucherNotFound
+        }
+        if err := authorizeBrand(ctx, existing.BrandID); err != nil {
+            return err
+        }
+
+        if err := uow.Vouchers().Delete(ctx, id); err != nil {
+            return err
+        }
+        return audit(ctx, uow, domain.AuditEntityVoucher, id, domain.AuditActionDelete, auditVoucher(existing), nil)
+    })
 }
 
-func (s *voucherService) Delete(id int64) error {
-    return s.repository.Delete(id)
+// auditVoucher membuang data brand hasil join supaya hanya kolom voucher
+// yang dibandingkan
+func auditVoucher(voucher *domain.Voucher) *domain.Voucher {
+    copied := *voucher
+    copied.Brand = nil
+    return &copied
 }
 
-func (s *voucherService) List() ([]domain.Voucher, error) {
-    return s.repository.List()
+func (s *voucherService) List(ctx context.Context, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+    return s.repository.List(ctx, filter)
 } 
\ No newline at end of file
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"api-otto/internal/i18n"
+	"fmt"
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// messages berisi pesan untuk aturan yang dipakai API. {0} adalah nama field
+// lengkap, misalnya "items[1].voucher_id", dan {1} adalah parameter aturan.
+// Aturan lain memakai terjemahan bawaan validator.
+var messages = map[string]map[string]string{
+	i18n.English: {
+		"required":        "{0} is required",
+		"min":             "{0} must be at least {1} characters",
+		"min-items":       "{0} must contain at least {1} item(s)",
+		"max":             "{0} must be at most {1} characters",
+		"gt":              "{0} must be greater than {1}",
+		"gte":             "{0} must be greater than or equal to {1}",
+		"email":           "{0} must be a valid email address",
+		"voucher_code":    "{0} must be 3-50 uppercase letters, digits, '-' or '_'",
+		"future":          "{0} must be in the future",
+		"unique_vouchers": "{0} must not contain the same voucher_id more than once",
+	},
+	i18n.Indonesian: {
+		"required":        "{0} wajib diisi",
+		"min":             "{0} minimal {1} karakter",
+		"min-items":       "{0} minimal berisi {1} item",
+		"max":             "{0} maksimal {1} karakter",
+		"gt":              "{0} harus lebih dari {1}",
+		"gte":             "{0} harus lebih dari atau sama dengan {1}",
+		"email":           "{0} harus berupa alamat email yang valid",
+		"voucher_code":    "{0} harus 3-50 karakter huruf besar, angka, '-' atau '_'",
+		"future":          "{0} harus di masa depan",
+		"unique_vouchers": "{0} tidak boleh berisi voucher_id yang sama lebih dari sekali",
+	},
+}
+
+var defaultTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	i18n.English:    en_translations.RegisterDefaultTranslations,
+	i18n.Indonesian: id_translations.RegisterDefaultTranslations,
+}
+
+var translatedTags = []string{"required", "min", "max", "gt", "gte", "email", "voucher_code", "future", "unique_vouchers"}
+
+// registerTranslations mendaftarkan terjemahan bawaan validator lalu
+// menimpanya dengan pesan di atas untuk setiap bahasa. Kegagalan di sini
+// adalah kesalahan program, jadi langsung panic seperti regexp.MustCompile.
+func registerTranslations(validate *validator.Validate, translator *ut.UniversalTranslator) {
+	for lang, texts := range messages {
+		trans, found := translator.GetTranslator(lang)
+		if !found {
+			panic(fmt.Sprintf("validation: missing locale %q", lang))
+		}
+		if err := defaultTranslations[lang](validate, trans); err != nil {
+			panic(fmt.Sprintf("validation: register %s translations: %v", lang, err))
+		}
+		for key, text := range texts {
+			if err := trans.Add(key, text, true); err != nil {
+				panic(fmt.Sprintf("validation: add %s translation %q: %v", lang, key, err))
+			}
+		}
+		for _, tag := range translatedTags {
+			if err := validate.RegisterTranslation(tag, trans, noopRegister, translate); err != nil {
+				panic(fmt.Sprintf("validation: register %s translation for %q: %v", lang, tag, err))
+			}
+		}
+	}
+}
+
+// noopRegister dipakai karena pesan sudah ditambahkan langsung ke translator
+func noopRegister(ut.Translator) error {
+	return nil
+}
+
+func translate(trans ut.Translator, fieldErr validator.FieldError) string {
+	key := fieldErr.Tag()
+	if key == "min" && fieldErr.Kind() == reflect.Slice {
+		key = "min-items"
+	}
+	message, err := trans.T(key, fieldName(fieldErr), fieldErr.Param())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
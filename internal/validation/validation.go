@@ -0,0 +1,125 @@
+// Package validation adalah lapisan validasi bersama untuk semua handler.
+// Validasi memakai tag `validate` pada struct domain dan mengembalikan
+// semua field yang gagal sebagai *domain.Error dengan daftar FieldError.
+// Pesan per field diterjemahkan dengan universal-translator.
+package validation
+
+import (
+	"api-otto/internal/domain"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// voucherCodePattern: huruf besar, angka, "-" atau "_", 3 sampai 50 karakter
+var voucherCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,49}$`)
+
+type Validator struct {
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+}
+
+func New() *Validator {
+	validate := validator.New()
+
+	// Nama field pada error mengikuti tag json supaya sama dengan request
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	validate.RegisterValidation("voucher_code", func(fl validator.FieldLevel) bool {
+		return voucherCodePattern.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		value, ok := fl.Field().Interface().(time.Time)
+		return ok && value.After(time.Now())
+	})
+	validate.RegisterValidation("unique_vouchers", func(fl validator.FieldLevel) bool {
+		items, ok := fl.Field().Interface().([]domain.TransactionItem)
+		if !ok {
+			return false
+		}
+		seen := make(map[int64]bool, len(items))
+		for _, item := range items {
+			if seen[item.VoucherID] {
+				return false
+			}
+			seen[item.VoucherID] = true
+		}
+		return true
+	})
+
+	translator := ut.New(en.New(), en.New(), id.New())
+	registerTranslations(validate, translator)
+
+	return &Validator{validate: validate, translator: translator}
+}
+
+// Struct memvalidasi s dan mengembalikan nil atau *domain.Error berisi
+// semua field yang gagal. Pesan field memakai bahasa lang, atau bahasa
+// Inggris jika lang tidak didukung.
+func (v *Validator) Struct(lang string, s interface{}) error {
+	return v.StructExcept(lang, s)
+}
+
+// StructExcept sama dengan Struct tetapi tidak menjalankan rule yang
+// disebut di skip, misalnya "future" saat data lama diubah. Rule yang
+// dilewati harus ditulis paling akhir di tag supaya rule sebelumnya, seperti
+// required, tetap dicek.
+func (v *Validator) StructExcept(lang string, s interface{}, skip ...string) error {
+	err := v.validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	trans, _ := v.translator.GetTranslator(lang)
+	fields := make([]domain.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		if contains(skip, fieldErr.Tag()) {
+			continue
+		}
+		fields = append(fields, domain.FieldError{
+			Field:   fieldName(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: fieldErr.Translate(trans),
+		})
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return domain.NewFieldValidationError(fields)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName membuang nama struct di depan namespace, misalnya
+// "Transaction.items[0].voucher_id" menjadi "items[0].voucher_id"
+func fieldName(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
@@ -0,0 +1,9 @@
+-- Menghapus dalam urutan yang benar (karena ada foreign key)
+DROP TRIGGER IF EXISTS points_ledger_append_only ON points_ledger;
+DROP FUNCTION IF EXISTS prevent_points_ledger_mutation();
+DROP INDEX IF EXISTS idx_points_ledger_transaction_id;
+DROP INDEX IF EXISTS idx_points_ledger_customer_id;
+DROP TABLE IF EXISTS points_ledger;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_customer_id;
+DROP TRIGGER IF EXISTS update_customers_updated_at ON customers;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,92 @@
+-- Membuat tabel customers
+CREATE TABLE IF NOT EXISTS customers (
+    -- Primary key dengan auto-increment
+    id SERIAL PRIMARY KEY,
+
+    -- Nama customer
+    -- NOT NULL: wajib diisi
+    name VARCHAR(255) NOT NULL,
+
+    -- Email customer (opsional)
+    -- UNIQUE: satu email hanya untuk satu customer
+    email VARCHAR(255) UNIQUE,
+
+    -- Timestamp pembuatan data
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Timestamp update data
+    -- Akan diupdate otomatis oleh trigger
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger untuk auto-update updated_at
+CREATE TRIGGER update_customers_updated_at
+    BEFORE UPDATE ON customers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Backfill customer dari transaksi yang sudah ada
+-- Supaya foreign key di bawah bisa dipasang tanpa error
+INSERT INTO customers (id, name)
+SELECT DISTINCT customer_id, 'Customer ' || customer_id
+FROM transactions
+ON CONFLICT (id) DO NOTHING;
+
+-- Sinkronkan sequence dengan id terbesar hasil backfill
+SELECT setval(
+    pg_get_serial_sequence('customers', 'id'),
+    COALESCE((SELECT MAX(id) FROM customers), 0) + 1,
+    false
+);
+
+-- customer_id di transactions sekarang harus menunjuk ke customer yang valid
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transactions_customer_id
+    FOREIGN KEY (customer_id) REFERENCES customers(id);
+
+-- Membuat tabel ledger poin customer
+-- Append-only: saldo dihitung dari SUM semua entry, baris tidak pernah diubah
+CREATE TABLE IF NOT EXISTS points_ledger (
+    -- Primary key dengan auto-increment
+    id SERIAL PRIMARY KEY,
+
+    -- Foreign key ke tabel customers
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+
+    -- Jenis entry
+    -- Hanya bisa: 'credit' (poin masuk), 'debit' (poin keluar)
+    entry_type VARCHAR(10) NOT NULL CHECK (entry_type IN ('credit', 'debit')),
+
+    -- Jumlah poin, selalu positif. Arah ditentukan oleh entry_type
+    points INTEGER NOT NULL CHECK (points > 0),
+
+    -- Alasan perubahan poin, misalnya 'redemption' atau 'top_up'
+    reason VARCHAR(255) NOT NULL,
+
+    -- Referensi ke transaksi (opsional)
+    -- Diisi untuk debit hasil redemption
+    transaction_id INTEGER REFERENCES transactions(id),
+
+    -- Timestamp pembuatan entry
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mempercepat perhitungan saldo per customer
+CREATE INDEX idx_points_ledger_customer_id ON points_ledger(customer_id);
+
+-- Mempercepat pencarian entry berdasarkan transaksi
+CREATE INDEX idx_points_ledger_transaction_id ON points_ledger(transaction_id);
+
+-- Fungsi untuk menolak UPDATE/DELETE pada ledger
+CREATE OR REPLACE FUNCTION prevent_points_ledger_mutation()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'points_ledger is append-only';
+END;
+$$ language 'plpgsql';
+
+-- Trigger untuk menjaga ledger tetap append-only
+CREATE TRIGGER points_ledger_append_only
+    BEFORE UPDATE OR DELETE ON points_ledger
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_points_ledger_mutation();
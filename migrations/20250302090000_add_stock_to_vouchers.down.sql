@@ -0,0 +1,5 @@
+-- Menghapus constraint dan kolom stok
+ALTER TABLE vouchers DROP CONSTRAINT IF EXISTS chk_vouchers_stock;
+ALTER TABLE vouchers
+    DROP COLUMN IF EXISTS remaining_stock,
+    DROP COLUMN IF EXISTS total_stock;
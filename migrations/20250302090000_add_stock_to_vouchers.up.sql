@@ -0,0 +1,18 @@
+-- Menambahkan kuota stok ke tabel vouchers
+-- NULL berarti voucher tidak dibatasi stoknya (perilaku lama)
+ALTER TABLE vouchers
+    -- Jumlah total unit voucher yang disediakan brand
+    -- CHECK: tidak boleh negatif
+    ADD COLUMN total_stock INTEGER CHECK (total_stock >= 0),
+
+    -- Sisa unit yang masih bisa diredeem
+    -- CHECK: tidak boleh negatif, ini pengaman terakhir saat redemption paralel
+    ADD COLUMN remaining_stock INTEGER CHECK (remaining_stock >= 0);
+
+-- total_stock dan remaining_stock harus sama-sama diisi atau sama-sama NULL,
+-- dan sisa stok tidak boleh melebihi total stok
+ALTER TABLE vouchers
+    ADD CONSTRAINT chk_vouchers_stock CHECK (
+        (total_stock IS NULL AND remaining_stock IS NULL)
+        OR (total_stock IS NOT NULL AND remaining_stock IS NOT NULL AND remaining_stock <= total_stock)
+    );
@@ -0,0 +1,9 @@
+-- Hapus transaksi gagal yang tidak memenuhi constraint lama
+-- Transaksi gagal tidak punya item maupun entry ledger
+DELETE FROM transactions WHERE total_points = 0;
+
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_total_points_check,
+    ADD CONSTRAINT transactions_total_points_check CHECK (total_points > 0);
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS failure_reason;
@@ -0,0 +1,9 @@
+-- Alasan kegagalan untuk transaksi berstatus 'failed'
+-- TEXT: pesan error bisa panjang, NULL untuk transaksi yang berhasil
+ALTER TABLE transactions ADD COLUMN failure_reason TEXT;
+
+-- Transaksi gagal bisa tercatat sebelum total poin selesai dihitung,
+-- sehingga total_points 0 sekarang diperbolehkan
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_total_points_check,
+    ADD CONSTRAINT transactions_total_points_check CHECK (total_points >= 0);
@@ -0,0 +1,31 @@
+-- Membuat tabel idempotency_keys
+-- Menyimpan Idempotency-Key dari client beserta response pertama,
+-- supaya retry dengan key yang sama tidak membuat data baru
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    -- Endpoint tempat key dipakai, misalnya 'POST /transaction/redemption'
+    -- Key yang sama boleh dipakai di endpoint berbeda
+    scope VARCHAR(100) NOT NULL,
+
+    -- Nilai header Idempotency-Key dari client
+    idempotency_key VARCHAR(255) NOT NULL,
+
+    -- SHA-256 (hex) dari request body pertama
+    -- Dipakai untuk menolak key yang sama dengan body berbeda
+    request_hash CHAR(64) NOT NULL,
+
+    -- Status dan body response yang disimpan
+    -- NULL selama request pertama masih diproses
+    response_status INTEGER,
+    response_body BYTEA,
+
+    -- Timestamp pembuatan key
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Setelah waktu ini key dianggap tidak ada dan boleh dipakai ulang
+    expires_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+-- Mempercepat pembersihan key yang sudah kadaluarsa
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_transaction_refunds_transaction_id;
+DROP TABLE IF EXISTS transaction_refunds;
+
+ALTER TABLE transaction_items DROP COLUMN IF EXISTS status;
+ALTER TABLE brands DROP COLUMN IF EXISTS cancellation_window_minutes;
+
+-- Transaksi yang dibatalkan/direfund dikembalikan ke status lama terdekat
+UPDATE transactions SET status = 'completed' WHERE status IN ('cancelled', 'refunded');
+
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_status_check,
+    ADD CONSTRAINT transactions_status_check
+        CHECK (status IN ('pending', 'completed', 'failed'));
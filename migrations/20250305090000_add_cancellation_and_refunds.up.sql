@@ -0,0 +1,48 @@
+-- Status transaksi baru untuk pembatalan dan refund
+-- 'cancelled': seluruh transaksi dibatalkan
+-- 'refunded': semua item sudah direfund satu per satu
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_status_check,
+    ADD CONSTRAINT transactions_status_check
+        CHECK (status IN ('pending', 'completed', 'failed', 'cancelled', 'refunded'));
+
+-- Batas waktu pembatalan per brand, dalam menit sejak transaksi dibuat
+-- 0 berarti transaksi voucher brand ini tidak bisa dibatalkan
+ALTER TABLE brands
+    ADD COLUMN cancellation_window_minutes INTEGER NOT NULL DEFAULT 1440
+        CHECK (cancellation_window_minutes >= 0);
+
+-- Status per item supaya item yang sama tidak direfund dua kali
+ALTER TABLE transaction_items
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'redeemed'
+        CHECK (status IN ('redeemed', 'refunded'));
+
+-- Catatan setiap refund: siapa yang melakukan dan alasannya
+CREATE TABLE IF NOT EXISTS transaction_refunds (
+    -- Primary key dengan auto-increment
+    id SERIAL PRIMARY KEY,
+
+    -- Transaksi yang direfund
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+
+    -- Item yang direfund
+    transaction_item_id INTEGER NOT NULL REFERENCES transaction_items(id),
+
+    -- Poin yang dikembalikan ke customer
+    points INTEGER NOT NULL CHECK (points > 0),
+
+    -- Siapa yang melakukan pembatalan/refund
+    actor VARCHAR(100) NOT NULL,
+
+    -- Alasan pembatalan/refund
+    reason VARCHAR(255) NOT NULL,
+
+    -- Timestamp refund
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Satu item hanya bisa direfund sekali
+    UNIQUE (transaction_item_id)
+);
+
+-- Mempercepat pencarian refund berdasarkan transaksi
+CREATE INDEX idx_transaction_refunds_transaction_id ON transaction_refunds(transaction_id);
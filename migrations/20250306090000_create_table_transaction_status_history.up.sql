@@ -0,0 +1,32 @@
+-- Membuat tabel riwayat perubahan status transaksi
+CREATE TABLE IF NOT EXISTS transaction_status_history (
+    -- Primary key dengan auto-increment
+    id SERIAL PRIMARY KEY,
+
+    -- Transaksi yang statusnya berubah
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+
+    -- Status sebelum perubahan
+    -- NULL untuk entry pertama saat transaksi dibuat
+    from_status VARCHAR(20),
+
+    -- Status sesudah perubahan
+    to_status VARCHAR(20) NOT NULL,
+
+    -- Siapa yang mengubah status, misalnya 'system' atau id petugas
+    actor VARCHAR(100) NOT NULL,
+
+    -- Alasan perubahan (opsional)
+    reason TEXT,
+
+    -- Waktu perubahan status
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mempercepat pengambilan riwayat per transaksi
+CREATE INDEX idx_transaction_status_history_transaction_id ON transaction_status_history(transaction_id);
+
+-- Backfill status terakhir dari transaksi yang sudah ada
+INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, reason, created_at)
+SELECT id, NULL, status, 'migration', failure_reason, updated_at
+FROM transactions;
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_transactions_customer_id_created_at_id;
+DROP INDEX IF EXISTS idx_vouchers_valid_until_id;
+DROP INDEX IF EXISTS idx_vouchers_points_id;
+DROP INDEX IF EXISTS idx_vouchers_brand_id_id;
+DROP INDEX IF EXISTS idx_brands_name_id;
+
+ALTER TABLE transactions
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
+
+ALTER TABLE vouchers
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
+
+ALTER TABLE brands
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP NOT NULL;
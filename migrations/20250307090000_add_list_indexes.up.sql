@@ -0,0 +1,26 @@
+-- Kolom yang dipakai untuk sort wajib NOT NULL karena cursor pagination
+-- membandingkan (kolom, id) secara keyset
+UPDATE brands SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE brands SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE brands
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE vouchers SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE vouchers SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE vouchers
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE transactions SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE transactions SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE transactions
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET NOT NULL;
+
+-- Index untuk sort default dan filter yang paling sering dipakai
+CREATE INDEX idx_brands_name_id ON brands(name, id);
+CREATE INDEX idx_vouchers_brand_id_id ON vouchers(brand_id, id);
+CREATE INDEX idx_vouchers_points_id ON vouchers(points, id);
+CREATE INDEX idx_vouchers_valid_until_id ON vouchers(valid_until, id);
+CREATE INDEX idx_transactions_customer_id_created_at_id ON transactions(customer_id, created_at, id);
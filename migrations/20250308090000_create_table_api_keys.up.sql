@@ -0,0 +1,20 @@
+-- Membuat tabel api_keys
+-- API key statis untuk integrasi partner dan back-office. Key asli hanya
+-- ditampilkan sekali saat dibuat, yang disimpan hanya hash-nya
+CREATE TABLE IF NOT EXISTS api_keys (
+    -- ID unik API key
+    id BIGSERIAL PRIMARY KEY,
+
+    -- Nama pemilik key, misalnya 'backoffice' atau 'partner-tokopedia'
+    name VARCHAR(100) NOT NULL,
+
+    -- SHA-256 (hex) dari key asli
+    key_hash CHAR(64) NOT NULL UNIQUE,
+
+    -- Timestamp pembuatan key
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Key yang sudah dicabut tidak bisa dipakai lagi
+    -- NULL berarti key masih aktif
+    revoked_at TIMESTAMP
+);
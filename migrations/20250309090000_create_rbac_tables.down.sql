@@ -0,0 +1,7 @@
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS brand_id,
+    DROP COLUMN IF EXISTS role;
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
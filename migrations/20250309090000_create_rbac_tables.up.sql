@@ -0,0 +1,67 @@
+-- Membuat tabel roles, permissions dan role_permissions
+-- Hak akses disimpan sebagai data sehingga permission sebuah role bisa
+-- diubah tanpa deploy ulang
+CREATE TABLE IF NOT EXISTS roles (
+    -- Nama role, misalnya 'platform_admin'
+    name VARCHAR(50) PRIMARY KEY,
+
+    -- Penjelasan singkat untuk admin
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    -- Nama permission dengan format '<resource>:<aksi>', misalnya 'voucher:write'
+    name VARCHAR(50) PRIMARY KEY,
+
+    -- Penjelasan singkat untuk admin
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('platform_admin', 'Mengelola semua data'),
+    ('brand_manager', 'Mengelola voucher dan melihat redemption milik satu brand'),
+    ('customer', 'Menukar poin dan melihat transaksi miliknya sendiri')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('brand:read', 'Melihat brand'),
+    ('brand:write', 'Membuat, mengubah dan menghapus brand'),
+    ('voucher:read', 'Melihat voucher'),
+    ('voucher:write', 'Membuat, mengubah dan menghapus voucher'),
+    ('customer:read', 'Melihat customer dan riwayat poin'),
+    ('customer:write', 'Membuat customer dan menambah poin'),
+    ('transaction:read', 'Melihat transaksi redemption'),
+    ('transaction:redeem', 'Membuat redemption'),
+    ('transaction:refund', 'Membatalkan redemption dan refund item')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'platform_admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('brand_manager', 'brand:read'),
+    ('brand_manager', 'voucher:read'),
+    ('brand_manager', 'voucher:write'),
+    ('brand_manager', 'transaction:read'),
+    ('customer', 'brand:read'),
+    ('customer', 'voucher:read'),
+    ('customer', 'transaction:read'),
+    ('customer', 'transaction:redeem')
+ON CONFLICT DO NOTHING;
+
+-- Role dan brand pemilik API key
+-- Key yang sudah ada tetap punya akses penuh seperti sebelumnya
+-- brand_id diisi untuk key yang hanya boleh mengakses satu brand
+ALTER TABLE api_keys
+    ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'platform_admin' REFERENCES roles(name),
+    ADD COLUMN brand_id INTEGER REFERENCES brands(id) ON DELETE CASCADE;
+
+ALTER TABLE api_keys ALTER COLUMN role DROP DEFAULT;
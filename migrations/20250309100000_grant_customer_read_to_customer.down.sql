@@ -0,0 +1 @@
+DELETE FROM role_permissions WHERE role = 'customer' AND permission = 'customer:read';
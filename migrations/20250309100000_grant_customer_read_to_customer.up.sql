@@ -0,0 +1,6 @@
+-- Customer boleh melihat profil dan riwayat poinnya sendiri
+-- Service membatasi customer ke datanya sendiri, permission ini hanya
+-- membuka route GET /customer/:id dan GET /customer/:id/points
+INSERT INTO role_permissions (role, permission) VALUES
+    ('customer', 'customer:read')
+ON CONFLICT DO NOTHING;
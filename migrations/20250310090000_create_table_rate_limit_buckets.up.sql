@@ -0,0 +1,18 @@
+-- Membuat tabel rate_limit_buckets
+-- Menyimpan token bucket rate limiter supaya batas request berlaku di
+-- semua instance aplikasi
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    -- Route dan client pemilik bucket, misalnya
+    -- 'POST /transaction/redemption customer:42' atau 'GET /brand ip:10.0.0.5'
+    bucket_key VARCHAR(255) PRIMARY KEY,
+
+    -- Sisa token pada updated_at, bisa pecahan karena token terisi terus
+    tokens DOUBLE PRECISION NOT NULL,
+
+    -- Waktu terakhir bucket dipakai
+    -- TIMESTAMPTZ supaya selisih waktu antar instance tetap benar
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Mempercepat pembersihan bucket yang sudah lama tidak dipakai
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP INDEX IF EXISTS idx_audit_log_entity;
+DROP TABLE IF EXISTS audit_log;
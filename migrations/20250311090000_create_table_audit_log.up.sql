@@ -0,0 +1,42 @@
+-- Membuat tabel audit_log
+-- Mencatat siapa mengubah brand, voucher dan status redemption. Entry ditulis
+-- dalam database transaction yang sama dengan perubahannya, sehingga
+-- perubahan yang di-rollback tidak pernah tercatat.
+CREATE TABLE IF NOT EXISTS audit_log (
+    -- Primary key dengan auto-increment
+    id BIGSERIAL PRIMARY KEY,
+
+    -- Principal yang melakukan perubahan, misalnya 'api_key:3' atau 'customer:42'
+    actor VARCHAR(100) NOT NULL,
+
+    -- X-Request-ID request yang melakukan perubahan, untuk mencari lognya
+    request_id VARCHAR(128),
+
+    -- Jenis dan ID data yang berubah
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('brand', 'voucher', 'transaction')),
+    entity_id BIGINT NOT NULL,
+
+    -- Jenis perubahan
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'status_change')),
+
+    -- Nilai field yang berubah sebelum dan sesudah perubahan
+    -- before_data NULL untuk create, after_data NULL untuk delete
+    before_data JSONB,
+    after_data JSONB,
+
+    -- Waktu perubahan
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mempercepat riwayat per data, yang terbaru lebih dulu
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC, id DESC);
+
+-- Hanya platform_admin yang boleh membaca audit log
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Melihat audit log')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('platform_admin', 'audit:read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Entry item_refund tidak bisa disimpan dengan constraint lama
+DELETE FROM audit_log WHERE action = 'item_refund';
+
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'status_change'));
@@ -0,0 +1,6 @@
+-- Menambah action 'item_refund' ke audit_log
+-- Setiap item yang direfund dicatat, termasuk refund sebagian yang tidak
+-- mengubah status transaksi
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'status_change', 'item_refund'));
@@ -0,0 +1,11 @@
+// Package migrations menyematkan file migrasi SQL ke dalam binary supaya
+// aplikasi tahu versi skema yang diharapkan tanpa membaca disk.
+package migrations
+
+import "embed"
+
+// FS berisi file migrasi dengan format nama golang-migrate:
+// {version}_{title}.up.sql dan {version}_{title}.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,359 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/i18n"
+	"api-otto/internal/logging"
+	"api-otto/internal/repository"
+	"api-otto/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuditRepository struct {
+	domain.AuditRepository
+	entries []domain.AuditEntry
+}
+
+func (f *fakeAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.ID = int64(len(f.entries) + 1)
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+// fakeUnitOfWork hanya berisi repository yang dipakai test
+type fakeUnitOfWork struct {
+	domain.UnitOfWork
+	brands   domain.BrandRepository
+	vouchers domain.VoucherRepository
+	audit    *fakeAuditRepository
+}
+
+func (u *fakeUnitOfWork) Brands() domain.BrandRepository     { return u.brands }
+func (u *fakeUnitOfWork) Vouchers() domain.VoucherRepository { return u.vouchers }
+func (u *fakeUnitOfWork) Audit() domain.AuditRepository      { return u.audit }
+
+// fakeTxManager membuang entry audit yang ditulis fn jika fn gagal, seperti
+// rollback database transaction
+type fakeTxManager struct {
+	uow *fakeUnitOfWork
+}
+
+func newFakeTxManager(brands domain.BrandRepository, vouchers domain.VoucherRepository) *fakeTxManager {
+	return &fakeTxManager{uow: &fakeUnitOfWork{brands: brands, vouchers: vouchers, audit: &fakeAuditRepository{}}}
+}
+
+func (m *fakeTxManager) WithinTransaction(ctx context.Context, fn func(uow domain.UnitOfWork) error) error {
+	committed := len(m.uow.audit.entries)
+	if err := fn(m.uow); err != nil {
+		m.uow.audit.entries = m.uow.audit.entries[:committed]
+		return err
+	}
+	return nil
+}
+
+// memoryBrandRepository menyimpan brand di map untuk test audit
+type memoryBrandRepository struct {
+	domain.BrandRepository
+	brands    map[int64]domain.Brand
+	deleteErr error
+}
+
+func (r *memoryBrandRepository) Create(ctx context.Context, brand *domain.Brand) error {
+	brand.ID = int64(len(r.brands) + 1)
+	r.brands[brand.ID] = *brand
+	return nil
+}
+
+func (r *memoryBrandRepository) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+	brand, ok := r.brands[id]
+	if !ok {
+		return nil, nil
+	}
+	return &brand, nil
+}
+
+func (r *memoryBrandRepository) Update(ctx context.Context, brand *domain.Brand) error {
+	brand.UpdatedAt = time.Now()
+	r.brands[brand.ID] = *brand
+	return nil
+}
+
+func (r *memoryBrandRepository) Delete(ctx context.Context, id int64) error {
+	if r.deleteErr != nil {
+		return r.deleteErr
+	}
+	delete(r.brands, id)
+	return nil
+}
+
+type MockAuditRepository struct {
+	domain.AuditRepository
+	mock.Mock
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, string, error) {
+	args := m.Called(filter)
+	entries, _ := args.Get(0).([]domain.AuditEntry)
+	return entries, args.String(1), args.Error(2)
+}
+
+func TestAuditEntry_SetDiff(t *testing.T) {
+	window := 60
+	brand := domain.Brand{ID: 1, Name: "Kopi Kenangan", CancellationWindowMinutes: &window}
+	renamed := brand
+	renamed.Name = "Kopi Kenangan Mantan"
+	renamed.UpdatedAt = time.Now()
+
+	tests := []struct {
+		name           string
+		before         interface{}
+		after          interface{}
+		expectedBefore string
+		expectedAfter  string
+	}{
+		{
+			name:          "Create",
+			after:         brand,
+			expectedAfter: `{"id":1,"name":"Kopi Kenangan","description":"","cancellation_window_minutes":60,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Update Keeps Only Changed Fields",
+			before:         brand,
+			after:          renamed,
+			expectedBefore: `{"name":"Kopi Kenangan"}`,
+			expectedAfter:  `{"name":"Kopi Kenangan Mantan"}`,
+		},
+		{
+			name:           "Removed Field",
+			before:         brand,
+			after:          domain.Brand{ID: 1, Name: "Kopi Kenangan"},
+			expectedBefore: `{"cancellation_window_minutes":60}`,
+			expectedAfter:  `{}`,
+		},
+		{
+			name:           "Delete",
+			before:         map[string]interface{}{"status": "pending"},
+			expectedBefore: `{"status":"pending"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &domain.AuditEntry{}
+
+			require.NoError(t, entry.SetDiff(tt.before, tt.after))
+
+			assertJSON(t, tt.expectedBefore, entry.Before)
+			assertJSON(t, tt.expectedAfter, entry.After)
+		})
+	}
+}
+
+func assertJSON(t *testing.T, expected string, actual json.RawMessage) {
+	if expected == "" {
+		assert.Nil(t, actual)
+		return
+	}
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestBrandService_Audit(t *testing.T) {
+	repo := &memoryBrandRepository{brands: map[int64]domain.Brand{}}
+	txManager := newFakeTxManager(repo, nil)
+	brandService := service.NewBrandService(txManager, repo)
+	ctx := logging.WithRequestID(domain.WithPrincipal(context.Background(), adminPrincipal), "req-1")
+
+	brand := &domain.Brand{Name: "Kopi Kenangan"}
+	require.NoError(t, brandService.Create(ctx, brand))
+	brand.Name = "Kopi Kenangan Mantan"
+	require.NoError(t, brandService.Update(ctx, brand))
+	require.NoError(t, brandService.Delete(ctx, brand.ID))
+
+	entries := txManager.uow.audit.entries
+	require.Len(t, entries, 3)
+	for i, action := range []domain.AuditAction{domain.AuditActionCreate, domain.AuditActionUpdate, domain.AuditActionDelete} {
+		assert.Equal(t, action, entries[i].Action)
+		assert.Equal(t, "api_key:1", entries[i].Actor)
+		assert.Equal(t, "req-1", entries[i].RequestID)
+		assert.Equal(t, domain.AuditEntityBrand, entries[i].EntityType)
+		assert.Equal(t, brand.ID, entries[i].EntityID)
+	}
+	assert.Nil(t, entries[0].Before)
+	assertJSON(t, `{"name":"Kopi Kenangan"}`, entries[1].Before)
+	assertJSON(t, `{"name":"Kopi Kenangan Mantan"}`, entries[1].After)
+	assert.Contains(t, string(entries[2].Before), `"name":"Kopi Kenangan Mantan"`)
+	assert.Nil(t, entries[2].After)
+}
+
+func TestBrandService_AuditRolledBack(t *testing.T) {
+	repo := &memoryBrandRepository{
+		brands:    map[int64]domain.Brand{1: {ID: 1, Name: "Kopi Kenangan"}},
+		deleteErr: domain.ErrBrandHasVouchers,
+	}
+	txManager := newFakeTxManager(repo, nil)
+	brandService := service.NewBrandService(txManager, repo)
+
+	err := brandService.Delete(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrBrandHasVouchers)
+	assert.Empty(t, txManager.uow.audit.entries)
+
+	// Brand yang tidak ada tidak dicatat
+	assert.ErrorIs(t, brandService.Delete(context.Background(), 2), domain.ErrBrandNotFound)
+	assert.Empty(t, txManager.uow.audit.entries)
+}
+
+func TestVoucherService_AuditPointsChange(t *testing.T) {
+	validUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeVoucherRepository{vouchers: map[int64]*domain.Voucher{
+		1: {ID: 1, BrandID: 10, Code: "KOPI50", Points: 100, ValidUntil: validUntil, Brand: &domain.Brand{ID: 10, Name: "Kopi"}},
+	}}
+	txManager := newFakeTxManager(fakeBrandRepository{}, repo)
+	voucherService := service.NewVoucherService(txManager, repo, fakeBrandRepository{})
+	ctx := domain.WithPrincipal(context.Background(), brandManagerPrincipal)
+
+	err := voucherService.Update(ctx, &domain.Voucher{ID: 1, BrandID: 10, Code: "KOPI50", Points: 200, ValidUntil: validUntil})
+
+	require.NoError(t, err)
+	entries := txManager.uow.audit.entries
+	require.Len(t, entries, 1)
+	assert.Equal(t, "api_key:2", entries[0].Actor)
+	assert.Equal(t, domain.AuditEntityVoucher, entries[0].EntityType)
+	assert.Equal(t, int64(1), entries[0].EntityID)
+	assertJSON(t, `{"points":100}`, entries[0].Before)
+	assertJSON(t, `{"points":200}`, entries[0].After)
+}
+
+func TestAuditHandler_List(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedFilter *domain.AuditFilter
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Voucher History",
+			query:          "?entity=voucher&id=5&limit=10",
+			expectedFilter: &domain.AuditFilter{EntityType: domain.AuditEntityVoucher, EntityID: int64Ptr(5), Page: domain.Page{Limit: 10}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "All Entries",
+			expectedFilter: &domain.AuditFilter{},
+			expectedStatus: http.StatusOK,
+		},
+		{name: "Unknown Entity", query: "?entity=customer", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_AUDIT_ENTITY"},
+		{name: "ID Without Entity", query: "?id=5", expectedStatus: http.StatusBadRequest, expectedCode: "AUDIT_ENTITY_REQUIRED"},
+		{name: "Invalid ID", query: "?entity=voucher&id=abc", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_QUERY_PARAMETER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockAuditRepository)
+			entries := []domain.AuditEntry{{ID: 1, Actor: "api_key:1", EntityType: domain.AuditEntityVoucher, EntityID: 5, Action: domain.AuditActionUpdate}}
+			if tt.expectedFilter != nil {
+				mockRepo.On("List", *tt.expectedFilter).Return(entries, "next", nil)
+			}
+			auditHandler := handler.NewAuditHandler(service.NewAuditService(mockRepo))
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/audit"+tt.query, nil)
+			i18n.Middleware(i18n.English, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auditHandler.List(w, r, nil)
+			})).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			var resp handler.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedCode, resp.ErrorCode)
+			if tt.expectedFilter != nil {
+				assert.Equal(t, "next", resp.NextCursor)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// TestAuditRepository butuh database Postgres yang sudah dimigrasi, set
+// TEST_DATABASE_URL untuk menjalankannya
+func TestAuditRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	txManager := repository.NewTxManager(db)
+	auditService := service.NewAuditService(repository.NewAuditRepository(db))
+	brandService := service.NewBrandService(txManager, repository.NewBrandRepository(db))
+	voucherService := service.NewVoucherService(txManager, repository.NewVoucherRepository(db), repository.NewBrandRepository(db))
+	ctx := logging.WithRequestID(domain.WithPrincipal(context.Background(), adminPrincipal), "audit-test")
+
+	brand := &domain.Brand{Name: "Audit Brand"}
+	require.NoError(t, brandService.Create(ctx, brand))
+	voucher := &domain.Voucher{
+		BrandID:    brand.ID,
+		Code:       fmt.Sprintf("AUDIT%d", time.Now().UnixNano()),
+		Name:       "Audit voucher",
+		Points:     100,
+		ValidUntil: time.Now().Add(24 * time.Hour),
+	}
+	require.NoError(t, voucherService.Create(ctx, voucher))
+	voucher.Points = 150
+	require.NoError(t, voucherService.Update(ctx, voucher))
+	voucher.Points = 175
+	require.NoError(t, voucherService.Update(ctx, voucher))
+
+	// Perubahan yang gagal tidak tercatat
+	err = brandService.Delete(ctx, brand.ID)
+	require.ErrorIs(t, err, domain.ErrBrandHasVouchers)
+
+	filter := domain.AuditFilter{EntityType: domain.AuditEntityVoucher, EntityID: &voucher.ID, Page: domain.Page{Limit: 2}}
+	first, next, err := auditService.List(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	require.NotEmpty(t, next)
+	assert.Equal(t, domain.AuditActionUpdate, first[0].Action)
+	assert.Equal(t, "api_key:1", first[0].Actor)
+	assert.Equal(t, "audit-test", first[0].RequestID)
+	assert.JSONEq(t, `{"points":150}`, string(first[0].Before))
+	assert.JSONEq(t, `{"points":175}`, string(first[0].After))
+
+	filter.Cursor = next
+	second, next, err := auditService.List(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Empty(t, next)
+	assert.Equal(t, domain.AuditActionCreate, second[0].Action)
+	assert.Nil(t, second[0].Before)
+
+	brandEntries, _, err := auditService.List(ctx, domain.AuditFilter{EntityType: domain.AuditEntityBrand, EntityID: &brand.ID})
+	require.NoError(t, err)
+	require.Len(t, brandEntries, 1)
+	assert.Equal(t, domain.AuditActionCreate, brandEntries[0].Action)
+
+	_, _, err = auditService.List(ctx, domain.AuditFilter{EntityType: "customer"})
+	assert.True(t, errors.Is(err, domain.ErrInvalidAuditEntity))
+}
@@ -0,0 +1,355 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/auth"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/repository"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret-that-is-at-least-32-bytes"
+
+// fakeAPIKeyRepository menyimpan API key di memory untuk kebutuhan test
+type fakeAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*domain.APIKey
+}
+
+func newFakeAPIKeyRepository() *fakeAPIKeyRepository {
+	return &fakeAPIKeyRepository{keys: map[string]*domain.APIKey{}}
+}
+
+func (f *fakeAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key.ID = int64(len(f.keys) + 1)
+	copied := *key
+	f.keys[key.KeyHash] = &copied
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, ok := f.keys[keyHash]
+	if !ok || key.RevokedAt != nil {
+		return nil, nil
+	}
+	copied := *key
+	return &copied, nil
+}
+
+// fakeRoleRepository memakai permission bawaan dari migration RBAC dan
+// audit log
+type fakeRoleRepository map[domain.Role][]domain.Permission
+
+func newFakeRoleRepository() fakeRoleRepository {
+	return fakeRoleRepository{
+		domain.RolePlatformAdmin: {
+			domain.PermissionBrandRead, domain.PermissionBrandWrite,
+			domain.PermissionVoucherRead, domain.PermissionVoucherWrite,
+			domain.PermissionCustomerRead, domain.PermissionCustomerWrite,
+			domain.PermissionTransactionRead, domain.PermissionTransactionRedeem, domain.PermissionTransactionRefund,
+			domain.PermissionAuditRead,
+		},
+		domain.RoleBrandManager: {
+			domain.PermissionBrandRead, domain.PermissionVoucherRead, domain.PermissionVoucherWrite, domain.PermissionTransactionRead,
+		},
+		domain.RoleCustomer: {
+			domain.PermissionBrandRead, domain.PermissionVoucherRead, domain.PermissionCustomerRead,
+			domain.PermissionTransactionRead, domain.PermissionTransactionRedeem,
+		},
+	}
+}
+
+func (f fakeRoleRepository) Permissions(ctx context.Context, role domain.Role) ([]domain.Permission, error) {
+	return f[role], nil
+}
+
+// addAPIKey menyimpan apiKey dengan key acak baru dan mengembalikan key
+// aslinya
+func addAPIKey(t *testing.T, repo domain.APIKeyRepository, apiKey *domain.APIKey) string {
+	key, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	apiKey.KeyHash = auth.HashAPIKey(key)
+	if apiKey.Role == "" {
+		apiKey.Role = domain.RolePlatformAdmin
+	}
+	require.NoError(t, repo.Create(context.Background(), apiKey))
+	return key
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func customerClaims(sub string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+// principalEcho mengembalikan principal dari context sebagai body response
+func principalEcho(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	principal, ok := domain.PrincipalFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte(principal.String()))
+}
+
+func TestAuth_Authenticate(t *testing.T) {
+	repo := newFakeAPIKeyRepository()
+	validKey := addAPIKey(t, repo, &domain.APIKey{Name: "backoffice"})
+	revokedAt := time.Now()
+	revokedKey := addAPIKey(t, repo, &domain.APIKey{Name: "old-partner", RevokedAt: &revokedAt})
+
+	authenticator, err := auth.New(repo, newFakeRoleRepository(), auth.Options{Algorithm: "HS256", Secret: []byte(testJWTSecret), Issuer: "customer-app"})
+	require.NoError(t, err)
+
+	hs256 := func(claims jwt.MapClaims) string {
+		return signToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), claims)
+	}
+	withIssuer := func(claims jwt.MapClaims) jwt.MapClaims {
+		claims["iss"] = "customer-app"
+		return claims
+	}
+
+	tests := []struct {
+		name              string
+		headers           map[string]string
+		expectedStatus    int
+		expectedPrincipal string
+		expectedCode      string
+	}{
+		{name: "API Key", headers: map[string]string{"X-API-Key": validKey}, expectedStatus: http.StatusOK, expectedPrincipal: "api_key:1"},
+		{name: "Customer Token", headers: map[string]string{"Authorization": "Bearer " + hs256(withIssuer(customerClaims("42")))}, expectedStatus: http.StatusOK, expectedPrincipal: "customer:42"},
+		{name: "Lowercase Bearer Scheme", headers: map[string]string{"Authorization": "bearer " + hs256(withIssuer(customerClaims("42")))}, expectedStatus: http.StatusOK, expectedPrincipal: "customer:42"},
+		{name: "Missing Credentials", expectedStatus: http.StatusUnauthorized, expectedCode: "AUTHENTICATION_REQUIRED"},
+		{name: "Basic Auth Is Not Supported", headers: map[string]string{"Authorization": "Basic YWRtaW46YWRtaW4="}, expectedStatus: http.StatusUnauthorized, expectedCode: "AUTHENTICATION_REQUIRED"},
+		{name: "Unknown API Key", headers: map[string]string{"X-API-Key": "ak_unknown"}, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_API_KEY"},
+		{name: "Revoked API Key", headers: map[string]string{"X-API-Key": revokedKey}, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_API_KEY"},
+		{
+			name:           "Expired Token",
+			headers:        map[string]string{"Authorization": "Bearer " + hs256(withIssuer(jwt.MapClaims{"sub": "42", "exp": time.Now().Add(-time.Hour).Unix()}))},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "Token Without Expiry",
+			headers:        map[string]string{"Authorization": "Bearer " + hs256(withIssuer(jwt.MapClaims{"sub": "42"}))},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "Wrong Secret",
+			headers:        map[string]string{"Authorization": "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("another-secret-that-is-32-bytes-long"), withIssuer(customerClaims("42")))},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{
+			name:           "Unsigned Token",
+			headers:        map[string]string{"Authorization": "Bearer " + signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, withIssuer(customerClaims("42")))},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
+		},
+		{name: "Wrong Issuer", headers: map[string]string{"Authorization": "Bearer " + hs256(customerClaims("42"))}, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_TOKEN"},
+		{name: "Subject Is Not A Customer ID", headers: map[string]string{"Authorization": "Bearer " + hs256(withIssuer(customerClaims("alice")))}, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_TOKEN"},
+		{name: "Malformed Token", headers: map[string]string{"Authorization": "Bearer not-a-jwt"}, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/brand", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.Authenticate(authenticator, principalEcho)(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedCode == "" {
+				assert.Equal(t, tt.expectedPrincipal, rec.Body.String())
+				return
+			}
+			var resp handler.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedCode, resp.ErrorCode)
+			assert.Equal(t, `Bearer realm="api-otto"`, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestAuth_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicKey, err := auth.ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	authenticator, err := auth.New(newFakeAPIKeyRepository(), newFakeRoleRepository(), auth.Options{Algorithm: "RS256", PublicKey: publicKey, Audience: "api-otto"})
+	require.NoError(t, err)
+
+	claims := customerClaims("7")
+	claims["aud"] = "api-otto"
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "Valid Token", token: signToken(t, jwt.SigningMethodRS256, privateKey, claims), expectedStatus: http.StatusOK},
+		{name: "Wrong Audience", token: signToken(t, jwt.SigningMethodRS256, privateKey, customerClaims("7")), expectedStatus: http.StatusUnauthorized},
+		// Token HS256 yang ditandatangani dengan public key tidak boleh lolos
+		{name: "Algorithm Confusion", token: signToken(t, jwt.SigningMethodHS256, der, claims), expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/customer/7/transactions", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			handler.Authenticate(authenticator, principalEcho)(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "customer:7", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuth_TokensDisabledWithoutSecret(t *testing.T) {
+	authenticator, err := auth.New(newFakeAPIKeyRepository(), newFakeRoleRepository(), auth.Options{Algorithm: "HS256"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/brand", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, []byte(""), customerClaims("1")))
+	rec := httptest.NewRecorder()
+
+	handler.Authenticate(authenticator, principalEcho)(rec, req, nil)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuth_InvalidOptions(t *testing.T) {
+	_, err := auth.New(newFakeAPIKeyRepository(), newFakeRoleRepository(), auth.Options{Algorithm: "RS256"})
+	assert.EqualError(t, err, "auth: RS256 requires a public key")
+
+	_, err = auth.New(newFakeAPIKeyRepository(), newFakeRoleRepository(), auth.Options{Algorithm: "ES256"})
+	assert.EqualError(t, err, `auth: unsupported JWT algorithm "ES256"`)
+}
+
+func TestAuth_RedemptionUsesCustomerFromToken(t *testing.T) {
+	authenticator, err := auth.New(newFakeAPIKeyRepository(), newFakeRoleRepository(), auth.Options{Algorithm: "HS256", Secret: []byte(testJWTSecret)})
+	require.NoError(t, err)
+	token := signToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), customerClaims("42"))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Body Customer Is Ignored", body: `{"customer_id":999,"items":[{"voucher_id":1}]}`},
+		{name: "Body Without Customer", body: `{"items":[{"voucher_id":1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			mockService.On("CreateRedemption", mock.MatchedBy(func(transaction *domain.Transaction) bool {
+				return transaction.CustomerID == 42
+			})).Return(nil).Once()
+			handle := handler.Authenticate(authenticator, handler.NewTransactionHandler(mockService).CreateRedemption)
+
+			req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			handle(rec, req, nil)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuth_IdempotencyKeyScopedToPrincipal(t *testing.T) {
+	repo := newFakeAPIKeyRepository()
+	partnerA := addAPIKey(t, repo, &domain.APIKey{Name: "partner-a"})
+	partnerB := addAPIKey(t, repo, &domain.APIKey{Name: "partner-b"})
+	authenticator, err := auth.New(repo, newFakeRoleRepository(), auth.Options{Algorithm: "HS256"})
+	require.NoError(t, err)
+
+	mockService := new(MockTransactionService)
+	mockService.On("CreateRedemption", mock.Anything).Return(nil).Twice()
+	idempotency := handler.NewIdempotency(newFakeIdempotencyRepository(), time.Hour)
+	handle := handler.Authenticate(authenticator, idempotency.Wrap("POST /transaction/redemption", handler.NewTransactionHandler(mockService).CreateRedemption))
+
+	for _, key := range []string{partnerA, partnerB} {
+		req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", bytes.NewBufferString(`{"customer_id":1,"items":[{"voucher_id":1}]}`))
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set(handler.IdempotencyKeyHeader, "order-1")
+		rec := httptest.NewRecorder()
+
+		handle(rec, req, nil)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get("Idempotent-Replayed"))
+	}
+	mockService.AssertExpectations(t)
+}
+
+// TestAPIKeyRepository butuh database Postgres yang sudah dimigrasi, set
+// TEST_DATABASE_URL untuk menjalankannya
+func TestAPIKeyRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := repository.NewAPIKeyRepository(db)
+	key := addAPIKey(t, repo, &domain.APIKey{Name: "repository-test"})
+
+	found, err := repo.GetActiveByHash(ctx, auth.HashAPIKey(key))
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "repository-test", found.Name)
+	assert.Equal(t, domain.RolePlatformAdmin, found.Role)
+	assert.Nil(t, found.BrandID)
+
+	_, err = db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1`, found.ID)
+	require.NoError(t, err)
+	revoked, err := repo.GetActiveByHash(ctx, auth.HashAPIKey(key))
+	require.NoError(t, err)
+	assert.Nil(t, revoked)
+}
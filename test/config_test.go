@@ -0,0 +1,289 @@
+package test
+
+import (
+	"api-otto/internal/config"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	cfg, flags, err := config.Load(nil, envLookup(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, ":3000", cfg.Server.Addr)
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.KeyTTL)
+	assert.True(t, cfg.Features.Idempotency)
+	assert.False(t, flags.PrintConfig)
+}
+
+func TestConfig_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  addr: ":4000"
+  read_timeout: 5s
+database:
+  max_open_conns: 10
+log:
+  level: debug
+`)
+	env := map[string]string{
+		config.ConfigFileEnv: path,
+		"HTTP_ADDR":          ":5000",
+		"LOG_LEVEL":          "warn",
+	}
+
+	cfg, flags, err := config.Load([]string{"--log-level", "error", "serve"}, envLookup(env))
+
+	require.NoError(t, err)
+	// file menimpa default
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 10, cfg.Database.MaxOpenConns)
+	// environment menimpa file
+	assert.Equal(t, ":5000", cfg.Server.Addr)
+	// flag menimpa environment
+	assert.Equal(t, "error", cfg.Log.Level)
+	assert.Equal(t, path, flags.ConfigFile)
+	assert.Equal(t, []string{"serve"}, flags.Args)
+}
+
+func TestConfig_JSONFile(t *testing.T) {
+	path := writeConfigFile(t, "config.json", "{\n\t\"features\": {\"idempotency\": false},\n\t\"idempotency\": {\"key_ttl\": \"1h\"}\n}")
+
+	cfg, _, err := config.Load([]string{"--config", path}, envLookup(nil))
+
+	require.NoError(t, err)
+	assert.False(t, cfg.Features.Idempotency)
+	assert.Equal(t, time.Hour, cfg.Idempotency.KeyTTL)
+}
+
+func TestConfig_RouteTimeouts(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  route_timeouts:
+    GET /brand: 2s
+`)
+	env := map[string]string{
+		"HTTP_ROUTE_TIMEOUTS": "POST /transaction/redemption=5s, GET /voucher=1s",
+	}
+
+	cfg, _, err := config.Load([]string{"--config", path, "--route-timeout", "GET /voucher=3s"}, envLookup(env))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"GET /brand":                   2 * time.Second,
+		"POST /transaction/redemption": 5 * time.Second,
+		"GET /voucher":                 3 * time.Second,
+	}, cfg.Server.RouteTimeouts)
+	assert.Equal(t, 10*time.Second, cfg.Server.RequestTimeout)
+}
+
+func TestConfig_RateLimits(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+rate_limit:
+  default: 100/1m
+  routes:
+    GET /voucher: 50/30s
+`)
+	env := map[string]string{
+		"RATE_LIMIT_BACKEND": "postgres",
+		"RATE_LIMIT_ROUTES":  "POST /transaction/redemption=5/1m, GET /voucher=60/30s",
+		"RATE_LIMIT_PER_IP":  "500/1m",
+	}
+
+	cfg, _, err := config.Load([]string{"--config", path, "--route-rate-limit", "GET /brand=2000/1h"}, envLookup(env))
+
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.RateLimit.Backend)
+	assert.Equal(t, config.RateLimit{Limit: 100, Period: time.Minute}, cfg.RateLimit.Default)
+	assert.Equal(t, config.RateLimit{Limit: 500, Period: time.Minute}, cfg.RateLimit.PerIP)
+	// Route yang tidak disebut tetap memakai nilai default
+	assert.Equal(t, map[string]config.RateLimit{
+		"POST /transaction/redemption": {Limit: 5, Period: time.Minute},
+		"GET /brand":                   {Limit: 2000, Period: time.Hour},
+		"GET /voucher":                 {Limit: 60, Period: 30 * time.Second},
+	}, cfg.RateLimit.Routes)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+	assert.Contains(t, out.String(), "default: 100/1m\n")
+	assert.Contains(t, out.String(), "GET /brand: 2000/1h\n")
+	assert.Contains(t, out.String(), "GET /voucher: 60/30s\n")
+}
+
+func TestConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		file     string
+		expected []string
+	}{
+		{
+			name:     "Invalid Env Value",
+			env:      map[string]string{"DB_MAX_OPEN_CONNS": "many"},
+			expected: []string{`invalid DB_MAX_OPEN_CONNS="many"`},
+		},
+		{
+			name:     "Unknown File Field",
+			file:     "server:\n  adr: \":3000\"\n",
+			expected: []string{"field adr not found"},
+		},
+		{
+			name: "All Validation Errors Reported",
+			args: []string{"--addr", "3000", "--log-level", "verbose", "--db-dsn", "", "--default-language", "fr"},
+			expected: []string{
+				`server.addr "3000" must be host:port`,
+				"database.dsn is required",
+				`log.level "verbose" must be one of`,
+				`default_language "fr" must be one of`,
+			},
+		},
+		{
+			name:     "Idle Exceeds Open Connections",
+			args:     []string{"--db-max-open-conns", "5", "--db-max-idle-conns", "10"},
+			expected: []string{"database.max_idle_conns (10) must not exceed database.max_open_conns (5)"},
+		},
+		{
+			name: "Invalid Tracing",
+			env:  map[string]string{"TRACING_EXPORTER": "zipkin"},
+			args: []string{"--tracing-service-name", ""},
+			expected: []string{
+				`tracing.exporter "zipkin" must be one of none, stdout, file, otlp`,
+				"tracing.service_name is required",
+			},
+		},
+		{
+			name:     "Tracing File Required",
+			args:     []string{"--tracing-exporter", "file"},
+			expected: []string{"tracing.file is required when tracing.exporter is file"},
+		},
+		{
+			name:     "Short JWT Secret",
+			env:      map[string]string{"JWT_SECRET": "short"},
+			expected: []string{"auth.jwt_secret must be at least 32 bytes"},
+		},
+		{
+			name:     "RS256 Without Public Key",
+			args:     []string{"--jwt-algorithm", "RS256"},
+			expected: []string{"auth.jwt_public_key_file is required when auth.jwt_algorithm is RS256"},
+		},
+		{
+			name:     "Unsupported JWT Algorithm",
+			args:     []string{"--jwt-algorithm", "none"},
+			expected: []string{`auth.jwt_algorithm "none" must be HS256 or RS256`},
+		},
+		{
+			name:     "Invalid Route Timeout Entry",
+			env:      map[string]string{"HTTP_ROUTE_TIMEOUTS": "GET /brand"},
+			expected: []string{`invalid HTTP_ROUTE_TIMEOUTS="GET /brand": "GET /brand" must be key=duration`},
+		},
+		{
+			name: "Invalid Route Timeout Values",
+			args: []string{"--route-timeout", "/brand=1s", "--route-timeout", "GET /voucher=0s", "--request-timeout", "0s"},
+			expected: []string{
+				"server.request_timeout must be greater than 0",
+				`server.route_timeouts key "/brand" must look like`,
+				`server.route_timeouts["GET /voucher"] must be greater than 0`,
+			},
+		},
+		{
+			name:     "Unknown Storage",
+			env:      map[string]string{"STORAGE": "sqlite"},
+			expected: []string{`storage "sqlite" must be postgres or memory`},
+		},
+		{
+			name:     "Memory Storage With Postgres Rate Limit",
+			args:     []string{"--storage", "memory", "--rate-limit-enabled", "--rate-limit-backend", "postgres"},
+			expected: []string{"rate_limit.backend postgres needs storage postgres"},
+		},
+		{
+			name:     "Invalid Rate Limit Entry",
+			env:      map[string]string{"RATE_LIMIT_DEFAULT": "10"},
+			expected: []string{`invalid RATE_LIMIT_DEFAULT="10": "10" must be limit/period, e.g. "10/1m"`},
+		},
+		{
+			name: "Invalid Rate Limit Values",
+			args: []string{"--rate-limit-backend", "redis", "--rate-limit-default", "0/1m", "--rate-limit-per-ip", "10/0s", "--route-rate-limit", "/brand=10/0s"},
+			expected: []string{
+				`rate_limit.backend "redis" must be memory or postgres`,
+				"rate_limit.default limit must be greater than 0",
+				"rate_limit.per_ip period must be greater than 0",
+				`rate_limit.routes key "/brand" must look like`,
+				`rate_limit.routes["/brand"] period must be greater than 0`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"--config", writeConfigFile(t, "config.yaml", tt.file)}, args...)
+			}
+
+			_, _, err := config.Load(args, envLookup(tt.env))
+
+			require.Error(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestConfig_PrintRedactsSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      string
+		expected string
+	}{
+		{"URL DSN", "postgres://app:s3cret@db:5432/vouchers", "dsn: postgres://app:xxxxx@db:5432/vouchers"},
+		{"Key Value DSN", "host=db user=app password=s3cret dbname=vouchers", "dsn: host=db user=app password=xxxxx dbname=vouchers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := config.Load([]string{"--db-dsn", tt.dsn}, envLookup(nil))
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			require.NoError(t, cfg.Print(&out))
+
+			assert.Contains(t, out.String(), tt.expected)
+			assert.NotContains(t, out.String(), "s3cret")
+			assert.Equal(t, tt.dsn, cfg.Database.DSN)
+		})
+	}
+}
+
+func TestConfig_PrintRedactsJWTSecret(t *testing.T) {
+	secret := "jwt-secret-that-is-at-least-32-bytes"
+	cfg, _, err := config.Load(nil, envLookup(map[string]string{"JWT_SECRET": secret}))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	assert.Contains(t, out.String(), "jwt_secret: xxxxx")
+	assert.NotContains(t, out.String(), secret)
+	assert.Equal(t, secret, cfg.Auth.JWTSecret)
+}
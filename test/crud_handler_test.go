@@ -0,0 +1,331 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBrandHandler_Update(t *testing.T) {
+	window := 60
+
+	tests := []struct {
+		name           string
+		method         string
+		brandID        string
+		requestBody    string
+		mockBehavior   func(service *MockBrandService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Success Put Brand",
+			method:      http.MethodPut,
+			brandID:     "1",
+			requestBody: `{"name":"New Name","description":"New Description","cancellation_window_minutes":60}`,
+			mockBehavior: func(service *MockBrandService) {
+				service.On("Update", &domain.Brand{ID: 1, Name: "New Name", Description: "New Description", CancellationWindowMinutes: &window}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Brand updated successfully","data":{"id":1,"name":"New Name","description":"New Description","cancellation_window_minutes":60,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:        "Put Brand Not Found",
+			method:      http.MethodPut,
+			brandID:     "999",
+			requestBody: `{"name":"New Name"}`,
+			mockBehavior: func(service *MockBrandService) {
+				service.On("Update", mock.Anything).Return(domain.ErrBrandNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND"}`,
+		},
+		{
+			name:           "Put Brand Invalid Name",
+			method:         http.MethodPut,
+			brandID:        "1",
+			requestBody:    `{"name":"ab"}`,
+			mockBehavior:   func(service *MockBrandService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"name","rule":"min","message":"name must be at least 3 characters"}]}`,
+		},
+		{
+			name:        "Patch Keeps Other Fields",
+			method:      http.MethodPatch,
+			brandID:     "1",
+			requestBody: `{"description":"Patched"}`,
+			mockBehavior: func(service *MockBrandService) {
+				service.On("GetByID", int64(1)).Return(&domain.Brand{
+					ID:          1,
+					Name:        "Brand One",
+					Description: "Old",
+					CreatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					UpdatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				}, nil)
+				service.On("Update", mock.MatchedBy(func(brand *domain.Brand) bool {
+					return brand.Name == "Brand One" && brand.Description == "Patched"
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Brand updated successfully","data":{"id":1,"name":"Brand One","description":"Patched","created_at":"2024-03-01T00:00:00Z","updated_at":"2024-03-01T00:00:00Z"}}`,
+		},
+		{
+			name:        "Patch Brand Not Found",
+			method:      http.MethodPatch,
+			brandID:     "999",
+			requestBody: `{"description":"Patched"}`,
+			mockBehavior: func(service *MockBrandService) {
+				service.On("GetByID", int64(999)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBrandService)
+			tt.mockBehavior(mockService)
+			h := handler.NewBrandHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, "/brand/"+tt.brandID, bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.brandID}}
+
+			if tt.method == http.MethodPatch {
+				h.Patch(rec, req, params)
+			} else {
+				h.Update(rec, req, params)
+			}
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBrandHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		brandID        string
+		mockBehavior   func(service *MockBrandService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "Success Delete Brand",
+			brandID: "1",
+			mockBehavior: func(service *MockBrandService) {
+				service.On("Delete", int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Brand deleted successfully"}`,
+		},
+		{
+			name:    "Brand Not Found",
+			brandID: "999",
+			mockBehavior: func(service *MockBrandService) {
+				service.On("Delete", int64(999)).Return(domain.ErrBrandNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND"}`,
+		},
+		{
+			name:    "Brand Still Has Vouchers",
+			brandID: "2",
+			mockBehavior: func(service *MockBrandService) {
+				service.On("Delete", int64(2)).Return(domain.ErrBrandHasVouchers)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"message":"brand still has vouchers","error_code":"BRAND_HAS_VOUCHERS"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBrandService)
+			tt.mockBehavior(mockService)
+			h := handler.NewBrandHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodDelete, "/brand/"+tt.brandID, nil)
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.brandID}}
+
+			h.Delete(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVoucherHandler_UpdateAndDelete(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		voucherID      string
+		requestBody    string
+		mockBehavior   func(service *MockVoucherService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Put Voucher Not Found",
+			method:      http.MethodPut,
+			voucherID:   "999",
+			requestBody: `{"brand_id":1,"code":"V100","name":"Voucher","points":100,"valid_until":"2030-12-31T23:59:59Z"}`,
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("GetForUpdate", int64(999)).Return(nil, domain.ErrVoucherNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"voucher not found","error_code":"VOUCHER_NOT_FOUND"}`,
+		},
+		{
+			name:        "Put Voucher Unknown Brand",
+			method:      http.MethodPut,
+			voucherID:   "1",
+			requestBody: `{"brand_id":999,"code":"V100","name":"Voucher","points":100,"valid_until":"2030-12-31T23:59:59Z"}`,
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("GetForUpdate", int64(1)).Return(&domain.Voucher{ID: 1, BrandID: 1, Code: "V100"}, nil)
+				service.On("Update", mock.Anything).Return(domain.ErrBrandNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND"}`,
+		},
+		{
+			name:        "Put Expired Voucher With Legacy Code",
+			method:      http.MethodPut,
+			voucherID:   "1",
+			requestBody: `{"brand_id":1,"code":"v1","name":"Renamed","points":100,"valid_until":"2020-12-31T23:59:59Z","total_stock":5}`,
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("GetForUpdate", int64(1)).Return(&domain.Voucher{ID: 1, BrandID: 1, Code: "v1"}, nil)
+				service.On("Update", mock.MatchedBy(func(voucher *domain.Voucher) bool {
+					return voucher.Name == "Renamed" && voucher.Code == "v1"
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Voucher updated successfully","data":{"id":1,"brand_id":1,"code":"v1","name":"Renamed","description":"","points":100,"valid_until":"2020-12-31T23:59:59Z","total_stock":5,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:        "Put Changes Code To Invalid Code",
+			method:      http.MethodPut,
+			voucherID:   "1",
+			requestBody: `{"brand_id":1,"code":"v2","name":"Renamed","points":100,"valid_until":"2020-12-31T23:59:59Z"}`,
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("GetForUpdate", int64(1)).Return(&domain.Voucher{ID: 1, BrandID: 1, Code: "v1"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"code","rule":"voucher_code","message":"code must be 3-50 uppercase letters, digits, '-' or '_'"}]}`,
+		},
+		{
+			name:        "Put Missing Valid Until",
+			method:      http.MethodPut,
+			voucherID:   "1",
+			requestBody: `{"brand_id":1,"code":"v1","name":"Renamed","points":100}`,
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("GetForUpdate", int64(1)).Return(&domain.Voucher{ID: 1, BrandID: 1, Code: "v1"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"valid_until","rule":"required","message":"valid_until is required"}]}`,
+		},
+		{
+			name:      "Delete Voucher Not Found",
+			method:    http.MethodDelete,
+			voucherID: "999",
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("Delete", int64(999)).Return(domain.ErrVoucherNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"voucher not found","error_code":"VOUCHER_NOT_FOUND"}`,
+		},
+		{
+			name:      "Delete Redeemed Voucher",
+			method:    http.MethodDelete,
+			voucherID: "1",
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("Delete", int64(1)).Return(domain.ErrVoucherRedeemed)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"message":"voucher has already been redeemed","error_code":"VOUCHER_REDEEMED"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockVoucherService)
+			tt.mockBehavior(mockService)
+			h := handler.NewVoucherHandler(mockService)
+
+			req := httptest.NewRequest(tt.method, "/voucher/"+tt.voucherID, bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.voucherID}}
+
+			if tt.method == http.MethodDelete {
+				h.Delete(rec, req, params)
+			} else {
+				h.Update(rec, req, params)
+			}
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVoucherHandler_List(t *testing.T) {
+	mockService := new(MockVoucherService)
+	mockService.On("List", domain.VoucherFilter{}).Return([]domain.Voucher{
+		{
+			ID:         1,
+			BrandID:    1,
+			Code:       "VOUCHER123",
+			Name:       "Test Voucher",
+			Points:     50000,
+			ValidUntil: time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+			CreatedAt:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, "", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/voucher", nil)
+	rec := httptest.NewRecorder()
+	handler.NewVoucherHandler(mockService).List(rec, req, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":200,"message":"Success","data":[{"id":1,"brand_id":1,"code":"VOUCHER123","name":"Test Voucher","description":"","points":50000,"valid_until":"2024-12-31T23:59:59Z","created_at":"2024-03-01T00:00:00Z","updated_at":"2024-03-01T00:00:00Z"}]}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_GetCustomerTransactions(t *testing.T) {
+	mockService := new(MockTransactionService)
+	mockService.On("GetCustomerTransactions", int64(7), domain.TransactionFilter{}).Return([]domain.Transaction{
+		{
+			ID:          3,
+			CustomerID:  7,
+			TotalPoints: 100,
+			Status:      domain.TransactionStatusCompleted,
+			Items:       []domain.TransactionItem{},
+			CreatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, "", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/customer/7/transactions", nil)
+	rec := httptest.NewRecorder()
+	handler.NewTransactionHandler(mockService).GetCustomerTransactions(rec, req, httprouter.Params{httprouter.Param{Key: "id", Value: "7"}})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":200,"message":"Success","data":[{"id":3,"customer_id":7,"total_points":100,"status":"completed","items":[],"created_at":"2024-03-01T00:00:00Z","updated_at":"2024-03-01T00:00:00Z"}]}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomerService struct {
+	mock.Mock
+}
+
+func (m *MockCustomerService) Create(ctx context.Context, customer *domain.Customer) error {
+	args := m.Called(customer)
+	return args.Error(0)
+}
+
+func (m *MockCustomerService) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) CreditPoints(ctx context.Context, entry *domain.PointsLedgerEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockCustomerService) GetLedger(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]domain.PointsLedgerEntry), args.Error(1)
+}
+
+func TestCustomerHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockBehavior   func(service *MockCustomerService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Success Create Customer",
+			requestBody: domain.Customer{Name: "Budi", Email: "budi@example.com"},
+			mockBehavior: func(service *MockCustomerService) {
+				service.On("Create", mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: `{
+				"status": 201,
+				"message": "Customer created successfully",
+				"data": {
+					"id": 0,
+					"name": "Budi",
+					"email": "budi@example.com",
+					"points_balance": 0,
+					"created_at": "0001-01-01T00:00:00Z",
+					"updated_at": "0001-01-01T00:00:00Z"
+				}
+			}`,
+		},
+		{
+			name:           "Invalid Request - Bad Email",
+			requestBody:    domain.Customer{Name: "Budi", Email: "not-an-email"},
+			mockBehavior:   func(service *MockCustomerService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"email","rule":"email","message":"email must be a valid email address"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCustomerService)
+			tt.mockBehavior(mockService)
+			handler := handler.NewCustomerHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/customer", bytes.NewBuffer(body))
+			rec := httptest.NewRecorder()
+
+			handler.Create(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCustomerHandler_GetByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		customerID     string
+		mockBehavior   func(service *MockCustomerService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "Success Get Customer With Balance",
+			customerID: "1",
+			mockBehavior: func(service *MockCustomerService) {
+				service.On("GetByID", int64(1)).Return(&domain.Customer{
+					ID:            1,
+					Name:          "Budi",
+					PointsBalance: 120000,
+					CreatedAt:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					UpdatedAt:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Success","data":{"id":1,"name":"Budi","email":"","points_balance":120000,"created_at":"2024-03-01T00:00:00Z","updated_at":"2024-03-01T00:00:00Z"}}`,
+		},
+		{
+			name:       "Customer Not Found",
+			customerID: "999",
+			mockBehavior: func(service *MockCustomerService) {
+				service.On("GetByID", int64(999)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"customer not found","error_code":"CUSTOMER_NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCustomerService)
+			tt.mockBehavior(mockService)
+			handler := handler.NewCustomerHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/customer/"+tt.customerID, nil)
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.customerID}}
+
+			handler.GetByID(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCustomerHandler_CreditPoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		customerID     string
+		requestBody    string
+		mockBehavior   func(service *MockCustomerService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "Success Credit Points",
+			customerID:  "1",
+			requestBody: `{"points":50000,"reason":"top_up"}`,
+			mockBehavior: func(service *MockCustomerService) {
+				service.On("CreditPoints", mock.MatchedBy(func(entry *domain.PointsLedgerEntry) bool {
+					return entry.CustomerID == 1 && entry.Points == 50000
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"status":201,"message":"Points credited successfully","data":{"id":0,"customer_id":1,"entry_type":"","points":50000,"reason":"top_up","created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			name:           "Invalid Request - Zero Points",
+			customerID:     "1",
+			requestBody:    `{"points":0,"reason":"top_up"}`,
+			mockBehavior:   func(service *MockCustomerService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"points","rule":"required","message":"points is required"}]}`,
+		},
+		{
+			name:        "Customer Not Found",
+			customerID:  "999",
+			requestBody: `{"points":100,"reason":"top_up"}`,
+			mockBehavior: func(service *MockCustomerService) {
+				service.On("CreditPoints", mock.Anything).Return(domain.ErrCustomerNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"customer not found","error_code":"CUSTOMER_NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCustomerService)
+			tt.mockBehavior(mockService)
+			handler := handler.NewCustomerHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/customer/"+tt.customerID+"/points", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.customerID}}
+
+			handler.CreditPoints(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
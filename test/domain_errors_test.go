@@ -0,0 +1,82 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDomainErrors_Kinds(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+		code string
+	}{
+		{"Brand Not Found", domain.ErrBrandNotFound, domain.ErrNotFound, "BRAND_NOT_FOUND"},
+		{"Wrapped Voucher Expired", fmt.Errorf("%w: voucher 3", domain.ErrVoucherExpired), domain.ErrExpired, "VOUCHER_EXPIRED"},
+		{"Voucher Code Exists", domain.ErrVoucherCodeExists, domain.ErrConflict, "VOUCHER_CODE_EXISTS"},
+		{"Insufficient Points", domain.ErrInsufficientPoints, domain.ErrInsufficientBalance, "INSUFFICIENT_POINTS"},
+		{"Invalid Transition", &domain.InvalidTransitionError{From: domain.TransactionStatusFailed, To: domain.TransactionStatusCompleted}, domain.ErrConflict, "INVALID_STATUS_TRANSITION"},
+		{"Plain Error", errors.New("boom"), nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.kind != nil {
+				assert.ErrorIs(t, tt.err, tt.kind)
+			}
+			assert.Equal(t, tt.code, domain.ErrorCode(tt.err))
+		})
+	}
+}
+
+func TestTransactionHandler_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Invalid Transition Is Conflict",
+			serviceErr:     &domain.InvalidTransitionError{From: domain.TransactionStatusRefunded, To: domain.TransactionStatusRefunded},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"message":"invalid transaction status transition: refunded to refunded","error_code":"INVALID_STATUS_TRANSITION"}`,
+		},
+		{
+			name:           "Database Error Is Not Leaked",
+			serviceErr:     errors.New(`pq: relation "transaction_refunds" does not exist`),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"status":500,"message":"Internal server error","error_code":"INTERNAL_ERROR"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			mockService.On("RefundItem", int64(1), int64(2), mock.Anything).Return(nil, tt.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/transaction/redemption/1/items/2/refund", bytes.NewBufferString(`{"reason":"wrong voucher"}`))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{
+				httprouter.Param{Key: "id", Value: "1"},
+				httprouter.Param{Key: "item_id", Value: "2"},
+			}
+			handler.NewTransactionHandler(mockService).RefundItem(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"api-otto/internal/domain"
 	"api-otto/internal/handler"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -22,8 +24,9 @@ type MockBrandService struct {
 }
 
 // Update implements domain.BrandService.
-func (m *MockBrandService) Update(brand *domain.Brand) error {
-	panic("unimplemented")
+func (m *MockBrandService) Update(ctx context.Context, brand *domain.Brand) error {
+	args := m.Called(brand)
+	return args.Error(0)
 }
 
 type MockVoucherService struct {
@@ -31,8 +34,9 @@ type MockVoucherService struct {
 }
 
 // Update implements domain.VoucherService.
-func (m *MockVoucherService) Update(voucher *domain.Voucher) error {
-	panic("unimplemented")
+func (m *MockVoucherService) Update(ctx context.Context, voucher *domain.Voucher) error {
+	args := m.Called(voucher)
+	return args.Error(0)
 }
 
 type MockTransactionService struct {
@@ -40,17 +44,18 @@ type MockTransactionService struct {
 }
 
 // GetCustomerTransactions implements domain.TransactionService.
-func (m *MockTransactionService) GetCustomerTransactions(customerID int64) ([]domain.Transaction, error) {
-	panic("unimplemented")
+func (m *MockTransactionService) GetCustomerTransactions(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+	args := m.Called(customerID, filter)
+	return args.Get(0).([]domain.Transaction), args.String(1), args.Error(2)
 }
 
 // Brand Service Mock Methods
-func (m *MockBrandService) Create(brand *domain.Brand) error {
+func (m *MockBrandService) Create(ctx context.Context, brand *domain.Brand) error {
 	args := m.Called(brand)
 	return args.Error(0)
 }
 
-func (m *MockBrandService) GetByID(id int64) (*domain.Brand, error) {
+func (m *MockBrandService) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -58,28 +63,28 @@ func (m *MockBrandService) GetByID(id int64) (*domain.Brand, error) {
 	return args.Get(0).(*domain.Brand), args.Error(1)
 }
 
-func (m *MockBrandService) GetAll() ([]domain.Brand, error) {
+func (m *MockBrandService) GetAll(ctx context.Context) ([]domain.Brand, error) {
 	args := m.Called()
 	return args.Get(0).([]domain.Brand), args.Error(1)
 }
 
-func (m *MockBrandService) Delete(id int64) error {
+func (m *MockBrandService) Delete(ctx context.Context, id int64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockBrandService) List() ([]domain.Brand, error) {
-	args := m.Called()
-	return args.Get(0).([]domain.Brand), args.Error(1)
+func (m *MockBrandService) List(ctx context.Context, filter domain.BrandFilter) ([]domain.Brand, string, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Brand), args.String(1), args.Error(2)
 }
 
 // Voucher Service Mock Methods
-func (m *MockVoucherService) Create(voucher *domain.Voucher) error {
+func (m *MockVoucherService) Create(ctx context.Context, voucher *domain.Voucher) error {
 	args := m.Called(voucher)
 	return args.Error(0)
 }
 
-func (m *MockVoucherService) GetByID(id int64) (*domain.Voucher, error) {
+func (m *MockVoucherService) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -87,28 +92,36 @@ func (m *MockVoucherService) GetByID(id int64) (*domain.Voucher, error) {
 	return args.Get(0).(*domain.Voucher), args.Error(1)
 }
 
-func (m *MockVoucherService) GetByBrandID(brandID int64) ([]domain.Voucher, error) {
-	args := m.Called(brandID)
-	return args.Get(0).([]domain.Voucher), args.Error(1)
+func (m *MockVoucherService) GetForUpdate(ctx context.Context, id int64) (*domain.Voucher, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Voucher), args.Error(1)
 }
 
-func (m *MockVoucherService) Delete(id int64) error {
+func (m *MockVoucherService) GetByBrandID(ctx context.Context, brandID int64, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+	args := m.Called(brandID, filter)
+	return args.Get(0).([]domain.Voucher), args.String(1), args.Error(2)
+}
+
+func (m *MockVoucherService) Delete(ctx context.Context, id int64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockVoucherService) List() ([]domain.Voucher, error) {
-	args := m.Called()
-	return args.Get(0).([]domain.Voucher), args.Error(1)
+func (m *MockVoucherService) List(ctx context.Context, filter domain.VoucherFilter) ([]domain.Voucher, string, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.Voucher), args.String(1), args.Error(2)
 }
 
 // Transaction Service Mock Methods
-func (m *MockTransactionService) CreateRedemption(transaction *domain.Transaction) error {
+func (m *MockTransactionService) CreateRedemption(ctx context.Context, transaction *domain.Transaction) error {
 	args := m.Called(transaction)
 	return args.Error(0)
 }
 
-func (m *MockTransactionService) GetTransactionByID(id int64) (*domain.Transaction, error) {
+func (m *MockTransactionService) GetTransactionByID(ctx context.Context, id int64) (*domain.Transaction, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -116,6 +129,22 @@ func (m *MockTransactionService) GetTransactionByID(id int64) (*domain.Transacti
 	return args.Get(0).(*domain.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionService) CancelRedemption(ctx context.Context, id int64, request domain.RefundRequest) (*domain.Transaction, error) {
+	args := m.Called(id, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) RefundItem(ctx context.Context, id int64, itemID int64, request domain.RefundRequest) (*domain.Transaction, error) {
+	args := m.Called(id, itemID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
 // Brand Handler Tests
 func TestBrandHandler_Create(t *testing.T) {
 	tests := []struct {
@@ -154,7 +183,7 @@ func TestBrandHandler_Create(t *testing.T) {
 			},
 			mockBehavior:   func(service *MockBrandService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"status":400,"message":"Nama brand tidak boleh kosong"}`,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"name","rule":"required","message":"name is required"}]}`,
 		},
 	}
 
@@ -207,21 +236,21 @@ func TestBrandHandler_GetByID(t *testing.T) {
 				service.On("GetByID", int64(999)).Return(nil, nil)
 			},
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"status":404,"message":"Brand not found"}`,
+			expectedBody:   `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND"}`,
 		},
 		{
 			name:           "Invalid ID Format",
 			brandID:        "abc",
 			mockBehavior:   func(service *MockBrandService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"status":400,"message":"Invalid ID"}`,
+			expectedBody:   `{"status":400,"message":"Invalid ID","error_code":"BAD_REQUEST"}`,
 		},
 		{
 			name:           "Empty ID",
 			brandID:        "",
 			mockBehavior:   func(service *MockBrandService) {},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"status":400,"message":"Invalid ID"}`,
+			expectedBody:   `{"status":400,"message":"Invalid ID","error_code":"BAD_REQUEST"}`,
 		},
 	}
 
@@ -255,7 +284,7 @@ func TestBrandHandler_GetAll(t *testing.T) {
 		{
 			name: "Success Get All Brands",
 			mockBehavior: func(service *MockBrandService) {
-				service.On("List").Return([]domain.Brand{
+				service.On("List", domain.BrandFilter{}).Return([]domain.Brand{
 					{
 						ID:          1,
 						Name:        "Brand One",
@@ -270,7 +299,7 @@ func TestBrandHandler_GetAll(t *testing.T) {
 						CreatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
 						UpdatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
 					},
-				}, nil)
+				}, "", nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: `{
@@ -297,7 +326,7 @@ func TestBrandHandler_GetAll(t *testing.T) {
 		{
 			name: "Empty Brand List",
 			mockBehavior: func(service *MockBrandService) {
-				service.On("List").Return([]domain.Brand{}, nil)
+				service.On("List", domain.BrandFilter{}).Return([]domain.Brand{}, "", nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: `{
@@ -309,12 +338,13 @@ func TestBrandHandler_GetAll(t *testing.T) {
 		{
 			name: "Internal Server Error",
 			mockBehavior: func(service *MockBrandService) {
-				service.On("List").Return([]domain.Brand{}, errors.New("database error"))
+				service.On("List", domain.BrandFilter{}).Return([]domain.Brand{}, "", errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody: `{
 				"status": 500,
-				"message": "database error"
+				"message": "Internal server error",
+				"error_code": "INTERNAL_ERROR"
 			}`,
 		},
 	}
@@ -339,7 +369,7 @@ func TestBrandHandler_GetAll(t *testing.T) {
 
 // Voucher Handler Tests
 func TestVoucherHandler_Create(t *testing.T) {
-	validUntil := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	validUntil := time.Date(2030, 12, 31, 23, 59, 59, 0, time.UTC)
 
 	tests := []struct {
 		name           string
@@ -361,7 +391,7 @@ func TestVoucherHandler_Create(t *testing.T) {
 				service.On("Create", mock.Anything).Return(nil)
 			},
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `{"status":201,"message":"Voucher created successfully","data":{"id":0,"brand_id":1,"code":"VOUCHER123","name":"Test Voucher","description":"","points":50000,"valid_until":"2024-12-31T23:59:59Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}`,
+			expectedBody:   `{"status":201,"message":"Voucher created successfully","data":{"id":0,"brand_id":1,"code":"VOUCHER123","name":"Test Voucher","description":"","points":50000,"valid_until":"2030-12-31T23:59:59Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}`,
 		},
 		{
 			name: "Invalid Request - Empty Name",
@@ -372,11 +402,9 @@ func TestVoucherHandler_Create(t *testing.T) {
 				ValidUntil: validUntil,
 				// Name dikosongkan untuk memicu error
 			},
-			mockBehavior: func(service *MockVoucherService) {
-				service.On("Create", mock.Anything).Return(errors.New("Nama voucher tidak boleh kosong"))
-			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"status":500,"message":"Nama voucher tidak boleh kosong"}`,
+			mockBehavior:   func(service *MockVoucherService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"name","rule":"required","message":"name is required"}]}`,
 		},
 	}
 
@@ -452,7 +480,8 @@ func TestVoucherHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 			expectedBody: `{
 				"status": 404,
-				"message": "Voucher not found"
+				"message": "voucher not found",
+				"error_code": "VOUCHER_NOT_FOUND"
 			}`,
 		},
 		{
@@ -462,7 +491,8 @@ func TestVoucherHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "Invalid ID"
+				"message": "Invalid ID",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 		{
@@ -472,7 +502,8 @@ func TestVoucherHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "ID is required"
+				"message": "ID is required",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 	}
@@ -510,7 +541,7 @@ func TestVoucherHandler_GetByBrandID(t *testing.T) {
 			name:    "Success Get Vouchers By Brand ID",
 			brandID: "1",
 			mockBehavior: func(service *MockVoucherService) {
-				service.On("GetByBrandID", int64(1)).Return([]domain.Voucher{
+				service.On("GetByBrandID", int64(1), domain.VoucherFilter{}).Return([]domain.Voucher{
 					{
 						ID:          1,
 						BrandID:     1,
@@ -533,7 +564,7 @@ func TestVoucherHandler_GetByBrandID(t *testing.T) {
 						CreatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
 						UpdatedAt:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
 					},
-				}, nil)
+				}, "", nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: `{
@@ -569,24 +600,26 @@ func TestVoucherHandler_GetByBrandID(t *testing.T) {
 			name:    "Brand Not Found",
 			brandID: "999",
 			mockBehavior: func(service *MockVoucherService) {
-				service.On("GetByBrandID", int64(999)).Return([]domain.Voucher{}, errors.New("brand not found"))
+				service.On("GetByBrandID", int64(999), domain.VoucherFilter{}).Return([]domain.Voucher{}, "", domain.ErrBrandNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: `{
 				"status": 404,
-				"message": "Brand not found"
+				"message": "brand not found",
+				"error_code": "BRAND_NOT_FOUND"
 			}`,
 		},
 		{
 			name:    "No Vouchers Found",
 			brandID: "2",
 			mockBehavior: func(service *MockVoucherService) {
-				service.On("GetByBrandID", int64(2)).Return([]domain.Voucher{}, nil)
+				service.On("GetByBrandID", int64(2), domain.VoucherFilter{}).Return([]domain.Voucher{}, "", nil)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody: `{
 				"status": 404,
-				"message": "Brand tidak memiliki voucher"
+				"message": "Brand has no vouchers",
+				"error_code": "NOT_FOUND"
 			}`,
 		},
 		{
@@ -596,7 +629,8 @@ func TestVoucherHandler_GetByBrandID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "Invalid brand ID"
+				"message": "Invalid brand ID",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 		{
@@ -606,7 +640,8 @@ func TestVoucherHandler_GetByBrandID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "Brand ID is required"
+				"message": "Brand ID is required",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 	}
@@ -698,13 +733,15 @@ func TestTransactionHandler_CreateRedemption(t *testing.T) {
 					},
 				},
 			},
-			mockBehavior: func(service *MockTransactionService) {
-				service.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Return(errors.New("customer ID is required"))
-			},
-			expectedStatus: http.StatusInternalServerError,
+			mockBehavior:   func(service *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
-				"status": 500,
-				"message": "customer ID is required"
+				"status": 400,
+				"message": "validation failed",
+				"error_code": "VALIDATION_FAILED",
+				"errors": [
+					{"field": "customer_id", "rule": "required", "message": "customer_id is required"}
+				]
 			}`,
 		},
 		{
@@ -714,13 +751,75 @@ func TestTransactionHandler_CreateRedemption(t *testing.T) {
 				TotalPoints: 50000,
 				Items:      []domain.TransactionItem{},
 			},
+			mockBehavior:   func(service *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{
+				"status": 400,
+				"message": "validation failed",
+				"error_code": "VALIDATION_FAILED",
+				"errors": [
+					{"field": "items", "rule": "min", "message": "items must contain at least 1 item(s)"}
+				]
+			}`,
+		},
+		{
+			name: "Voucher Expired",
+			requestBody: domain.Transaction{
+				CustomerID: 1,
+				Items: []domain.TransactionItem{
+					{
+						VoucherID: 3,
+					},
+				},
+			},
 			mockBehavior: func(service *MockTransactionService) {
-				service.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Return(errors.New("transaction items are required"))
+				service.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Return(fmt.Errorf("%w: voucher 3", domain.ErrVoucherExpired))
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody: `{
-				"status": 500,
-				"message": "transaction items are required"
+				"status": 422,
+				"message": "voucher has expired: voucher 3",
+				"error_code": "VOUCHER_EXPIRED"
+			}`,
+		},
+		{
+			name: "Voucher Sold Out",
+			requestBody: domain.Transaction{
+				CustomerID: 1,
+				Items: []domain.TransactionItem{
+					{
+						VoucherID: 1,
+					},
+				},
+			},
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Return(fmt.Errorf("%w: voucher 1", domain.ErrVoucherSoldOut))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody: `{
+				"status": 409,
+				"message": "voucher sold out: voucher 1",
+				"error_code": "VOUCHER_SOLD_OUT"
+			}`,
+		},
+		{
+			name: "Insufficient Points",
+			requestBody: domain.Transaction{
+				CustomerID: 1,
+				Items: []domain.TransactionItem{
+					{
+						VoucherID: 1,
+					},
+				},
+			},
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Return(fmt.Errorf("%w: balance 100, required 50000", domain.ErrInsufficientPoints))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: `{
+				"status": 422,
+				"message": "insufficient points: balance 100, required 50000",
+				"error_code": "INSUFFICIENT_POINTS"
 			}`,
 		},
 	}
@@ -821,7 +920,9 @@ func TestTransactionHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 			expectedBody: `{
 				"status": 404,
-				"message": "Transaction not found"
+				"message": "transaction not found",
+				"error_code": "TRANSACTION_NOT_FOUND",
+				"error_code": "TRANSACTION_NOT_FOUND"
 			}`,
 		},
 		{
@@ -831,7 +932,8 @@ func TestTransactionHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "Invalid transaction ID"
+				"message": "Invalid transaction ID",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 		{
@@ -841,7 +943,8 @@ func TestTransactionHandler_GetByID(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: `{
 				"status": 400,
-				"message": "Transaction ID is required"
+				"message": "Transaction ID is required",
+				"error_code": "BAD_REQUEST"
 			}`,
 		},
 	}
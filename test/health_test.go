@@ -0,0 +1,152 @@
+package test
+
+import (
+	"api-otto/internal/buildinfo"
+	"api-otto/internal/handler"
+	"api-otto/internal/health"
+	"api-otto/internal/migrate"
+	"api-otto/migrations"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticCheck(name string, err error) health.Check {
+	return health.Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			return err
+		},
+	}
+}
+
+func TestHealthHandler_Readyz(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []health.Check
+		draining       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Ready",
+			checks:         []health.Check{staticCheck("database", nil), staticCheck("migrations", nil)},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Success","data":{"status":"ok","checks":{"database":"ok","migrations":"ok"}}}`,
+		},
+		{
+			name:           "Check Failing",
+			checks:         []health.Check{staticCheck("database", nil), staticCheck("migrations", errors.New("version 1, latest is 2 (1 pending)"))},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":503,"message":"Service is not ready","error_code":"NOT_READY","data":{"status":"failing","checks":{"database":"ok","migrations":"version 1, latest is 2 (1 pending)"}}}`,
+		},
+		{
+			name:           "Draining",
+			checks:         []health.Check{staticCheck("database", nil)},
+			draining:       true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":503,"message":"Service is not ready","error_code":"NOT_READY","data":{"status":"draining"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := health.NewProbe(time.Second, tt.checks...)
+			if tt.draining {
+				probe.SetDraining()
+			}
+			healthHandler := handler.NewHealthHandler(probe)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+
+			healthHandler.Readyz(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHealthHandler_HealthzIgnoresChecks(t *testing.T) {
+	probe := health.NewProbe(time.Second, staticCheck("database", errors.New("connection refused")))
+	probe.SetDraining()
+	healthHandler := handler.NewHealthHandler(probe)
+
+	rec := httptest.NewRecorder()
+	healthHandler.Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil), nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":200,"message":"Success","data":{"status":"ok"}}`, rec.Body.String())
+}
+
+func TestHealthHandler_Version(t *testing.T) {
+	commit, buildTime := buildinfo.Commit, buildinfo.BuildTime
+	buildinfo.Commit, buildinfo.BuildTime = "abc123", "2026-01-02T03:04:05Z"
+	defer func() { buildinfo.Commit, buildinfo.BuildTime = commit, buildTime }()
+
+	rec := httptest.NewRecorder()
+	handler.NewHealthHandler(health.NewProbe(time.Second)).Version(rec, httptest.NewRequest(http.MethodGet, "/version", nil), nil)
+
+	var resp struct {
+		Data buildinfo.Info `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, buildinfo.Info{Commit: "abc123", BuildTime: "2026-01-02T03:04:05Z", GoVersion: runtime.Version()}, resp.Data)
+}
+
+func TestMigrations_Latest(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, uint64(20250313090000), loaded[len(loaded)-1].Version)
+}
+
+// TestHealth_MigrationsCheck butuh database Postgres, set TEST_DATABASE_URL
+// untuk menjalankannya. Skema dibuat di schema terpisah.
+func TestHealth_MigrationsCheck(t *testing.T) {
+	db := isolatedSchema(t)
+	ctx := context.Background()
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	latest := migrator.Latest()
+	check := health.Migrations(migrator)
+
+	pending, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	assert.EqualError(t, check.Run(ctx), fmt.Sprintf("version 0, latest is %d (%d pending)", latest, len(pending)))
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, check.Run(ctx))
+
+	// Skema lebih baru dari binary, misalnya setelah rollback deploy
+	first := pending[0]
+	older, err := migrate.New(db, fstest.MapFS{
+		first.String() + ".up.sql":   &fstest.MapFile{Data: []byte("SELECT 1;")},
+		first.String() + ".down.sql": &fstest.MapFile{Data: []byte("SELECT 1;")},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, health.Migrations(older).Run(ctx))
+
+	_, err = db.Exec("UPDATE schema_migrations SET dirty = true")
+	require.NoError(t, err)
+	assert.EqualError(t, check.Run(ctx), fmt.Sprintf("version %d is dirty", latest))
+
+	// golang-migrate mencatat versi 0 yang dirty sebagai -1
+	_, err = db.Exec("UPDATE schema_migrations SET version = -1")
+	require.NoError(t, err)
+	assert.EqualError(t, check.Run(ctx), "version 0 is dirty")
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/i18n"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestI18n_Negotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		fallback string
+		expected string
+	}{
+		{"Empty Header", "", i18n.English, i18n.English},
+		{"Empty Header Indonesian Default", "", i18n.Indonesian, i18n.Indonesian},
+		{"Region Subtag", "id-ID", i18n.English, i18n.Indonesian},
+		{"Quality Order", "en;q=0.5, id;q=0.9", i18n.English, i18n.Indonesian},
+		{"Unsupported First", "fr-FR, en-US;q=0.8", i18n.Indonesian, i18n.English},
+		{"Only Unsupported", "fr, de", i18n.Indonesian, i18n.Indonesian},
+		{"Wildcard", "*", i18n.Indonesian, i18n.Indonesian},
+		{"Zero Quality", "id;q=0", i18n.English, i18n.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, i18n.Negotiate(tt.header, tt.fallback))
+		})
+	}
+}
+
+// Setiap pesan harus tersedia di semua bahasa
+func TestI18n_BundlesHaveSameKeys(t *testing.T) {
+	keys := []string{
+		"success", "invalid_request_body", "internal_error", "brand_created", "brand_has_no_voucher",
+		"BRAND_NOT_FOUND", "VOUCHER_EXPIRED", "INSUFFICIENT_POINTS", "VALIDATION_FAILED", "INVALID_QUERY_PARAMETER",
+	}
+	for _, lang := range i18n.Languages() {
+		for _, key := range keys {
+			_, ok := i18n.Lookup(lang, key)
+			assert.True(t, ok, "%s: missing %s", lang, key)
+		}
+	}
+	assert.Equal(t, "Brand tidak memiliki voucher", i18n.T(i18n.Indonesian, "brand_has_no_voucher"))
+	assert.Equal(t, "unknown_key", i18n.T(i18n.Indonesian, "unknown_key"))
+}
+
+func TestI18n_HandlerMessages(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		defaultLang    string
+		method         string
+		url            string
+		body           string
+		mockBehavior   func(brands *MockBrandService, transactions *MockTransactionService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Not Found In Indonesian",
+			acceptLanguage: "id-ID,id;q=0.9,en;q=0.8",
+			defaultLang:    i18n.English,
+			method:         http.MethodGet,
+			url:            "/brand/1",
+			mockBehavior: func(brands *MockBrandService, _ *MockTransactionService) {
+				brands.On("GetByID", int64(1)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"brand tidak ditemukan","error_code":"BRAND_NOT_FOUND"}`,
+		},
+		{
+			name:           "Configured Default Language",
+			defaultLang:    i18n.Indonesian,
+			method:         http.MethodGet,
+			url:            "/brand/abc",
+			mockBehavior:   func(*MockBrandService, *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"ID tidak valid","error_code":"BAD_REQUEST"}`,
+		},
+		{
+			name:           "Validation Errors In Indonesian",
+			acceptLanguage: "id",
+			defaultLang:    i18n.English,
+			method:         http.MethodPost,
+			url:            "/brand",
+			body:           `{"name":"ab"}`,
+			mockBehavior:   func(*MockBrandService, *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validasi gagal","error_code":"VALIDATION_FAILED","errors":[{"field":"name","rule":"min","message":"name minimal 3 karakter"}]}`,
+		},
+		{
+			name:           "Service Error Keeps Detail",
+			acceptLanguage: "id",
+			defaultLang:    i18n.English,
+			method:         http.MethodPost,
+			url:            "/transaction/redemption",
+			body:           `{"customer_id":1,"items":[{"voucher_id":3}]}`,
+			mockBehavior: func(_ *MockBrandService, transactions *MockTransactionService) {
+				transactions.On("CreateRedemption", mock.Anything).Return(fmt.Errorf("%w: voucher 3", domain.ErrVoucherExpired))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"status":422,"message":"voucher sudah kadaluarsa: voucher 3","error_code":"VOUCHER_EXPIRED"}`,
+		},
+		{
+			name:           "Query Parameter In English",
+			acceptLanguage: "en-US",
+			defaultLang:    i18n.Indonesian,
+			method:         http.MethodGet,
+			url:            "/brand?limit=abc",
+			mockBehavior:   func(*MockBrandService, *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"Invalid query parameter limit","error_code":"INVALID_QUERY_PARAMETER"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brands := new(MockBrandService)
+			transactions := new(MockTransactionService)
+			tt.mockBehavior(brands, transactions)
+
+			brandHandler := handler.NewBrandHandler(brands)
+			transactionHandler := handler.NewTransactionHandler(transactions)
+			router := httprouter.New()
+			router.GET("/brand/:id", brandHandler.GetByID)
+			router.GET("/brand", brandHandler.GetAll)
+			router.POST("/brand", brandHandler.Create)
+			router.POST("/transaction/redemption", transactionHandler.CreateRedemption)
+
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+
+			i18n.Middleware(tt.defaultLang, router).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			brands.AssertExpectations(t)
+			transactions.AssertExpectations(t)
+		})
+	}
+}
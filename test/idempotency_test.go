@@ -0,0 +1,153 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeIdempotencyRepository menyimpan key di memory untuk kebutuhan test
+type fakeIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newFakeIdempotencyRepository() *fakeIdempotencyRepository {
+	return &fakeIdempotencyRepository{records: map[string]*domain.IdempotencyRecord{}}
+}
+
+func (f *fakeIdempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.Scope+"|"+record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return false, nil
+	}
+	copied := *record
+	f.records[record.Scope+"|"+record.Key] = &copied
+	return true, nil
+}
+
+func (f *fakeIdempotencyRepository) Get(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[scope+"|"+key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (f *fakeIdempotencyRepository) SaveResponse(ctx context.Context, record *domain.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *record
+	f.records[record.Scope+"|"+record.Key] = &copied
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, scope+"|"+key)
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency_CreateRedemption(t *testing.T) {
+	body := `{"customer_id":1,"items":[{"voucher_id":1}]}`
+
+	tests := []struct {
+		name            string
+		retryBody       string
+		ttl             time.Duration
+		mockBehavior    func(service *MockTransactionService)
+		expectedStatus  int
+		expectedReplay  string
+		expectedMessage string
+	}{
+		{
+			name:      "Retry Replays Original Response",
+			retryBody: body,
+			ttl:       time.Hour,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.Anything).Return(nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedReplay: "true",
+		},
+		{
+			name:      "Same Key Different Body",
+			retryBody: `{"customer_id":2,"items":[{"voucher_id":1}]}`,
+			ttl:       time.Hour,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.Anything).Return(nil).Once()
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: `{"status":422,"message":"Idempotency-Key was already used with a different request body","error_code":"IDEMPOTENCY_KEY_REUSED"}`,
+		},
+		{
+			name:      "Expired Key Is Processed Again",
+			retryBody: body,
+			ttl:       -time.Second,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.Anything).Return(nil).Twice()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:      "Server Error Is Not Stored",
+			retryBody: body,
+			ttl:       time.Hour,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CreateRedemption", mock.Anything).Return(errors.New("database error")).Once()
+				service.On("CreateRedemption", mock.Anything).Return(nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			tt.mockBehavior(mockService)
+			idempotency := handler.NewIdempotency(newFakeIdempotencyRepository(), tt.ttl)
+			handle := idempotency.Wrap("POST /transaction/redemption", handler.NewTransactionHandler(mockService).CreateRedemption)
+
+			send := func(payload string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", bytes.NewBufferString(payload))
+				req.Header.Set(handler.IdempotencyKeyHeader, "retry-123")
+				rec := httptest.NewRecorder()
+				handle(rec, req, nil)
+				return rec
+			}
+
+			first := send(body)
+			retry := send(tt.retryBody)
+
+			assert.Equal(t, tt.expectedStatus, retry.Code)
+			assert.Equal(t, tt.expectedReplay, retry.Header().Get("Idempotent-Replayed"))
+			if tt.expectedReplay != "" {
+				assert.Equal(t, first.Code, retry.Code)
+				assert.JSONEq(t, first.Body.String(), retry.Body.String())
+			}
+			if tt.expectedMessage != "" {
+				assert.JSONEq(t, tt.expectedMessage, retry.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
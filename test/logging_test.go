@@ -0,0 +1,133 @@
+package test
+
+import (
+	"api-otto/internal/handler"
+	"api-otto/internal/logging"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeLogLines mengubah setiap baris log JSON menjadi map
+func decodeLogLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestLogging_RequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		expectSame bool
+	}{
+		{name: "Propagates Client ID", header: "order-42.retry_1", expectSame: true},
+		{name: "Generates Missing ID", header: ""},
+		{name: "Replaces Unsafe ID", header: "abc\ninjected"},
+		{name: "Replaces Too Long ID", header: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var seen string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = logging.RequestID(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/brand", nil)
+			if tt.header != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			logging.Middleware(logging.New(&out, "info"), next).ServeHTTP(rec, req)
+
+			id := rec.Header().Get(logging.RequestIDHeader)
+			assert.Equal(t, id, seen)
+			if tt.expectSame {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", id)
+			}
+		})
+	}
+}
+
+func TestLogging_AccessLogAndErrorResponse(t *testing.T) {
+	mockService := new(MockBrandService)
+	mockService.On("GetByID", int64(999)).Return(nil, nil)
+	brandHandler := handler.NewBrandHandler(mockService)
+
+	var out bytes.Buffer
+	logger := logging.New(&out, "info")
+	router := httprouter.New()
+	router.GET("/brand/:id", logging.WithRoute("/brand/:id", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		logger.InfoContext(r.Context(), "looking up brand", "brand_id", ps.ByName("id"))
+		brandHandler.GetByID(w, r, ps)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/brand/999", nil)
+	req.Header.Set(logging.RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+
+	logging.Middleware(logger, router).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"status":404,"message":"brand not found","error_code":"BRAND_NOT_FOUND","request_id":"req-123"}`, rec.Body.String())
+
+	lines := decodeLogLines(t, &out)
+	require.Len(t, lines, 2)
+
+	// Log dari dalam handler membawa request ID yang sama
+	assert.Equal(t, "looking up brand", lines[0]["msg"])
+	assert.Equal(t, "req-123", lines[0]["request_id"])
+
+	access := lines[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "INFO", access["level"])
+	assert.Equal(t, "req-123", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/brand/:id", access["route"])
+	assert.Equal(t, "/brand/999", access["path"])
+	assert.Equal(t, float64(http.StatusNotFound), access["status"])
+	assert.Equal(t, float64(rec.Body.Len()), access["bytes"])
+	assert.Contains(t, access, "latency_ms")
+}
+
+func TestLogging_ServerErrorLoggedAsError(t *testing.T) {
+	var out bytes.Buffer
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	logging.Middleware(logging.New(&out, "info"), next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transaction/redemption", nil))
+
+	lines := decodeLogLines(t, &out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "", lines[0]["route"])
+}
+
+func TestLogging_LevelFilter(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, "warn")
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	lines := decodeLogLines(t, &out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "shown", lines[0]["msg"])
+}
@@ -0,0 +1,226 @@
+package test
+
+import (
+	"api-otto/internal/app"
+	"api-otto/internal/config"
+	"api-otto/internal/domain"
+	"api-otto/internal/repository/memory"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTxManager_Rollback(t *testing.T) {
+	store := memory.NewStore()
+	brands := memory.NewBrandRepository(store)
+	vouchers := memory.NewVoucherRepository(store)
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	var brandID int64
+	err := memory.NewTxManager(store).WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		brand := &domain.Brand{Name: "Rollback"}
+		require.NoError(t, uow.Brands().Create(ctx, brand))
+		brandID = brand.ID
+		voucher := &domain.Voucher{BrandID: brand.ID, Code: "ROLLBACK", Name: "Rollback", Points: 10, ValidUntil: time.Now().Add(time.Hour)}
+		require.NoError(t, uow.Vouchers().Create(ctx, voucher))
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	brand, err := brands.GetByID(ctx, brandID)
+	require.NoError(t, err)
+	assert.Nil(t, brand)
+	list, _, err := vouchers.List(ctx, domain.VoucherFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Sequence tidak ikut di-rollback, sama seperti Postgres
+	next := &domain.Brand{Name: "Next"}
+	require.NoError(t, brands.Create(ctx, next))
+	assert.Equal(t, brandID+1, next.ID)
+}
+
+func TestMemoryTxManager_PanicRollsBack(t *testing.T) {
+	store := memory.NewStore()
+	brands := memory.NewBrandRepository(store)
+	txManager := memory.NewTxManager(store)
+	ctx := context.Background()
+
+	var brandID int64
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+			brand := &domain.Brand{Name: "Panic"}
+			require.NoError(t, uow.Brands().Create(ctx, brand))
+			brandID = brand.ID
+			panic("boom")
+		})
+	})
+
+	brand, err := brands.GetByID(ctx, brandID)
+	require.NoError(t, err)
+	assert.Nil(t, brand)
+
+	// txMu sudah dilepas, transaction dan penulisan berikutnya tidak macet
+	done := make(chan error, 1)
+	go func() {
+		done <- txManager.WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+			return uow.Brands().Create(ctx, &domain.Brand{Name: "After Panic"})
+		})
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("transaction lock was not released after panic")
+	}
+	require.NoError(t, brands.Create(ctx, &domain.Brand{Name: "Outside"}))
+}
+
+// TestMemoryTxManager_WriteOutsideTransaction memastikan penulisan di luar
+// transaction menunggu transaction yang sedang berjalan, sehingga rollback
+// tidak menimpanya dengan nilai lama
+func TestMemoryTxManager_WriteOutsideTransaction(t *testing.T) {
+	store := memory.NewStore()
+	brands := memory.NewBrandRepository(store)
+	ctx := context.Background()
+	brand := &domain.Brand{Name: "Before"}
+	require.NoError(t, brands.Create(ctx, brand))
+	errFailed := errors.New("failed")
+
+	updated := make(chan struct{})
+	inside := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-inside
+		outside := *brand
+		outside.Name = "Outside"
+		assert.NoError(t, brands.Update(ctx, &outside))
+		close(updated)
+	}()
+
+	err := memory.NewTxManager(store).WithinTransaction(ctx, func(uow domain.UnitOfWork) error {
+		inTx := *brand
+		inTx.Name = "Rolled Back"
+		require.NoError(t, uow.Brands().Update(ctx, &inTx))
+		close(inside)
+		select {
+		case <-updated:
+			t.Error("write outside the transaction did not wait for it")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+	wg.Wait()
+
+	stored, err := brands.GetByID(ctx, brand.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Outside", stored.Name)
+}
+
+func TestMemoryVoucherRepository_ConcurrentDecrementStock(t *testing.T) {
+	store := memory.NewStore()
+	ctx := context.Background()
+	brand := &domain.Brand{Name: "Concurrent"}
+	require.NoError(t, memory.NewBrandRepository(store).Create(ctx, brand))
+	vouchers := memory.NewVoucherRepository(store)
+	stock := 10
+	voucher := &domain.Voucher{BrandID: brand.ID, Code: "STOCK10", Name: "Stock", Points: 10, ValidUntil: time.Now().Add(time.Hour), TotalStock: &stock}
+	require.NoError(t, vouchers.Create(ctx, voucher))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := vouchers.DecrementStock(ctx, voucher.ID); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			} else {
+				assert.ErrorIs(t, err, domain.ErrVoucherSoldOut)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	stored, err := vouchers.GetByID(ctx, voucher.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *stored.RemainingStock)
+}
+
+func TestMemoryVoucherRepository_ReturnsCopies(t *testing.T) {
+	store := memory.NewStore()
+	ctx := context.Background()
+	brand := &domain.Brand{Name: "Copies"}
+	require.NoError(t, memory.NewBrandRepository(store).Create(ctx, brand))
+	vouchers := memory.NewVoucherRepository(store)
+	stock := 5
+	voucher := &domain.Voucher{BrandID: brand.ID, Code: "COPY", Name: "Copy", Points: 10, ValidUntil: time.Now().Add(time.Hour), TotalStock: &stock}
+	require.NoError(t, vouchers.Create(ctx, voucher))
+
+	stock = 1
+	*voucher.RemainingStock = 1
+	stored, err := vouchers.GetByID(ctx, voucher.ID)
+	require.NoError(t, err)
+	*stored.TotalStock = 0
+
+	stored, err = vouchers.GetByID(ctx, voucher.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, *stored.TotalStock)
+	assert.Equal(t, 5, *stored.RemainingStock)
+}
+
+func TestApp_MemoryStorage(t *testing.T) {
+	cfg := config.Default()
+	cfg.Storage = "memory"
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	handler := application.Handler()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", app.DemoAPIKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/readyz", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = do(http.MethodGet, "/voucher?sort=code", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var vouchers struct {
+		Data []domain.Voucher `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vouchers))
+	require.NotEmpty(t, vouchers.Data)
+
+	rec = do(http.MethodPost, "/transaction/redemption", `{"customer_id": 1, "items": [{"voucher_id": 1}]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = do(http.MethodGet, "/customer/1/transactions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"completed"`)
+
+	req := httptest.NewRequest(http.MethodGet, "/brand", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
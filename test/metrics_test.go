@@ -0,0 +1,90 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape mengambil output /metrics dari handler
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_HTTPRequests(t *testing.T) {
+	m := metrics.New()
+	router := httprouter.New()
+	router.GET("/brand/:id", m.Instrument(http.MethodGet, "/brand/:id", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName("id") == "999" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+
+	for _, path := range []string{"/brand/1", "/brand/2", "/brand/999"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/brand/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/brand/:id",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/brand/:id",status="200"} 2`)
+	assert.NotContains(t, body, `route="/brand/1"`)
+}
+
+func TestMetrics_RecordRedemption(t *testing.T) {
+	m := metrics.New()
+
+	m.RecordRedemption(domain.TransactionStatusCompleted, map[int64]int{1: 50000, 2: 25000}, nil)
+	m.RecordRedemption(domain.TransactionStatusCompleted, map[int64]int{1: 10000}, nil)
+	m.RecordRedemption(domain.TransactionStatusFailed, map[int64]int{2: 25000}, fmt.Errorf("%w: voucher 3", domain.ErrVoucherExpired))
+	m.RecordRedemption(domain.TransactionStatusFailed, map[int64]int{}, domain.ErrCustomerNotFound)
+	m.RecordRedemption(domain.TransactionStatusFailed, map[int64]int{1: 10000}, fmt.Errorf("lock voucher: %w", context.DeadlineExceeded))
+
+	body := scrape(t, m)
+	for _, expected := range []string{
+		`redemptions_total{brand_id="1",status="completed"} 2`,
+		`redemptions_total{brand_id="2",status="completed"} 1`,
+		`redemptions_total{brand_id="2",status="failed"} 1`,
+		`redemptions_total{brand_id="unknown",status="failed"} 1`,
+		`redemption_points_total{brand_id="1"} 60000`,
+		`redemption_points_total{brand_id="2"} 25000`,
+		`redemption_rejections_total{code="VOUCHER_EXPIRED"} 1`,
+		`redemption_rejections_total{code="CUSTOMER_NOT_FOUND"} 1`,
+		`redemption_rejections_total{code="REQUEST_TIMEOUT"} 1`,
+	} {
+		assert.Contains(t, body, expected)
+	}
+}
+
+func TestMetrics_DBStats(t *testing.T) {
+	// sql.Open belum membuka koneksi, statistik pool tetap tersedia
+	db, err := sql.Open("postgres", "postgres://localhost:1/unused?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+
+	m := metrics.New()
+	m.RegisterDB("postgres", db)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="postgres"} 7`)
+	assert.Contains(t, body, `go_sql_in_use_connections{db_name="postgres"} 0`)
+}
@@ -0,0 +1,301 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/cli"
+	"api-otto/internal/config"
+	"api-otto/internal/migrate"
+	"api-otto/internal/repository"
+	"api-otto/internal/seed"
+	"api-otto/migrations"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_LoadEmbedded(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, uint64(20250228101246), loaded[0].Version)
+	assert.Equal(t, "create_table_brands", loaded[0].Name)
+	for i := 1; i < len(loaded); i++ {
+		assert.Less(t, loaded[i-1].Version, loaded[i].Version)
+	}
+}
+
+func TestMigrate_LoadErrors(t *testing.T) {
+	file := func() *fstest.MapFile { return &fstest.MapFile{Data: []byte("SELECT 1;")} }
+
+	tests := []struct {
+		name          string
+		fsys          fstest.MapFS
+		expectedError string
+	}{
+		{
+			name:          "missing down",
+			fsys:          fstest.MapFS{"1_init.up.sql": file()},
+			expectedError: "migration 1_init: both .up.sql and .down.sql are required",
+		},
+		{
+			name:          "invalid version",
+			fsys:          fstest.MapFS{"v1_init.up.sql": file()},
+			expectedError: `migration v1_init.up.sql: invalid version "v1"`,
+		},
+		{
+			name:          "missing direction",
+			fsys:          fstest.MapFS{"1_init.sql": file()},
+			expectedError: "migration 1_init.sql: name must end with .up.sql or .down.sql",
+		},
+		{
+			name:          "missing title",
+			fsys:          fstest.MapFS{"1.up.sql": file()},
+			expectedError: "migration 1.up.sql: name must be {version}_{title}.up.sql",
+		},
+		{
+			name:          "conflicting names",
+			fsys:          fstest.MapFS{"1_init.up.sql": file(), "1_other.down.sql": file()},
+			expectedError: `migration 1: conflicting names`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrate.Load(tt.fsys)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestCLI_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		configure     func(cfg *config.Config)
+		expectedOut   string
+		expectedUsage bool
+		expectedError string
+	}{
+		{name: "check config", args: []string{"check-config"}, expectedOut: "config is valid\n"},
+		{name: "help", args: []string{"help"}, expectedOut: cli.Usage},
+		{name: "unknown command", args: []string{"start"}, expectedUsage: true, expectedError: `unknown command "start"`},
+		{name: "serve with arguments", args: []string{"serve", "now"}, expectedUsage: true, expectedError: "serve takes no arguments"},
+		{name: "migrate without subcommand", args: []string{"migrate"}, expectedUsage: true, expectedError: "migrate needs a subcommand"},
+		{name: "unknown migrate subcommand", args: []string{"migrate", "redo"}, expectedUsage: true, expectedError: `unknown migrate subcommand "redo"`},
+		{name: "migrate down zero", args: []string{"migrate", "down", "0"}, expectedUsage: true, expectedError: `N must be a positive number, got "0"`},
+		{name: "migrate goto without version", args: []string{"migrate", "goto"}, expectedUsage: true, expectedError: "migrate goto needs exactly one version"},
+		{name: "migrate force invalid version", args: []string{"migrate", "force", "latest"}, expectedUsage: true, expectedError: `migrate force: invalid version "latest"`},
+		{name: "create api key without role", args: []string{"create-api-key", "partner"}, expectedUsage: true, expectedError: "create-api-key needs NAME, ROLE"},
+		{name: "create api key unknown role", args: []string{"create-api-key", "partner", "admin"}, expectedUsage: true, expectedError: `ROLE must be platform_admin or brand_manager, got "admin"`},
+		{name: "create api key customer role", args: []string{"create-api-key", "app", "customer"}, expectedUsage: true, expectedError: `got "customer"`},
+		{name: "create api key brand manager without brand", args: []string{"create-api-key", "kopi", "brand_manager"}, expectedUsage: true, expectedError: "brand_manager needs BRAND_ID"},
+		{name: "create api key invalid brand", args: []string{"create-api-key", "kopi", "brand_manager", "kopi"}, expectedUsage: true, expectedError: `BRAND_ID must be a positive number, got "kopi"`},
+		{name: "create api key platform admin with brand", args: []string{"create-api-key", "ops", "platform_admin", "1"}, expectedUsage: true, expectedError: "platform_admin keys are not scoped to a brand"},
+		{name: "seed with arguments", args: []string{"seed", "--force"}, expectedUsage: true, expectedError: "seed takes no arguments"},
+		{
+			name:          "migrate with memory storage",
+			args:          []string{"migrate", "status"},
+			configure:     func(cfg *config.Config) { cfg.Storage = "memory" },
+			expectedError: "migrate needs storage postgres, storage is memory",
+		},
+		{
+			name:          "create api key with memory storage",
+			args:          []string{"create-api-key", "ops", "platform_admin"},
+			configure:     func(cfg *config.Config) { cfg.Storage = "memory" },
+			expectedError: "create-api-key needs storage postgres, storage is memory",
+		},
+		{
+			name: "check config unknown route",
+			args: []string{"check-config"},
+			configure: func(cfg *config.Config) {
+				cfg.Server.RouteTimeouts = map[string]time.Duration{"GET /brands": time.Second}
+			},
+			expectedError: `server.route_timeouts: unknown routes ["GET /brands"]`,
+		},
+		{
+			name: "check config missing public key",
+			args: []string{"check-config"},
+			configure: func(cfg *config.Config) {
+				cfg.Auth.JWTAlgorithm = "RS256"
+				cfg.Auth.JWTPublicKeyFile = "testdata/missing.pem"
+			},
+			expectedError: "auth.jwt_public_key_file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			var out bytes.Buffer
+
+			err := cli.Run(context.Background(), cfg, tt.args, &out)
+
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedOut, out.String())
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Equal(t, tt.expectedUsage, errors.Is(err, cli.ErrUsage))
+		})
+	}
+}
+
+// isolatedSchema membuat schema baru dan mengembalikan koneksi yang
+// search_path-nya menunjuk ke schema itu, supaya migrasi dalam test tidak
+// menyentuh tabel di TEST_DATABASE_URL
+func isolatedSchema(t *testing.T) *sql.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	db, err := database.NewPostgresConnection(u.String())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestMigrator_Postgres butuh database Postgres, set TEST_DATABASE_URL
+// untuk menjalankannya
+func TestMigrator_Postgres(t *testing.T) {
+	db := isolatedSchema(t)
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"1_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"1_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"2_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"2_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"3_create_c.up.sql":   {Data: []byte("CREATE TABLE c (id INT); INSERT INTO c VALUES (1);")},
+		"3_create_c.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+	migrator, err := migrate.New(db, fsys)
+	require.NoError(t, err)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), status.Version)
+	assert.Len(t, status.Pending, 3)
+	assert.True(t, status.Behind())
+
+	steps, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	assertSchemaVersion(t, db, 3, false)
+
+	steps, err = migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, uint64(3), steps[0].Migration.Version)
+	assert.Equal(t, migrate.DirectionDown, steps[0].Direction)
+	assertSchemaVersion(t, db, 1, false)
+
+	_, err = migrator.Goto(ctx, 2)
+	require.NoError(t, err)
+	assertSchemaVersion(t, db, 2, false)
+
+	_, err = migrator.Goto(ctx, 0)
+	require.NoError(t, err)
+	var rows int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&rows))
+	assert.Equal(t, 0, rows)
+
+	_, err = migrator.Goto(ctx, 4)
+	assert.EqualError(t, err, "version 4 not found in embedded migrations")
+}
+
+func TestMigrator_PostgresDirty(t *testing.T) {
+	db := isolatedSchema(t)
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"1_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"1_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"2_broken.up.sql":     {Data: []byte("CREATE TABLE b (id INT); SELECT * FROM missing;")},
+		"2_broken.down.sql":   {Data: []byte("DROP TABLE b;")},
+	}
+	migrator, err := migrate.New(db, fsys)
+	require.NoError(t, err)
+
+	steps, err := migrator.Up(ctx)
+	require.Error(t, err)
+	assert.Len(t, steps, 1)
+	assertSchemaVersion(t, db, 2, true)
+	// Statement sebelum error ikut di-rollback
+	var exists bool
+	require.NoError(t, db.QueryRow("SELECT to_regclass('b') IS NOT NULL").Scan(&exists))
+	assert.False(t, exists)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, migrate.ErrDirty)
+
+	require.NoError(t, migrator.Force(ctx, 1))
+	assertSchemaVersion(t, db, 1, false)
+}
+
+// TestMigrator_PostgresEmbedded memastikan semua migrasi bisa dijalankan
+// naik, turun sampai habis lalu naik lagi, dan seed bisa dijalankan ulang
+func TestMigrator_PostgresEmbedded(t *testing.T) {
+	db := isolatedSchema(t)
+	ctx := context.Background()
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = migrator.Goto(ctx, 0)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Behind())
+	assertSchemaVersion(t, db, migrator.Latest(), false)
+
+	seeded, err := seed.Run(ctx, repository.NewTxManager(db), time.Now())
+	require.NoError(t, err)
+	assert.True(t, seeded)
+	seeded, err = seed.Run(ctx, repository.NewTxManager(db), time.Now())
+	require.NoError(t, err)
+	assert.False(t, seeded)
+}
+
+func assertSchemaVersion(t *testing.T, db *sql.DB, version uint64, dirty bool) {
+	t.Helper()
+	var actualVersion uint64
+	var actualDirty bool
+	require.NoError(t, db.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&actualVersion, &actualDirty))
+	assert.Equal(t, version, actualVersion)
+	assert.Equal(t, dirty, actualDirty)
+}
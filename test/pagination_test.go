@@ -0,0 +1,236 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/repository"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoucherHandler_ListFilters(t *testing.T) {
+	brandID := int64(3)
+	valid := true
+	minPoints := 100
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		mockBehavior   func(service *MockVoucherService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Filters Are Passed To Service",
+			query: "?brand_id=3&valid=true&min_points=100&valid_until_from=2025-01-01&valid_until_to=2025-01-31&limit=2&sort=-points&cursor=abc",
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("List", domain.VoucherFilter{
+					BrandID:    &brandID,
+					Valid:      &valid,
+					MinPoints:  &minPoints,
+					ValidUntil: domain.TimeRange{From: &from, To: &to},
+					Page:       domain.Page{Limit: 2, Cursor: "abc", Sort: "-points"},
+				}).Return([]domain.Voucher{}, "next-page", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Success","data":[],"next_cursor":"next-page"}`,
+		},
+		{
+			name:           "Invalid Number",
+			query:          "?min_points=abc",
+			mockBehavior:   func(service *MockVoucherService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"Invalid query parameter min_points","error_code":"INVALID_QUERY_PARAMETER"}`,
+		},
+		{
+			name:           "Invalid Limit",
+			query:          "?limit=0",
+			mockBehavior:   func(service *MockVoucherService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"Invalid query parameter limit","error_code":"INVALID_QUERY_PARAMETER"}`,
+		},
+		{
+			name:  "Invalid Cursor",
+			query: "?cursor=broken",
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("List", mock.Anything).Return([]domain.Voucher{}, "", domain.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"invalid cursor","error_code":"INVALID_CURSOR"}`,
+		},
+		{
+			name:  "Unknown Sort Field",
+			query: "?sort=description",
+			mockBehavior: func(service *MockVoucherService) {
+				service.On("List", mock.Anything).Return([]domain.Voucher{}, "", fmt.Errorf("%w: description", domain.ErrInvalidSort))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"invalid sort field: description","error_code":"INVALID_SORT"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockVoucherService)
+			tt.mockBehavior(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/voucher"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.NewVoucherHandler(mockService).List(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVoucherHandler_GetByBrandIDEmptyPage(t *testing.T) {
+	minPoints := 100
+
+	tests := []struct {
+		name           string
+		query          string
+		filter         domain.VoucherFilter
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Last Page After Cursor",
+			query:          "?cursor=abc",
+			filter:         domain.VoucherFilter{Page: domain.Page{Cursor: "abc"}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Success","data":[]}`,
+		},
+		{
+			name:           "Filter Matches Nothing",
+			query:          "?min_points=100",
+			filter:         domain.VoucherFilter{MinPoints: &minPoints},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":200,"message":"Success","data":[]}`,
+		},
+		{
+			name:           "Brand Without Vouchers",
+			query:          "?limit=5",
+			filter:         domain.VoucherFilter{Page: domain.Page{Limit: 5}},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"Brand has no vouchers","error_code":"NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockVoucherService)
+			mockService.On("GetByBrandID", int64(2), tt.filter).Return([]domain.Voucher(nil), "", nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/brand/2/vouchers"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.NewVoucherHandler(mockService).GetByBrandID(rec, req, httprouter.Params{httprouter.Param{Key: "id", Value: "2"}})
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransactionHandler_GetCustomerTransactionsFilters(t *testing.T) {
+	t.Run("Status Filter", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		mockService.On("GetCustomerTransactions", int64(7), domain.TransactionFilter{
+			Status: domain.TransactionStatusCompleted,
+		}).Return([]domain.Transaction{}, "", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/customer/7/transactions?status=completed", nil)
+		rec := httptest.NewRecorder()
+		handler.NewTransactionHandler(mockService).GetCustomerTransactions(rec, req, httprouter.Params{httprouter.Param{Key: "id", Value: "7"}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Status", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+
+		req := httptest.NewRequest(http.MethodGet, "/customer/7/transactions?status=shipped", nil)
+		rec := httptest.NewRecorder()
+		handler.NewTransactionHandler(mockService).GetCustomerTransactions(rec, req, httprouter.Params{httprouter.Param{Key: "id", Value: "7"}})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"status":400,"message":"Invalid query parameter status","error_code":"INVALID_QUERY_PARAMETER"}`, rec.Body.String())
+	})
+}
+
+// TestVoucherRepository_ListPagination menelusuri semua halaman voucher
+// sebuah brand dan memastikan tidak ada data yang terlewat atau terulang.
+// Butuh database Postgres yang sudah dimigrasi, set TEST_DATABASE_URL.
+func TestVoucherRepository_ListPagination(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	brandRepo := repository.NewBrandRepository(db)
+	voucherRepo := repository.NewVoucherRepository(db)
+
+	brand := &domain.Brand{Name: "Pagination Brand"}
+	require.NoError(t, brandRepo.Create(context.Background(), brand))
+
+	// Beberapa voucher sengaja memakai points yang sama untuk menguji tie-breaker id
+	created := map[int64]bool{}
+	for i := 0; i < 7; i++ {
+		voucher := &domain.Voucher{
+			BrandID:    brand.ID,
+			Code:       fmt.Sprintf("PAGE%d-%d", time.Now().UnixNano(), i),
+			Name:       fmt.Sprintf("Voucher %d", i),
+			Points:     100 * (i % 3),
+			ValidUntil: time.Now().Add(24 * time.Hour),
+		}
+		if voucher.Points == 0 {
+			voucher.Points = 50
+		}
+		require.NoError(t, voucherRepo.Create(context.Background(), voucher))
+		created[voucher.ID] = true
+	}
+
+	seen := map[int64]bool{}
+	lastPoints := int(^uint(0) >> 1)
+	filter := domain.VoucherFilter{BrandID: &brand.ID, Page: domain.Page{Limit: 3, Sort: "-points"}}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination does not terminate")
+
+		vouchers, next, err := voucherRepo.List(context.Background(), filter)
+		require.NoError(t, err)
+		for _, voucher := range vouchers {
+			assert.False(t, seen[voucher.ID], "voucher %d returned twice", voucher.ID)
+			assert.LessOrEqual(t, voucher.Points, lastPoints)
+			seen[voucher.ID] = true
+			lastPoints = voucher.Points
+		}
+		if next == "" {
+			break
+		}
+		filter.Cursor = next
+	}
+	assert.Equal(t, created, seen)
+
+	_, _, err = voucherRepo.List(context.Background(), domain.VoucherFilter{Page: domain.Page{Sort: "points", Cursor: filter.Cursor}})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
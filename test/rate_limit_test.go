@@ -0,0 +1,341 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/auth"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/i18n"
+	"api-otto/internal/repository"
+	"api-otto/internal/repository/memory"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRateLimitRepository mensimulasikan database yang tidak bisa diakses
+type failingRateLimitRepository struct{}
+
+func (failingRateLimitRepository) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitResult, error) {
+	return domain.RateLimitResult{}, errors.New("connection refused")
+}
+
+func (failingRateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	return 0, errors.New("connection refused")
+}
+
+var okHandler httprouter.Handle = func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// serveRateLimited menjalankan satu request melalui i18n dan rate limiter
+func serveRateLimited(handle httprouter.Handle, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	i18n.Middleware(i18n.English, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, nil)
+	})).ServeHTTP(rec, req)
+	return rec
+}
+
+// assertTokenBucket memeriksa perhitungan token bucket yang sama untuk
+// setiap backend
+func assertTokenBucket(t *testing.T, repo domain.RateLimitRepository, key string) {
+	ctx := context.Background()
+	policy := domain.RateLimitPolicy{Limit: 2, Period: time.Minute}
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name       string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{name: "New Bucket Starts Full", at: 0, allowed: true, remaining: 1},
+		{name: "Last Token", at: 0, allowed: true, remaining: 0},
+		{name: "Empty", at: 15 * time.Second, allowed: false, remaining: 0, retryAfter: 15 * time.Second},
+		{name: "Refilled One Token", at: 30 * time.Second, allowed: true, remaining: 0},
+		{name: "Clock Going Backwards Adds Nothing", at: 20 * time.Second, allowed: false, remaining: 0, retryAfter: 30 * time.Second},
+		{name: "Refill Is Capped At Limit", at: 10 * time.Minute, allowed: true, remaining: 1},
+	}
+
+	for _, step := range steps {
+		result, err := repo.Take(ctx, key, policy, start.Add(step.at))
+
+		require.NoError(t, err, step.name)
+		assert.Equal(t, step.allowed, result.Allowed, step.name)
+		assert.Equal(t, 2, result.Limit, step.name)
+		assert.Equal(t, step.remaining, result.Remaining, step.name)
+		assert.InDelta(t, step.retryAfter.Seconds(), result.RetryAfter.Seconds(), 0.01, step.name)
+	}
+}
+
+func TestMemoryRateLimitRepository(t *testing.T) {
+	repo := memory.NewRateLimitRepository()
+	assertTokenBucket(t, repo, "GET /brand ip:10.0.0.1")
+
+	// Bucket lain tidak terpengaruh
+	result, err := repo.Take(context.Background(), "GET /brand ip:10.0.0.2", domain.RateLimitPolicy{Limit: 2, Period: time.Minute}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Remaining)
+
+	deleted, err := repo.DeleteIdle(context.Background(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestRateLimiter_Headers(t *testing.T) {
+	limiter := handler.NewRateLimiter(memory.NewRateLimitRepository(), handler.RateLimitOptions{
+		Default: domain.RateLimitPolicy{Limit: 2, Period: time.Minute},
+	})
+	handle := limiter.Wrap("POST /transaction/redemption", okHandler)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		return req
+	}
+
+	first := serveRateLimited(handle, newRequest())
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", first.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, first.Header().Get("Retry-After"))
+
+	serveRateLimited(handle, newRequest())
+	limited := serveRateLimited(handle, newRequest())
+
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "0", limited.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", limited.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"status":429,"message":"Too many requests, retry after the time in the Retry-After header","error_code":"RATE_LIMITED"}`, limited.Body.String())
+}
+
+func TestRateLimiter_Clients(t *testing.T) {
+	otherCustomer := &domain.Principal{Type: domain.PrincipalCustomer, ID: 6, Role: domain.RoleCustomer}
+
+	tests := []struct {
+		name              string
+		trustForwardedFor bool
+		first             func(*http.Request) *http.Request
+		second            func(*http.Request) *http.Request
+		expectedStatus    int
+	}{
+		{
+			name:           "Same IP Shares Bucket",
+			first:          withRemoteAddr("10.0.0.1:1000"),
+			second:         withRemoteAddr("10.0.0.1:2000"),
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "Different IP",
+			first:          withRemoteAddr("10.0.0.1:1000"),
+			second:         withRemoteAddr("10.0.0.2:1000"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Principal Shares Bucket Across IPs",
+			first:          withPrincipal(customerPrincipal, "10.0.0.1:1000"),
+			second:         withPrincipal(customerPrincipal, "10.0.0.2:1000"),
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "Different Principal Behind Same IP",
+			first:          withPrincipal(customerPrincipal, "10.0.0.1:1000"),
+			second:         withPrincipal(otherCustomer, "10.0.0.1:1000"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Forwarded For Ignored By Default",
+			first:          withForwardedFor("203.0.113.1"),
+			second:         withForwardedFor("203.0.113.2"),
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:              "Forwarded For Uses Entry Added By Proxy",
+			trustForwardedFor: true,
+			first:             withForwardedFor("198.51.100.7, 203.0.113.1"),
+			second:            withForwardedFor("198.51.100.7, 203.0.113.2"),
+			expectedStatus:    http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := handler.NewRateLimiter(memory.NewRateLimitRepository(), handler.RateLimitOptions{
+				Default:           domain.RateLimitPolicy{Limit: 1, Period: time.Minute},
+				TrustForwardedFor: tt.trustForwardedFor,
+			})
+			handle := limiter.Wrap("GET /voucher", okHandler)
+
+			first := serveRateLimited(handle, tt.first(httptest.NewRequest(http.MethodGet, "/voucher", nil)))
+			second := serveRateLimited(handle, tt.second(httptest.NewRequest(http.MethodGet, "/voucher", nil)))
+
+			assert.Equal(t, http.StatusOK, first.Code)
+			assert.Equal(t, tt.expectedStatus, second.Code)
+		})
+	}
+}
+
+func withRemoteAddr(addr string) func(*http.Request) *http.Request {
+	return func(req *http.Request) *http.Request {
+		req.RemoteAddr = addr
+		return req
+	}
+}
+
+func withPrincipal(principal *domain.Principal, addr string) func(*http.Request) *http.Request {
+	return func(req *http.Request) *http.Request {
+		req.RemoteAddr = addr
+		return req.WithContext(domain.WithPrincipal(req.Context(), principal))
+	}
+}
+
+func withForwardedFor(forwarded string) func(*http.Request) *http.Request {
+	return func(req *http.Request) *http.Request {
+		req.RemoteAddr = "10.0.0.1:1000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		return req
+	}
+}
+
+// TestRateLimiter_FailedAuthentication memastikan request dengan API key
+// salah ikut dibatasi per IP, sehingga 401 berulang akhirnya menjadi 429
+func TestRateLimiter_FailedAuthentication(t *testing.T) {
+	apiKeys := newFakeAPIKeyRepository()
+	validKey := addAPIKey(t, apiKeys, &domain.APIKey{Name: "backoffice", Role: domain.RolePlatformAdmin})
+	authenticator, err := auth.New(apiKeys, newFakeRoleRepository(), auth.Options{Algorithm: "HS256", Secret: []byte(testJWTSecret)})
+	require.NoError(t, err)
+	limiter := handler.NewRateLimiter(memory.NewRateLimitRepository(), handler.RateLimitOptions{
+		Default: domain.RateLimitPolicy{Limit: 300, Period: time.Minute},
+		PerIP:   domain.RateLimitPolicy{Limit: 3, Period: time.Minute},
+	})
+	next := limiter.WrapIP(handler.Authenticate(authenticator, principalEcho))
+
+	send := func(key, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/brand", nil)
+		req.Header.Set("X-API-Key", key)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		next(rec, req, nil)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, send("ak_wrong", "10.0.0.1:1000").Code)
+	}
+	limited := send("ak_wrong", "10.0.0.1:1000")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "3;w=60", limited.Header().Get("RateLimit-Policy"))
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+
+	// Key yang benar dari IP yang sama ikut ditolak, IP lain tidak terpengaruh
+	assert.Equal(t, http.StatusTooManyRequests, send(validKey, "10.0.0.1:2000").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("ak_wrong", "10.0.0.2:1000").Code)
+	assert.Equal(t, http.StatusOK, send(validKey, "10.0.0.3:1000").Code)
+}
+
+func TestRateLimiter_RoutePolicies(t *testing.T) {
+	limiter := handler.NewRateLimiter(memory.NewRateLimitRepository(), handler.RateLimitOptions{
+		Default: domain.RateLimitPolicy{Limit: 300, Period: time.Minute},
+		Routes: map[string]domain.RateLimitPolicy{
+			"POST /transaction/redemption": {Limit: 1, Period: time.Minute},
+		},
+	})
+	redemption := limiter.Wrap("POST /transaction/redemption", okHandler)
+	brands := limiter.Wrap("GET /brand", okHandler)
+
+	assert.Equal(t, http.StatusOK, serveRateLimited(redemption, httptest.NewRequest(http.MethodPost, "/transaction/redemption", nil)).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveRateLimited(redemption, httptest.NewRequest(http.MethodPost, "/transaction/redemption", nil)).Code)
+
+	// Kuota redemption yang habis tidak memengaruhi route lain
+	rec := serveRateLimited(brands, httptest.NewRequest(http.MethodGet, "/brand", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "300", rec.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimiter_FailsOpen(t *testing.T) {
+	limiter := handler.NewRateLimiter(failingRateLimitRepository{}, handler.RateLimitOptions{
+		Default: domain.RateLimitPolicy{Limit: 1, Period: time.Minute},
+	})
+	handle := limiter.Wrap("GET /brand", okHandler)
+
+	for i := 0; i < 3; i++ {
+		rec := serveRateLimited(handle, httptest.NewRequest(http.MethodGet, "/brand", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimiter_LocalizedError(t *testing.T) {
+	limiter := handler.NewRateLimiter(memory.NewRateLimitRepository(), handler.RateLimitOptions{
+		Default: domain.RateLimitPolicy{Limit: 1, Period: time.Minute},
+	})
+	handle := limiter.Wrap("GET /brand", okHandler)
+	serveRateLimited(handle, httptest.NewRequest(http.MethodGet, "/brand", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/brand", nil)
+	req.Header.Set("Accept-Language", "id")
+	rec := serveRateLimited(handle, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Terlalu banyak request")
+}
+
+// TestPostgresRateLimitRepository butuh database Postgres yang sudah
+// dimigrasi, set TEST_DATABASE_URL untuk menjalankannya
+func TestPostgresRateLimitRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRateLimitRepository(db)
+	prefix := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	defer db.Exec(`DELETE FROM rate_limit_buckets WHERE bucket_key LIKE $1`, prefix+"%")
+
+	t.Run("Token Bucket", func(t *testing.T) {
+		assertTokenBucket(t, repo, prefix+" bucket")
+	})
+
+	t.Run("Concurrent Requests Share Bucket", func(t *testing.T) {
+		policy := domain.RateLimitPolicy{Limit: 5, Period: time.Hour}
+		now := time.Now()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := repo.Take(context.Background(), prefix+" concurrent", policy, now)
+				assert.NoError(t, err)
+				if result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 5, allowed)
+	})
+}
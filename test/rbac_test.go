@@ -0,0 +1,558 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/auth"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/i18n"
+	"api-otto/internal/repository"
+	"api-otto/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrandRepository menganggap semua brand ada
+type fakeBrandRepository struct {
+	domain.BrandRepository
+}
+
+func (fakeBrandRepository) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+	return &domain.Brand{ID: id}, nil
+}
+
+// fakeVoucherRepository hanya mengimplementasikan method yang dipakai
+// voucherService untuk menulis voucher
+type fakeVoucherRepository struct {
+	domain.VoucherRepository
+	vouchers map[int64]*domain.Voucher
+	written  int
+}
+
+func (f *fakeVoucherRepository) GetByID(ctx context.Context, id int64) (*domain.Voucher, error) {
+	return f.vouchers[id], nil
+}
+
+func (f *fakeVoucherRepository) Create(ctx context.Context, voucher *domain.Voucher) error {
+	f.written++
+	return nil
+}
+
+func (f *fakeVoucherRepository) Update(ctx context.Context, voucher *domain.Voucher) error {
+	f.written++
+	return nil
+}
+
+func (f *fakeVoucherRepository) Delete(ctx context.Context, id int64) error {
+	f.written++
+	return nil
+}
+
+// fakeTransactionRepository mengembalikan satu transaksi dan mencatat
+// filter daftar transaksi terakhir
+type fakeTransactionRepository struct {
+	domain.TransactionRepository
+	transaction domain.Transaction
+	refunds     []domain.Refund
+	filter      domain.TransactionFilter
+}
+
+func (f *fakeTransactionRepository) GetByID(ctx context.Context, id int64) (*domain.Transaction, error) {
+	transaction := f.transaction
+	return &transaction, nil
+}
+
+func (f *fakeTransactionRepository) GetTransactionItems(ctx context.Context, transactionID int64) ([]domain.TransactionItem, error) {
+	return f.transaction.Items, nil
+}
+
+func (f *fakeTransactionRepository) GetRefunds(ctx context.Context, transactionID int64) ([]domain.Refund, error) {
+	return f.refunds, nil
+}
+
+func (f *fakeTransactionRepository) GetStatusHistory(ctx context.Context, transactionID int64) ([]domain.TransactionStatusChange, error) {
+	return nil, nil
+}
+
+func (f *fakeTransactionRepository) GetByCustomerID(ctx context.Context, customerID int64, filter domain.TransactionFilter) ([]domain.Transaction, string, error) {
+	f.filter = filter
+	if f.transaction.ID == 0 {
+		return nil, "", nil
+	}
+	return []domain.Transaction{f.transaction}, "", nil
+}
+
+var (
+	adminPrincipal        = &domain.Principal{Type: domain.PrincipalAPIKey, ID: 1, Role: domain.RolePlatformAdmin}
+	brandManagerPrincipal = &domain.Principal{Type: domain.PrincipalAPIKey, ID: 2, Role: domain.RoleBrandManager, BrandID: 10}
+	customerPrincipal     = &domain.Principal{Type: domain.PrincipalCustomer, ID: 5, Role: domain.RoleCustomer}
+)
+
+func TestRBAC_RoutePermissions(t *testing.T) {
+	apiKeys := newFakeAPIKeyRepository()
+	adminKey := addAPIKey(t, apiKeys, &domain.APIKey{Name: "backoffice", Role: domain.RolePlatformAdmin})
+	brandID := int64(10)
+	managerKey := addAPIKey(t, apiKeys, &domain.APIKey{Name: "brand-10", Role: domain.RoleBrandManager, BrandID: &brandID})
+	authenticator, err := auth.New(apiKeys, newFakeRoleRepository(), auth.Options{Algorithm: "HS256", Secret: []byte(testJWTSecret)})
+	require.NoError(t, err)
+	customerToken := "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testJWTSecret), customerClaims("5"))
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		permission     domain.Permission
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Admin Writes Brand", header: "X-API-Key", value: adminKey, permission: domain.PermissionBrandWrite, expectedStatus: http.StatusOK},
+		{name: "Admin Refunds", header: "X-API-Key", value: adminKey, permission: domain.PermissionTransactionRefund, expectedStatus: http.StatusOK},
+		{name: "Brand Manager Writes Voucher", header: "X-API-Key", value: managerKey, permission: domain.PermissionVoucherWrite, expectedStatus: http.StatusOK},
+		{
+			name:           "Brand Manager Writes Brand",
+			header:         "X-API-Key",
+			value:          managerKey,
+			permission:     domain.PermissionBrandWrite,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"permission brand:write is required","error_code":"PERMISSION_DENIED"}`,
+		},
+		{
+			name:           "Brand Manager Redeems",
+			header:         "X-API-Key",
+			value:          managerKey,
+			permission:     domain.PermissionTransactionRedeem,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"permission transaction:redeem is required","error_code":"PERMISSION_DENIED"}`,
+		},
+		{name: "Customer Redeems", header: "Authorization", value: customerToken, permission: domain.PermissionTransactionRedeem, expectedStatus: http.StatusOK},
+		{
+			name:           "Customer Writes Voucher",
+			header:         "Authorization",
+			value:          customerToken,
+			permission:     domain.PermissionVoucherWrite,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"permission voucher:write is required","error_code":"PERMISSION_DENIED"}`,
+		},
+		{name: "Admin Reads Audit Log", header: "X-API-Key", value: adminKey, permission: domain.PermissionAuditRead, expectedStatus: http.StatusOK},
+		{
+			name:           "Brand Manager Reads Audit Log",
+			header:         "X-API-Key",
+			value:          managerKey,
+			permission:     domain.PermissionAuditRead,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"permission audit:read is required","error_code":"PERMISSION_DENIED"}`,
+		},
+		{name: "Customer Reads Ledger", header: "Authorization", value: customerToken, permission: domain.PermissionCustomerRead, expectedStatus: http.StatusOK},
+		{
+			name:           "Customer Credits Points",
+			header:         "Authorization",
+			value:          customerToken,
+			permission:     domain.PermissionCustomerWrite,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"permission customer:write is required","error_code":"PERMISSION_DENIED"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+
+			handler.Authenticate(authenticator, handler.Authorize(tt.permission, principalEcho))(rec, req, nil)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRBAC_AuthorizeWithoutPrincipal(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	handler.Authorize(domain.PermissionBrandWrite, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusNoContent)
+	})(rec, httptest.NewRequest(http.MethodPost, "/brand", nil), nil)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestRBAC_VoucherServiceBrandScope(t *testing.T) {
+	validUntil := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		principal     *domain.Principal
+		action        func(ctx context.Context, s domain.VoucherService) error
+		expectedError error
+	}{
+		{
+			name:      "Manager Creates Own Brand Voucher",
+			principal: brandManagerPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Create(ctx, &domain.Voucher{BrandID: 10, ValidUntil: validUntil})
+			},
+		},
+		{
+			name:      "Manager Creates Other Brand Voucher",
+			principal: brandManagerPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Create(ctx, &domain.Voucher{BrandID: 20, ValidUntil: validUntil})
+			},
+			expectedError: domain.ErrBrandAccessDenied,
+		},
+		{
+			name:      "Manager Moves Other Brand Voucher",
+			principal: brandManagerPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Update(ctx, &domain.Voucher{ID: 2, BrandID: 10})
+			},
+			expectedError: domain.ErrBrandAccessDenied,
+		},
+		{
+			name:      "Manager Updates Own Voucher",
+			principal: brandManagerPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Update(ctx, &domain.Voucher{ID: 1, BrandID: 10})
+			},
+		},
+		{
+			name:      "Manager Deletes Other Brand Voucher",
+			principal: brandManagerPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Delete(ctx, 2)
+			},
+			expectedError: domain.ErrBrandAccessDenied,
+		},
+		{
+			name:      "Admin Creates Any Brand Voucher",
+			principal: adminPrincipal,
+			action: func(ctx context.Context, s domain.VoucherService) error {
+				return s.Create(ctx, &domain.Voucher{BrandID: 20, ValidUntil: validUntil})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVoucherRepository{vouchers: map[int64]*domain.Voucher{
+				1: {ID: 1, BrandID: 10},
+				2: {ID: 2, BrandID: 20},
+			}}
+			voucherService := service.NewVoucherService(newFakeTxManager(fakeBrandRepository{}, repo), repo, fakeBrandRepository{})
+
+			err := tt.action(domain.WithPrincipal(context.Background(), tt.principal), voucherService)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Zero(t, repo.written)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, repo.written)
+			}
+		})
+	}
+}
+
+// TestRBAC_VoucherHandlerBrandScope memastikan PUT dan PATCH ke voucher
+// brand lain ditolak sebelum body dibaca dan divalidasi
+func TestRBAC_VoucherHandlerBrandScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		voucherID      string
+		requestBody    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Put Other Brand Voucher",
+			method:         http.MethodPut,
+			voucherID:      "2",
+			requestBody:    `{"brand_id":10,"code":"V100","name":"Voucher","points":100,"valid_until":"2030-12-31T23:59:59Z"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"access is limited to your own brand","error_code":"BRAND_ACCESS_DENIED"}`,
+		},
+		{
+			name:           "Patch Other Brand Voucher",
+			method:         http.MethodPatch,
+			voucherID:      "2",
+			requestBody:    `{"code":"invalid"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"message":"access is limited to your own brand","error_code":"BRAND_ACCESS_DENIED"}`,
+		},
+		{
+			name:           "Patch Missing Voucher",
+			method:         http.MethodPatch,
+			voucherID:      "3",
+			requestBody:    `{"name":"Renamed"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"voucher not found","error_code":"VOUCHER_NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeVoucherRepository{vouchers: map[int64]*domain.Voucher{
+				1: {ID: 1, BrandID: 10},
+				2: {ID: 2, BrandID: 20},
+			}}
+			h := handler.NewVoucherHandler(service.NewVoucherService(newFakeTxManager(fakeBrandRepository{}, repo), repo, fakeBrandRepository{}))
+
+			req := httptest.NewRequest(tt.method, "/voucher/"+tt.voucherID, strings.NewReader(tt.requestBody))
+			req = req.WithContext(domain.WithPrincipal(req.Context(), brandManagerPrincipal))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.voucherID}}
+
+			if tt.method == http.MethodPatch {
+				h.Patch(rec, req, params)
+			} else {
+				h.Update(rec, req, params)
+			}
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			assert.Zero(t, repo.written)
+		})
+	}
+}
+
+func TestRBAC_TransactionServiceScope(t *testing.T) {
+	transaction := domain.Transaction{
+		ID:         1,
+		CustomerID: 5,
+		Items: []domain.TransactionItem{
+			{ID: 1, VoucherID: 1, Voucher: &domain.Voucher{BrandID: 10}},
+		},
+	}
+	otherBrandManager := &domain.Principal{Type: domain.PrincipalAPIKey, ID: 3, Role: domain.RoleBrandManager, BrandID: 20}
+	otherCustomer := &domain.Principal{Type: domain.PrincipalCustomer, ID: 6, Role: domain.RoleCustomer}
+
+	tests := []struct {
+		name          string
+		principal     *domain.Principal
+		expectedError error
+	}{
+		{name: "Admin", principal: adminPrincipal},
+		{name: "Owner Customer", principal: customerPrincipal},
+		{name: "Other Customer", principal: otherCustomer, expectedError: domain.ErrCustomerAccessDenied},
+		{name: "Manager Of Redeemed Brand", principal: brandManagerPrincipal},
+		{name: "Manager Of Other Brand", principal: otherBrandManager, expectedError: domain.ErrBrandAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTransactionRepository{transaction: transaction}
+			transactionService := service.NewTransactionService(nil, repo, nil)
+
+			found, err := transactionService.GetTransactionByID(domain.WithPrincipal(context.Background(), tt.principal), 1)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, found)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), found.ID)
+			}
+		})
+	}
+}
+
+func TestRBAC_CustomerTransactionsScope(t *testing.T) {
+	tests := []struct {
+		name            string
+		principal       *domain.Principal
+		customerID      int64
+		expectedError   error
+		expectedBrandID *int64
+	}{
+		{name: "Own Transactions", principal: customerPrincipal, customerID: 5},
+		{name: "Other Customer Transactions", principal: customerPrincipal, customerID: 6, expectedError: domain.ErrCustomerAccessDenied},
+		{name: "Manager Sees Own Brand Only", principal: brandManagerPrincipal, customerID: 6, expectedBrandID: &brandManagerPrincipal.BrandID},
+		{name: "Admin Sees Everything", principal: adminPrincipal, customerID: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTransactionRepository{}
+			transactionService := service.NewTransactionService(nil, repo, nil)
+
+			_, _, err := transactionService.GetCustomerTransactions(domain.WithPrincipal(context.Background(), tt.principal), tt.customerID, domain.TransactionFilter{})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBrandID, repo.filter.BrandID)
+		})
+	}
+}
+
+// TestRBAC_BrandManagerSeesOwnItems memastikan brand manager tidak melihat
+// item dan refund brand lain dari redemption campuran
+func TestRBAC_BrandManagerSeesOwnItems(t *testing.T) {
+	kopi := &domain.Voucher{ID: 1, BrandID: 10}
+	teh := &domain.Voucher{ID: 2, BrandID: 20}
+	transaction := domain.Transaction{
+		ID:         1,
+		CustomerID: 5,
+		Items: []domain.TransactionItem{
+			{ID: 1, VoucherID: kopi.ID, Voucher: kopi},
+			{ID: 2, VoucherID: teh.ID, Voucher: teh},
+			{ID: 3, VoucherID: teh.ID, Voucher: teh},
+		},
+	}
+	refunds := []domain.Refund{
+		{ID: 1, TransactionID: 1, TransactionItemID: 1},
+		{ID: 2, TransactionID: 1, TransactionItemID: 2},
+		{ID: 3, TransactionID: 1, TransactionItemID: 3},
+	}
+
+	tests := []struct {
+		name            string
+		principal       *domain.Principal
+		expectedVoucher []int64
+	}{
+		{name: "Admin Sees Every Item", principal: adminPrincipal, expectedVoucher: []int64{kopi.ID, teh.ID, teh.ID}},
+		{name: "Kopi Manager", principal: brandManagerPrincipal, expectedVoucher: []int64{kopi.ID}},
+		{name: "Teh Manager", principal: &domain.Principal{Type: domain.PrincipalAPIKey, ID: 3, Role: domain.RoleBrandManager, BrandID: 20}, expectedVoucher: []int64{teh.ID, teh.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTransactionRepository{transaction: transaction, refunds: refunds}
+			transactionService := service.NewTransactionService(nil, repo, nil)
+			scoped := domain.WithPrincipal(context.Background(), tt.principal)
+
+			found, err := transactionService.GetTransactionByID(scoped, transaction.ID)
+			require.NoError(t, err)
+			assertScopedItems(t, found, tt.expectedVoucher)
+
+			list, _, err := transactionService.GetCustomerTransactions(scoped, transaction.CustomerID, domain.TransactionFilter{})
+			require.NoError(t, err)
+			require.Len(t, list, 1)
+			assert.Equal(t, tt.expectedVoucher, voucherIDsOf(list[0].Items))
+		})
+	}
+}
+
+// assertScopedItems memastikan item dan refund transaksi hanya milik voucher
+// yang diharapkan
+func assertScopedItems(t *testing.T, transaction *domain.Transaction, expectedVouchers []int64) {
+	assert.Equal(t, expectedVouchers, voucherIDsOf(transaction.Items))
+	require.Len(t, transaction.Refunds, len(expectedVouchers))
+	items := make(map[int64]bool)
+	for _, item := range transaction.Items {
+		items[item.ID] = true
+	}
+	for _, refund := range transaction.Refunds {
+		assert.True(t, items[refund.TransactionItemID], "refund %d belongs to another brand", refund.ID)
+	}
+}
+
+func voucherIDsOf(items []domain.TransactionItem) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.VoucherID)
+	}
+	return ids
+}
+
+// fakeCustomerRepository menganggap semua customer ada dengan satu entry
+// ledger
+type fakeCustomerRepository struct {
+	domain.CustomerRepository
+}
+
+func (fakeCustomerRepository) GetByID(ctx context.Context, id int64) (*domain.Customer, error) {
+	return &domain.Customer{ID: id}, nil
+}
+
+func (fakeCustomerRepository) GetLedgerEntries(ctx context.Context, customerID int64) ([]domain.PointsLedgerEntry, error) {
+	return []domain.PointsLedgerEntry{{ID: 1, CustomerID: customerID, EntryType: domain.PointsEntryCredit, Points: 100}}, nil
+}
+
+func TestRBAC_CustomerServiceScope(t *testing.T) {
+	customerService := service.NewCustomerService(fakeCustomerRepository{})
+	ctx := domain.WithPrincipal(context.Background(), customerPrincipal)
+
+	tests := []struct {
+		name          string
+		customerID    int64
+		expectedError error
+	}{
+		{name: "Own Profile And Ledger", customerID: customerPrincipal.ID},
+		{name: "Other Customer", customerID: 6, expectedError: domain.ErrCustomerAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customer, err := customerService.GetByID(ctx, tt.customerID)
+			ledger, ledgerErr := customerService.GetLedger(ctx, tt.customerID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, customer)
+				assert.ErrorIs(t, ledgerErr, tt.expectedError)
+				assert.Nil(t, ledger)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, customerPrincipal.ID, customer.ID)
+			require.NoError(t, ledgerErr)
+			assert.Len(t, ledger, 1)
+		})
+	}
+}
+
+func TestRBAC_ForbiddenErrorLocalized(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/customer/6/transactions", nil)
+	req.Header.Set("Accept-Language", "id")
+	req = req.WithContext(domain.WithPrincipal(req.Context(), customerPrincipal))
+	rec := httptest.NewRecorder()
+
+	handle := handler.Authorize(domain.PermissionCustomerWrite, principalEcho)
+	i18n.Middleware(i18n.English, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, nil)
+	})).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"status":403,"message":"butuh permission customer:write","error_code":"PERMISSION_DENIED"}`, rec.Body.String())
+}
+
+// TestRoleRepository butuh database Postgres yang sudah dimigrasi, set
+// TEST_DATABASE_URL untuk menjalankannya
+func TestRoleRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRoleRepository(db)
+	for role, expected := range newFakeRoleRepository() {
+		permissions, err := repo.Permissions(context.Background(), role)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, permissions, role)
+	}
+
+	unknown, err := repo.Permissions(context.Background(), "auditor")
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateRedemption_ConcurrentStock menjalankan banyak redemption paralel
+// terhadap voucher dengan stok terbatas. Backend memory selalu dijalankan,
+// Postgres hanya jika TEST_DATABASE_URL diset.
+func TestCreateRedemption_ConcurrentStock(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		testConcurrentStock(t, memoryRepositories(t))
+	})
+	t.Run("Postgres", func(t *testing.T) {
+		testConcurrentStock(t, postgresRepositories(t))
+	})
+}
+
+func testConcurrentStock(t *testing.T, r repositories) {
+	const (
+		stock   = 10
+		workers = 50
+	)
+	ctx := context.Background()
+
+	brand := createBrand(t, r, "Concurrency Brand", nil)
+	voucher := createVoucher(t, r, brand.ID, "RACE", 100, intPtr(stock))
+
+	// Satu customer per goroutine supaya yang diperebutkan hanya stok voucher
+	customerIDs := make([]int64, workers)
+	for i := range customerIDs {
+		customer := createCustomer(t, r, fmt.Sprintf("racer%d", i))
+		creditPoints(t, r, customer.ID, 1000)
+		customerIDs[i] = customer.ID
+	}
+
+	transactionHandler := handler.NewTransactionHandler(newTransactionService(r))
+	router := httprouter.New()
+	router.POST("/transaction/redemption", transactionHandler.CreateRedemption)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	start := make(chan struct{})
+	statuses := make(chan int, workers)
+	var wg sync.WaitGroup
+	for _, customerID := range customerIDs {
+		wg.Add(1)
+		go func(customerID int64) {
+			defer wg.Done()
+			body, _ := json.Marshal(domain.Transaction{
+				CustomerID: customerID,
+				Items:      []domain.TransactionItem{{VoucherID: voucher.ID}},
+			})
+			<-start
+			resp, err := http.Post(server.URL+"/transaction/redemption", "application/json", bytes.NewReader(body))
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}(customerID)
+	}
+	close(start)
+	wg.Wait()
+	close(statuses)
+
+	counts := map[int]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, stock, counts[http.StatusCreated])
+	assert.Equal(t, workers-stock, counts[http.StatusConflict])
+
+	updated, err := r.vouchers.GetByID(ctx, voucher.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.RemainingStock)
+	assert.Equal(t, 0, *updated.RemainingStock)
+
+	var redeemed int
+	for _, customerID := range customerIDs {
+		transactions, _, err := r.transactions.GetByCustomerID(ctx, customerID, domain.TransactionFilter{})
+		require.NoError(t, err)
+		for _, transaction := range transactions {
+			items, err := r.transactions.GetTransactionItems(ctx, transaction.ID)
+			require.NoError(t, err)
+			redeemed += len(items)
+		}
+	}
+	assert.Equal(t, stock, redeemed)
+}
@@ -0,0 +1,768 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/migrate"
+	"api-otto/internal/repository"
+	"api-otto/internal/repository/memory"
+	"api-otto/migrations"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repositories adalah satu backend penyimpanan yang diuji contract suite
+type repositories struct {
+	brands       domain.BrandRepository
+	vouchers     domain.VoucherRepository
+	transactions domain.TransactionRepository
+	customers    domain.CustomerRepository
+	audit        domain.AuditRepository
+	txManager    domain.TxManager
+}
+
+// repositoryFactory membuat backend kosong untuk setiap subtest
+type repositoryFactory func(t *testing.T) repositories
+
+func memoryRepositories(t *testing.T) repositories {
+	store := memory.NewStore()
+	return repositories{
+		brands:       memory.NewBrandRepository(store),
+		vouchers:     memory.NewVoucherRepository(store),
+		transactions: memory.NewTransactionRepository(store),
+		customers:    memory.NewCustomerRepository(store),
+		audit:        memory.NewAuditRepository(store),
+		txManager:    memory.NewTxManager(store),
+	}
+}
+
+func postgresRepositories(t *testing.T) repositories {
+	db := isolatedSchema(t)
+	migrator, err := migrate.New(db, migrations.FS)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	return repositories{
+		brands:       repository.NewBrandRepository(db),
+		vouchers:     repository.NewVoucherRepository(db),
+		transactions: repository.NewTransactionRepository(db),
+		customers:    repository.NewCustomerRepository(db),
+		audit:        repository.NewAuditRepository(db),
+		txManager:    repository.NewTxManager(db),
+	}
+}
+
+func TestRepositoryContract_Memory(t *testing.T) {
+	runRepositoryContract(t, memoryRepositories)
+}
+
+// TestRepositoryContract_Postgres butuh database Postgres, set
+// TEST_DATABASE_URL untuk menjalankannya. Setiap subtest memakai schema baru.
+func TestRepositoryContract_Postgres(t *testing.T) {
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	runRepositoryContract(t, postgresRepositories)
+}
+
+// runRepositoryContract memastikan setiap backend berperilaku sama. Update
+// dan Delete pada baris yang tidak ada mengembalikan error not found domain,
+// kecuali UpdateStatus yang meneruskan sql.ErrNoRows.
+func runRepositoryContract(t *testing.T, factory repositoryFactory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r repositories)
+	}{
+		{name: "Brand Create And Get", run: brandCreateAndGet},
+		{name: "Brand List", run: brandList},
+		{name: "Brand Update", run: brandUpdate},
+		{name: "Tampered Cursors", run: tamperedCursors},
+		{name: "Brand Delete", run: brandDelete},
+		{name: "Voucher Create And Get", run: voucherCreateAndGet},
+		{name: "Voucher List", run: voucherList},
+		{name: "Voucher Update", run: voucherUpdate},
+		{name: "Voucher Delete", run: voucherDelete},
+		{name: "Voucher Stock", run: voucherStock},
+		{name: "Transaction Create And Get", run: transactionCreateAndGet},
+		{name: "Transaction Get By Customer", run: transactionGetByCustomer},
+		{name: "Transaction Update Status", run: transactionUpdateStatus},
+		{name: "Transaction Items", run: transactionItems},
+		{name: "Transaction Refunds", run: transactionRefunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, factory(t))
+		})
+	}
+}
+
+func createBrand(t *testing.T, r repositories, name string, window *int) *domain.Brand {
+	brand := &domain.Brand{Name: name, Description: name + " description", CancellationWindowMinutes: window}
+	require.NoError(t, r.brands.Create(context.Background(), brand))
+	return brand
+}
+
+func createVoucher(t *testing.T, r repositories, brandID int64, code string, points int, stock *int) *domain.Voucher {
+	voucher := &domain.Voucher{
+		BrandID:    brandID,
+		Code:       code,
+		Name:       code + " name",
+		Points:     points,
+		ValidUntil: time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second),
+		TotalStock: stock,
+	}
+	require.NoError(t, r.vouchers.Create(context.Background(), voucher))
+	return voucher
+}
+
+func createCustomer(t *testing.T, r repositories, name string) *domain.Customer {
+	customer := &domain.Customer{Name: name, Email: fmt.Sprintf("%s@example.com", name)}
+	require.NoError(t, r.customers.Create(context.Background(), customer))
+	return customer
+}
+
+func createTransaction(t *testing.T, r repositories, customerID int64, vouchers ...*domain.Voucher) *domain.Transaction {
+	transaction := &domain.Transaction{CustomerID: customerID}
+	for _, voucher := range vouchers {
+		transaction.TotalPoints += voucher.Points
+		transaction.Items = append(transaction.Items, domain.TransactionItem{VoucherID: voucher.ID, PointsUsed: voucher.Points})
+	}
+	require.NoError(t, r.transactions.Create(context.Background(), transaction, fmt.Sprintf("customer:%d", customerID)))
+	return transaction
+}
+
+func brandIDs(brands []domain.Brand) []int64 {
+	var ids []int64
+	for _, brand := range brands {
+		ids = append(ids, brand.ID)
+	}
+	return ids
+}
+
+func voucherIDs(vouchers []domain.Voucher) []int64 {
+	var ids []int64
+	for _, voucher := range vouchers {
+		ids = append(ids, voucher.ID)
+	}
+	return ids
+}
+
+func transactionIDs(transactions []domain.Transaction) []int64 {
+	var ids []int64
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+	return ids
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func brandCreateAndGet(t *testing.T, r repositories) {
+	ctx := context.Background()
+	first := createBrand(t, r, "Kopi", nil)
+	second := createBrand(t, r, "Toko", intPtr(0))
+
+	assert.Greater(t, second.ID, first.ID)
+	require.NotNil(t, first.CancellationWindowMinutes)
+	assert.Equal(t, domain.DefaultCancellationWindowMinutes, *first.CancellationWindowMinutes)
+
+	brand, err := r.brands.GetByID(ctx, second.ID)
+	require.NoError(t, err)
+	require.NotNil(t, brand)
+	assert.Equal(t, "Toko", brand.Name)
+	assert.Equal(t, "Toko description", brand.Description)
+	assert.Equal(t, 0, *brand.CancellationWindowMinutes)
+	assert.False(t, brand.CreatedAt.IsZero())
+	assert.True(t, brand.CreatedAt.Equal(brand.UpdatedAt))
+
+	brand, err = r.brands.GetByID(ctx, second.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, brand)
+
+	err = r.brands.Create(ctx, &domain.Brand{Name: "Negative", CancellationWindowMinutes: intPtr(-1)})
+	assert.ErrorIs(t, err, domain.ErrConstraintViolation)
+}
+
+func brandList(t *testing.T, r repositories) {
+	ctx := context.Background()
+
+	brands, cursor, err := r.brands.List(ctx, domain.BrandFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, brands)
+	assert.Empty(t, cursor)
+
+	kopi := createBrand(t, r, "Kopi Nusantara", nil)
+	toko := createBrand(t, r, "Toko Serba Ada", nil)
+	bioskop := createBrand(t, r, "Bioskop", nil)
+
+	brands, cursor, err = r.brands.List(ctx, domain.BrandFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{kopi.ID, toko.ID, bioskop.ID}, brandIDs(brands))
+	assert.Empty(t, cursor)
+
+	brands, _, err = r.brands.List(ctx, domain.BrandFilter{Page: domain.Page{Sort: "-name"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{toko.ID, kopi.ID, bioskop.ID}, brandIDs(brands))
+
+	brands, _, err = r.brands.List(ctx, domain.BrandFilter{Name: "NUSA"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{kopi.ID}, brandIDs(brands))
+
+	brands, cursor, err = r.brands.List(ctx, domain.BrandFilter{Page: domain.Page{Limit: 2, Sort: "name"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{bioskop.ID, kopi.ID}, brandIDs(brands))
+	require.NotEmpty(t, cursor)
+	brands, cursor, err = r.brands.List(ctx, domain.BrandFilter{Page: domain.Page{Limit: 2, Sort: "name", Cursor: cursor}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{toko.ID}, brandIDs(brands))
+	assert.Empty(t, cursor)
+
+	_, _, err = r.brands.List(ctx, domain.BrandFilter{Page: domain.Page{Sort: "description"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidSort)
+	_, _, err = r.brands.List(ctx, domain.BrandFilter{Page: domain.Page{Cursor: "not-a-cursor"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+
+	// Wildcard LIKE di filter nama dicari apa adanya
+	discount := createBrand(t, r, "Diskon 50%_Hemat", nil)
+	for _, name := range []string{"%", "_", "0%_h"} {
+		brands, _, err = r.brands.List(ctx, domain.BrandFilter{Name: name})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{discount.ID}, brandIDs(brands), name)
+	}
+	brands, _, err = r.brands.List(ctx, domain.BrandFilter{Name: `\`})
+	require.NoError(t, err)
+	assert.Empty(t, brands)
+}
+
+// forgeCursor membuat cursor dengan nilai sembarang, seperti cursor yang
+// diubah client
+func forgeCursor(sort, value string) string {
+	raw, _ := json.Marshal(map[string]interface{}{"s": sort, "v": value, "id": 1})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func tamperedCursors(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	createVoucher(t, r, brand.ID, "KOPI", 100, nil)
+	customer := createCustomer(t, r, "budi")
+
+	tests := []struct {
+		name string
+		list func(page domain.Page) error
+		sort string
+		// extra adalah nilai yang hanya tidak valid untuk tipe kolom ini
+		extra []string
+	}{
+		{name: "brand timestamp", sort: "created_at", list: func(page domain.Page) error {
+			_, _, err := r.brands.List(ctx, domain.BrandFilter{Page: page})
+			return err
+		}},
+		{name: "brand bigint", sort: "id", list: func(page domain.Page) error {
+			_, _, err := r.brands.List(ctx, domain.BrandFilter{Page: page})
+			return err
+		}},
+		{name: "voucher timestamp", sort: "-valid_until", list: func(page domain.Page) error {
+			_, _, err := r.vouchers.List(ctx, domain.VoucherFilter{Page: page})
+			return err
+		}},
+		{name: "voucher integer", sort: "points", extra: []string{"3000000000"}, list: func(page domain.Page) error {
+			_, _, err := r.vouchers.List(ctx, domain.VoucherFilter{Page: page})
+			return err
+		}},
+		{name: "transaction timestamp", sort: "-created_at", list: func(page domain.Page) error {
+			_, _, err := r.transactions.GetByCustomerID(ctx, customer.ID, domain.TransactionFilter{Page: page})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		values := append([]string{"yesterday", "2025-13-45 25:61:00", "99999999999999999999", ""}, tt.extra...)
+		for _, value := range values {
+			t.Run(tt.name+" "+value, func(t *testing.T) {
+				err := tt.list(domain.Page{Sort: tt.sort, Cursor: forgeCursor(tt.sort, value)})
+				assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+			})
+		}
+	}
+}
+
+func brandUpdate(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", intPtr(60))
+
+	update := &domain.Brand{ID: brand.ID, Name: "Kopi Baru", Description: "baru"}
+	require.NoError(t, r.brands.Update(ctx, update))
+	assert.Equal(t, 60, *update.CancellationWindowMinutes)
+	assert.False(t, update.UpdatedAt.IsZero())
+
+	stored, err := r.brands.GetByID(ctx, brand.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Kopi Baru", stored.Name)
+	assert.Equal(t, "baru", stored.Description)
+	assert.Equal(t, 60, *stored.CancellationWindowMinutes)
+	assert.False(t, stored.UpdatedAt.Before(stored.CreatedAt))
+
+	update.CancellationWindowMinutes = intPtr(0)
+	require.NoError(t, r.brands.Update(ctx, update))
+	stored, err = r.brands.GetByID(ctx, brand.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *stored.CancellationWindowMinutes)
+
+	err = r.brands.Update(ctx, &domain.Brand{ID: brand.ID + 100, Name: "Missing"})
+	assert.ErrorIs(t, err, domain.ErrBrandNotFound)
+}
+
+func brandDelete(t *testing.T, r repositories) {
+	ctx := context.Background()
+	empty := createBrand(t, r, "Empty", nil)
+	used := createBrand(t, r, "Used", nil)
+	createVoucher(t, r, used.ID, "USED-1", 100, nil)
+
+	require.NoError(t, r.brands.Delete(ctx, empty.ID))
+	brand, err := r.brands.GetByID(ctx, empty.ID)
+	require.NoError(t, err)
+	assert.Nil(t, brand)
+
+	assert.ErrorIs(t, r.brands.Delete(ctx, empty.ID), domain.ErrBrandNotFound)
+	assert.ErrorIs(t, r.brands.Delete(ctx, used.ID), domain.ErrBrandHasVouchers)
+	brand, err = r.brands.GetByID(ctx, used.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, brand)
+}
+
+func voucherCreateAndGet(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", intPtr(60))
+	limited := createVoucher(t, r, brand.ID, "KOPI-25K", 250, intPtr(10))
+	unlimited := createVoucher(t, r, brand.ID, "KOPI-50K", 500, nil)
+
+	assert.Greater(t, unlimited.ID, limited.ID)
+	require.NotNil(t, limited.RemainingStock)
+	assert.Equal(t, 10, *limited.RemainingStock)
+	assert.Nil(t, unlimited.RemainingStock)
+
+	voucher, err := r.vouchers.GetByID(ctx, limited.ID)
+	require.NoError(t, err)
+	require.NotNil(t, voucher)
+	assert.Equal(t, brand.ID, voucher.BrandID)
+	assert.Equal(t, "KOPI-25K", voucher.Code)
+	assert.Equal(t, "KOPI-25K name", voucher.Name)
+	assert.Equal(t, 250, voucher.Points)
+	assert.WithinDuration(t, limited.ValidUntil, voucher.ValidUntil, time.Second)
+	assert.Equal(t, 10, *voucher.TotalStock)
+	assert.Equal(t, 10, *voucher.RemainingStock)
+	require.NotNil(t, voucher.Brand)
+	assert.Equal(t, brand.ID, voucher.Brand.ID)
+	assert.Equal(t, "Kopi", voucher.Brand.Name)
+	assert.Equal(t, 60, *voucher.Brand.CancellationWindowMinutes)
+
+	voucher, err = r.vouchers.GetByID(ctx, unlimited.ID+100)
+	require.NoError(t, err)
+	assert.Nil(t, voucher)
+
+	tests := []struct {
+		name     string
+		voucher  domain.Voucher
+		expected error
+	}{
+		{name: "duplicate code", voucher: domain.Voucher{BrandID: brand.ID, Code: "KOPI-25K", Name: "Copy", Points: 10}, expected: domain.ErrVoucherCodeExists},
+		{name: "unknown brand", voucher: domain.Voucher{BrandID: brand.ID + 100, Code: "NO-BRAND", Name: "No brand", Points: 10}, expected: domain.ErrInvalidReference},
+		{name: "zero points", voucher: domain.Voucher{BrandID: brand.ID, Code: "ZERO", Name: "Zero", Points: 0}, expected: domain.ErrConstraintViolation},
+		{name: "negative stock", voucher: domain.Voucher{BrandID: brand.ID, Code: "NEGATIVE", Name: "Negative", Points: 10, TotalStock: intPtr(-1)}, expected: domain.ErrConstraintViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher := tt.voucher
+			voucher.ValidUntil = time.Now().UTC().Add(time.Hour)
+			assert.ErrorIs(t, r.vouchers.Create(ctx, &voucher), tt.expected)
+		})
+	}
+}
+
+func voucherList(t *testing.T, r repositories) {
+	ctx := context.Background()
+	kopi := createBrand(t, r, "Kopi", nil)
+	toko := createBrand(t, r, "Toko", nil)
+	small := createVoucher(t, r, kopi.ID, "KOPI-S", 100, nil)
+	large := createVoucher(t, r, kopi.ID, "KOPI-L", 300, nil)
+	other := createVoucher(t, r, toko.ID, "TOKO-M", 200, nil)
+	expired := &domain.Voucher{BrandID: toko.ID, Code: "TOKO-OLD", Name: "Old", Points: 50, ValidUntil: time.Now().UTC().Add(-48 * time.Hour)}
+	require.NoError(t, r.vouchers.Create(ctx, expired))
+
+	vouchers, cursor, err := r.vouchers.List(ctx, domain.VoucherFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{small.ID, large.ID, other.ID, expired.ID}, voucherIDs(vouchers))
+	assert.Empty(t, cursor)
+
+	vouchers, _, err = r.vouchers.List(ctx, domain.VoucherFilter{Page: domain.Page{Sort: "-points"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{large.ID, other.ID, small.ID, expired.ID}, voucherIDs(vouchers))
+
+	vouchers, _, err = r.vouchers.List(ctx, domain.VoucherFilter{BrandID: &kopi.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{small.ID, large.ID}, voucherIDs(vouchers))
+
+	vouchers, _, err = r.vouchers.List(ctx, domain.VoucherFilter{MinPoints: intPtr(150), MaxPoints: intPtr(250)})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{other.ID}, voucherIDs(vouchers))
+
+	valid := false
+	vouchers, _, err = r.vouchers.List(ctx, domain.VoucherFilter{Valid: &valid})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{expired.ID}, voucherIDs(vouchers))
+
+	vouchers, cursor, err = r.vouchers.List(ctx, domain.VoucherFilter{Page: domain.Page{Limit: 3, Sort: "code"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{large.ID, small.ID, other.ID}, voucherIDs(vouchers))
+	require.NotEmpty(t, cursor)
+	vouchers, cursor, err = r.vouchers.List(ctx, domain.VoucherFilter{Page: domain.Page{Limit: 3, Sort: "code", Cursor: cursor}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{expired.ID}, voucherIDs(vouchers))
+	assert.Empty(t, cursor)
+
+	_, _, err = r.vouchers.List(ctx, domain.VoucherFilter{Page: domain.Page{Sort: "brand_id"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidSort)
+}
+
+func voucherUpdate(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	voucher := createVoucher(t, r, brand.ID, "KOPI-25K", 250, intPtr(10))
+	createVoucher(t, r, brand.ID, "KOPI-50K", 500, nil)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, r.vouchers.DecrementStock(ctx, voucher.ID))
+	}
+
+	// Unit yang sudah terjual tetap dihitung saat total stok diubah
+	voucher.Name = "Kopi Baru"
+	voucher.Points = 300
+	voucher.TotalStock = intPtr(5)
+	require.NoError(t, r.vouchers.Update(ctx, voucher))
+	assert.Equal(t, 1, *voucher.RemainingStock)
+
+	stored, err := r.vouchers.GetByID(ctx, voucher.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Kopi Baru", stored.Name)
+	assert.Equal(t, 300, stored.Points)
+	assert.Equal(t, 5, *stored.TotalStock)
+	assert.Equal(t, 1, *stored.RemainingStock)
+
+	voucher.TotalStock = intPtr(2)
+	require.NoError(t, r.vouchers.Update(ctx, voucher))
+	assert.Equal(t, 0, *voucher.RemainingStock)
+
+	voucher.TotalStock = nil
+	require.NoError(t, r.vouchers.Update(ctx, voucher))
+	assert.Nil(t, voucher.RemainingStock)
+
+	voucher.Code = "KOPI-50K"
+	assert.ErrorIs(t, r.vouchers.Update(ctx, voucher), domain.ErrVoucherCodeExists)
+
+	missing := *voucher
+	missing.ID = voucher.ID + 100
+	missing.Code = "MISSING"
+	assert.ErrorIs(t, r.vouchers.Update(ctx, &missing), domain.ErrVoucherNotFound)
+}
+
+func voucherDelete(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	unused := createVoucher(t, r, brand.ID, "UNUSED", 100, nil)
+	redeemed := createVoucher(t, r, brand.ID, "REDEEMED", 100, nil)
+	customer := createCustomer(t, r, "budi")
+	createTransaction(t, r, customer.ID, redeemed)
+
+	require.NoError(t, r.vouchers.Delete(ctx, unused.ID))
+	voucher, err := r.vouchers.GetByID(ctx, unused.ID)
+	require.NoError(t, err)
+	assert.Nil(t, voucher)
+
+	assert.ErrorIs(t, r.vouchers.Delete(ctx, unused.ID), domain.ErrVoucherNotFound)
+	assert.ErrorIs(t, r.vouchers.Delete(ctx, redeemed.ID), domain.ErrVoucherRedeemed)
+}
+
+func voucherStock(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	limited := createVoucher(t, r, brand.ID, "LIMITED", 100, intPtr(2))
+	unlimited := createVoucher(t, r, brand.ID, "UNLIMITED", 100, nil)
+
+	remaining := func(id int64) *int {
+		voucher, err := r.vouchers.GetByID(ctx, id)
+		require.NoError(t, err)
+		return voucher.RemainingStock
+	}
+
+	require.NoError(t, r.vouchers.DecrementStock(ctx, limited.ID))
+	require.NoError(t, r.vouchers.DecrementStock(ctx, limited.ID))
+	assert.ErrorIs(t, r.vouchers.DecrementStock(ctx, limited.ID), domain.ErrVoucherSoldOut)
+	assert.Equal(t, 0, *remaining(limited.ID))
+	assert.ErrorIs(t, r.vouchers.DecrementStock(ctx, unlimited.ID+100), domain.ErrVoucherSoldOut)
+
+	require.NoError(t, r.vouchers.IncrementStock(ctx, limited.ID))
+	require.NoError(t, r.vouchers.IncrementStock(ctx, limited.ID))
+	require.NoError(t, r.vouchers.IncrementStock(ctx, limited.ID))
+	assert.Equal(t, 2, *remaining(limited.ID))
+
+	require.NoError(t, r.vouchers.DecrementStock(ctx, unlimited.ID))
+	require.NoError(t, r.vouchers.IncrementStock(ctx, unlimited.ID))
+	assert.Nil(t, remaining(unlimited.ID))
+}
+
+func transactionCreateAndGet(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	first := createVoucher(t, r, brand.ID, "KOPI-25K", 250, nil)
+	second := createVoucher(t, r, brand.ID, "KOPI-50K", 500, nil)
+	customer := createCustomer(t, r, "budi")
+
+	transaction := createTransaction(t, r, customer.ID, first, second)
+	assert.NotZero(t, transaction.ID)
+	assert.Equal(t, domain.TransactionStatusPending, transaction.Status)
+	assert.False(t, transaction.CreatedAt.IsZero())
+	for _, item := range transaction.Items {
+		assert.NotZero(t, item.ID)
+		assert.Equal(t, transaction.ID, item.TransactionID)
+		assert.Equal(t, domain.TransactionItemStatusRedeemed, item.Status)
+	}
+
+	for _, get := range []func(context.Context, int64) (*domain.Transaction, error){r.transactions.GetByID, r.transactions.GetByIDForUpdate} {
+		stored, err := get(ctx, transaction.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, customer.ID, stored.CustomerID)
+		assert.Equal(t, 750, stored.TotalPoints)
+		assert.Equal(t, domain.TransactionStatusPending, stored.Status)
+		require.Len(t, stored.Items, 2)
+		assert.Equal(t, first.ID, stored.Items[0].VoucherID)
+		assert.Equal(t, second.ID, stored.Items[1].VoucherID)
+
+		stored, err = get(ctx, transaction.ID+100)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	}
+
+	history, err := r.transactions.GetStatusHistory(ctx, transaction.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, domain.TransactionStatus(""), history[0].FromStatus)
+	assert.Equal(t, domain.TransactionStatusPending, history[0].ToStatus)
+	assert.Equal(t, fmt.Sprintf("customer:%d", customer.ID), history[0].Actor)
+
+	failed := &domain.Transaction{CustomerID: customer.ID, TotalPoints: 0, Status: domain.TransactionStatusFailed, FailureReason: "sold out"}
+	assert.ErrorIs(t, r.transactions.Create(ctx, failed, domain.ActorSystem), domain.ErrInvalidTransition)
+
+	unknown := &domain.Transaction{CustomerID: customer.ID + 100, TotalPoints: 250}
+	assert.ErrorIs(t, r.transactions.Create(ctx, unknown, domain.ActorSystem), domain.ErrInvalidReference)
+}
+
+func transactionGetByCustomer(t *testing.T, r repositories) {
+	ctx := context.Background()
+	kopi := createBrand(t, r, "Kopi", nil)
+	toko := createBrand(t, r, "Toko", nil)
+	kopiVoucher := createVoucher(t, r, kopi.ID, "KOPI", 100, nil)
+	tokoVoucher := createVoucher(t, r, toko.ID, "TOKO", 300, nil)
+	budi := createCustomer(t, r, "budi")
+	siti := createCustomer(t, r, "siti")
+
+	transactions, cursor, err := r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+	assert.Empty(t, cursor)
+
+	first := createTransaction(t, r, budi.ID, kopiVoucher)
+	second := createTransaction(t, r, budi.ID, tokoVoucher)
+	third := createTransaction(t, r, budi.ID, kopiVoucher, tokoVoucher)
+	createTransaction(t, r, siti.ID, kopiVoucher)
+	require.NoError(t, r.transactions.UpdateStatus(ctx, second, domain.TransactionStatusCompleted, domain.ActorSystem, ""))
+
+	transactions, cursor, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{third.ID, second.ID, first.ID}, transactionIDs(transactions))
+	assert.Empty(t, cursor)
+
+	transactions, _, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{Status: domain.TransactionStatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{second.ID}, transactionIDs(transactions))
+
+	transactions, _, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{BrandID: &kopi.ID, Page: domain.Page{Sort: "id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{first.ID, third.ID}, transactionIDs(transactions))
+
+	transactions, _, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{MinPoints: intPtr(300), Page: domain.Page{Sort: "-total_points"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{third.ID, second.ID}, transactionIDs(transactions))
+
+	transactions, cursor, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{Page: domain.Page{Limit: 2, Sort: "id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{first.ID, second.ID}, transactionIDs(transactions))
+	require.NotEmpty(t, cursor)
+	transactions, cursor, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{Page: domain.Page{Limit: 2, Sort: "id", Cursor: cursor}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{third.ID}, transactionIDs(transactions))
+	assert.Empty(t, cursor)
+
+	_, _, err = r.transactions.GetByCustomerID(ctx, budi.ID, domain.TransactionFilter{Page: domain.Page{Sort: "status"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidSort)
+}
+
+func transactionUpdateStatus(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	voucher := createVoucher(t, r, brand.ID, "KOPI", 100, nil)
+	customer := createCustomer(t, r, "budi")
+	transaction := createTransaction(t, r, customer.ID, voucher)
+	stale := *transaction
+
+	require.NoError(t, r.transactions.UpdateStatus(ctx, transaction, domain.TransactionStatusCompleted, domain.ActorSystem, "stock reserved"))
+	assert.Equal(t, domain.TransactionStatusCompleted, transaction.Status)
+	assert.False(t, transaction.UpdatedAt.IsZero())
+
+	stored, err := r.transactions.GetByID(ctx, transaction.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransactionStatusCompleted, stored.Status)
+
+	history, err := r.transactions.GetStatusHistory(ctx, transaction.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Less(t, history[0].ID, history[1].ID)
+	assert.Equal(t, domain.TransactionStatusPending, history[1].FromStatus)
+	assert.Equal(t, domain.TransactionStatusCompleted, history[1].ToStatus)
+	assert.Equal(t, domain.ActorSystem, history[1].Actor)
+	assert.Equal(t, "stock reserved", history[1].Reason)
+
+	// Status lama yang basi dilaporkan dengan status terbaru di database
+	err = r.transactions.UpdateStatus(ctx, &stale, domain.TransactionStatusFailed, domain.ActorSystem, "")
+	var transitionErr *domain.InvalidTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, domain.TransactionStatusCompleted, transitionErr.From)
+	assert.Equal(t, domain.TransactionStatusFailed, transitionErr.To)
+
+	err = r.transactions.UpdateStatus(ctx, transaction, domain.TransactionStatusPending, domain.ActorSystem, "")
+	require.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, domain.TransactionStatusCompleted, transitionErr.From)
+
+	history, err = r.transactions.GetStatusHistory(ctx, transaction.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	missing := &domain.Transaction{ID: transaction.ID + 100, Status: domain.TransactionStatusPending}
+	err = r.transactions.UpdateStatus(ctx, missing, domain.TransactionStatusCompleted, domain.ActorSystem, "")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	history, err = r.transactions.GetStatusHistory(ctx, missing.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func transactionItems(t *testing.T, r repositories) {
+	ctx := context.Background()
+	kopi := createBrand(t, r, "Kopi", nil)
+	toko := createBrand(t, r, "Toko", nil)
+	kopiVoucher := createVoucher(t, r, kopi.ID, "KOPI", 100, nil)
+	tokoVoucher := createVoucher(t, r, toko.ID, "TOKO", 300, nil)
+	customer := createCustomer(t, r, "budi")
+	transaction := createTransaction(t, r, customer.ID, tokoVoucher)
+
+	item := &domain.TransactionItem{TransactionID: transaction.ID, VoucherID: kopiVoucher.ID, PointsUsed: 100}
+	require.NoError(t, r.transactions.CreateTransactionItem(ctx, item))
+	assert.Greater(t, item.ID, transaction.Items[0].ID)
+	assert.Equal(t, domain.TransactionItemStatusRedeemed, item.Status)
+	assert.False(t, item.CreatedAt.IsZero())
+
+	items, err := r.transactions.GetTransactionItems(ctx, transaction.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, transaction.Items[0].ID, items[0].ID)
+	assert.Equal(t, item.ID, items[1].ID)
+	for i, voucher := range []*domain.Voucher{tokoVoucher, kopiVoucher} {
+		assert.Equal(t, transaction.ID, items[i].TransactionID)
+		assert.Equal(t, voucher.ID, items[i].VoucherID)
+		assert.Equal(t, voucher.Points, items[i].PointsUsed)
+		require.NotNil(t, items[i].Voucher)
+		assert.Equal(t, voucher.Code, items[i].Voucher.Code)
+		assert.Equal(t, voucher.Name, items[i].Voucher.Name)
+		assert.Equal(t, voucher.Points, items[i].Voucher.Points)
+		assert.Equal(t, voucher.BrandID, items[i].Voucher.BrandID)
+	}
+
+	item.Status = domain.TransactionItemStatusRefunded
+	require.NoError(t, r.transactions.UpdateTransactionItem(ctx, item))
+	items, err = r.transactions.GetTransactionItems(ctx, transaction.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransactionItemStatusRedeemed, items[0].Status)
+	assert.Equal(t, domain.TransactionItemStatusRefunded, items[1].Status)
+
+	missing := &domain.TransactionItem{ID: item.ID + 100, Status: domain.TransactionItemStatusRefunded}
+	assert.ErrorIs(t, r.transactions.UpdateTransactionItem(ctx, missing), domain.ErrTransactionItemNotFound)
+
+	items, err = r.transactions.GetTransactionItems(ctx, transaction.ID+100)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	tests := []struct {
+		name     string
+		item     domain.TransactionItem
+		expected error
+	}{
+		{name: "unknown voucher", item: domain.TransactionItem{TransactionID: transaction.ID, VoucherID: tokoVoucher.ID + 100, PointsUsed: 10}, expected: domain.ErrInvalidReference},
+		{name: "unknown transaction", item: domain.TransactionItem{TransactionID: transaction.ID + 100, VoucherID: tokoVoucher.ID, PointsUsed: 10}, expected: domain.ErrInvalidReference},
+		{name: "zero points", item: domain.TransactionItem{TransactionID: transaction.ID, VoucherID: tokoVoucher.ID, PointsUsed: 0}, expected: domain.ErrConstraintViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			assert.ErrorIs(t, r.transactions.CreateTransactionItem(ctx, &item), tt.expected)
+		})
+	}
+}
+
+func transactionRefunds(t *testing.T, r repositories) {
+	ctx := context.Background()
+	brand := createBrand(t, r, "Kopi", nil)
+	first := createVoucher(t, r, brand.ID, "KOPI-25K", 250, nil)
+	second := createVoucher(t, r, brand.ID, "KOPI-50K", 500, nil)
+	customer := createCustomer(t, r, "budi")
+	transaction := createTransaction(t, r, customer.ID, first, second)
+
+	refunds, err := r.transactions.GetRefunds(ctx, transaction.ID)
+	require.NoError(t, err)
+	assert.Empty(t, refunds)
+
+	var created []*domain.Refund
+	for _, item := range []domain.TransactionItem{transaction.Items[1], transaction.Items[0]} {
+		refund := &domain.Refund{TransactionID: transaction.ID, TransactionItemID: item.ID, Points: item.PointsUsed, Actor: "admin", Reason: "broken"}
+		require.NoError(t, r.transactions.CreateRefund(ctx, refund))
+		assert.NotZero(t, refund.ID)
+		assert.False(t, refund.CreatedAt.IsZero())
+		created = append(created, refund)
+	}
+
+	refunds, err = r.transactions.GetRefunds(ctx, transaction.ID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	for i, refund := range created {
+		assert.Equal(t, refund.ID, refunds[i].ID)
+		assert.Equal(t, refund.TransactionItemID, refunds[i].TransactionItemID)
+		assert.Equal(t, refund.Points, refunds[i].Points)
+		assert.Equal(t, "admin", refunds[i].Actor)
+		assert.Equal(t, "broken", refunds[i].Reason)
+	}
+
+	duplicate := &domain.Refund{TransactionID: transaction.ID, TransactionItemID: transaction.Items[0].ID, Points: 250, Actor: "admin", Reason: "again"}
+	assert.ErrorIs(t, r.transactions.CreateRefund(ctx, duplicate), domain.ErrTransactionItemRefunded)
+
+	unknown := &domain.Refund{TransactionID: transaction.ID, TransactionItemID: transaction.Items[1].ID + 100, Points: 250, Actor: "admin", Reason: "missing"}
+	assert.ErrorIs(t, r.transactions.CreateRefund(ctx, unknown), domain.ErrInvalidReference)
+}
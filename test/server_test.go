@@ -0,0 +1,149 @@
+package test
+
+import (
+	"api-otto/internal/server"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler memberi tahu saat request masuk lalu menunggu release
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		io.WriteString(w, "done")
+	})
+}
+
+func startServer(t *testing.T, srv *server.Server) (string, context.CancelFunc, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, listener)
+	}()
+	return "http://" + listener.Addr().String(), cancel, done
+}
+
+func TestServer_GracefulShutdownDrainsRequests(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := server.New(server.Options{ShutdownTimeout: 5 * time.Second}, blockingHandler(started, release))
+
+	var workerStopped, dbClosed atomic.Bool
+	srv.AddWorker("test", func(ctx context.Context) {
+		<-ctx.Done()
+		workerStopped.Store(true)
+	})
+	srv.OnShutdown("database", func() error {
+		// Resource ditutup setelah worker berhenti
+		assert.True(t, workerStopped.Load())
+		dbClosed.Store(true)
+		return nil
+	})
+
+	url, cancel, done := startServer(t, srv)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- result{body: string(body)}
+	}()
+
+	<-started
+	cancel()
+
+	// Server belum selesai selama request masih berjalan
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped before in-flight request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.False(t, dbClosed.Load())
+
+	close(release)
+
+	res := <-response
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	require.NoError(t, <-done)
+	assert.True(t, workerStopped.Load())
+	assert.True(t, dbClosed.Load())
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	srv := server.New(server.Options{ShutdownTimeout: 50 * time.Millisecond}, blockingHandler(started, release))
+
+	var dbClosed atomic.Bool
+	srv.OnShutdown("database", func() error {
+		dbClosed.Store(true)
+		return nil
+	})
+
+	url, cancel, done := startServer(t, srv)
+	go http.Get(url)
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shutdown")
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the shutdown timeout")
+	}
+	assert.True(t, dbClosed.Load())
+}
+
+func TestServer_DrainBeforeShutdown(t *testing.T) {
+	var draining atomic.Bool
+	srv := server.New(server.Options{ShutdownTimeout: 5 * time.Second, DrainDelay: 200 * time.Millisecond},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if draining.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+	srv.OnDrain(func() { draining.Store(true) })
+
+	url, cancel, done := startServer(t, srv)
+	cancel()
+
+	// Selama drain delay request baru masih dilayani, tetapi sudah melihat
+	// status draining
+	require.Eventually(t, draining.Load, time.Second, 10*time.Millisecond)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the drain delay")
+	}
+}
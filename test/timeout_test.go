@@ -0,0 +1,71 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowBrandService menunggu sampai context request selesai, seperti query
+// database yang dibatalkan driver saat deadline lewat
+type slowBrandService struct {
+	MockBrandService
+}
+
+func (s *slowBrandService) GetByID(ctx context.Context, id int64) (*domain.Brand, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("query brand %d: %w", id, ctx.Err())
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		cancelClient   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Deadline Exceeded",
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedBody:   `{"status":504,"message":"Request took too long to process","error_code":"REQUEST_TIMEOUT"}`,
+		},
+		{
+			name:           "Client Cancelled",
+			cancelClient:   true,
+			expectedStatus: 499,
+			expectedBody:   `{"status":499,"message":"Request was cancelled by the client","error_code":"REQUEST_CANCELLED"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brandHandler := handler.NewBrandHandler(&slowBrandService{})
+			timeout := 20 * time.Millisecond
+			if tt.cancelClient {
+				timeout = time.Minute
+			}
+			handle := handler.WithTimeout(timeout, brandHandler.GetByID)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelClient {
+				cancel()
+			}
+			req := httptest.NewRequest(http.MethodGet, "/brand/1", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			handle(rec, req, httprouter.Params{{Key: "id", Value: "1"}})
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
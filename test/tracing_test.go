@@ -0,0 +1,171 @@
+package test
+
+import (
+	"api-otto/database"
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/repository"
+	"api-otto/internal/service"
+	"api-otto/internal/tracing"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans memasang tracer provider global yang menyimpan span di memory
+// selama test berjalan
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func findSpan(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not found", name)
+	return nil
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracing_RedemptionSpans(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "Completed", expectedStatus: http.StatusCreated},
+		{name: "Rejected", serviceErr: fmt.Errorf("%w: voucher 2", domain.ErrVoucherExpired), expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+
+			mockService := new(MockTransactionService)
+			mockService.On("CreateRedemption", mock.AnythingOfType("*domain.Transaction")).Run(func(args mock.Arguments) {
+				transaction := args.Get(0).(*domain.Transaction)
+				transaction.ID = 77
+				transaction.Status = domain.TransactionStatusCompleted
+				if tt.serviceErr != nil {
+					transaction.Status = domain.TransactionStatusFailed
+				}
+			}).Return(tt.serviceErr)
+			transactionHandler := handler.NewTransactionHandler(service.NewTracedTransactionService(mockService))
+			handle := tracing.Handle(http.MethodPost, "/transaction/redemption", transactionHandler.CreateRedemption)
+
+			// Trace dilanjutkan dari client lewat header traceparent
+			parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+			req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", strings.NewReader(`{"customer_id":5,"items":[{"voucher_id":1},{"voucher_id":2}]}`))
+			req.Header.Set("traceparent", parent)
+			rec := httptest.NewRecorder()
+
+			handle(rec, req, nil)
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+
+			server := findSpan(t, spans, "POST /transaction/redemption")
+			assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+			serverAttrs := spanAttributes(server)
+			assert.Equal(t, "/transaction/redemption", serverAttrs["http.route"].AsString())
+			assert.Equal(t, int64(tt.expectedStatus), serverAttrs["http.response.status_code"].AsInt64())
+
+			redemption := findSpan(t, spans, "TransactionService.CreateRedemption")
+			assert.Equal(t, server.SpanContext().SpanID(), redemption.Parent().SpanID())
+			attrs := spanAttributes(redemption)
+			assert.Equal(t, int64(5), attrs["customer.id"].AsInt64())
+			assert.Equal(t, []int64{1, 2}, attrs["voucher.ids"].AsInt64Slice())
+			assert.Equal(t, int64(77), attrs["transaction.id"].AsInt64())
+			if tt.serviceErr != nil {
+				assert.Equal(t, "Error", redemption.Status().Code.String())
+				assert.Equal(t, "failed", attrs["transaction.status"].AsString())
+			} else {
+				assert.Equal(t, "Unset", redemption.Status().Code.String())
+			}
+		})
+	}
+}
+
+func TestTracing_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "file", File: path, ServiceName: "api-otto-test"})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "GET /brand/:id")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"GET /brand/:id"`)
+	assert.Contains(t, string(content), `"api-otto-test"`)
+}
+
+func TestTracing_UnknownExporter(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "zipkin"})
+
+	assert.EqualError(t, err, `tracing: unknown exporter "zipkin"`)
+}
+
+// TestTracing_SQLSpans butuh database Postgres yang sudah dimigrasi, set
+// TEST_DATABASE_URL untuk menjalankannya
+func TestTracing_SQLSpans(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresConnection(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	recorder := recordSpans(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err = repository.NewBrandRepository(db).GetByID(ctx, -1)
+	parent.End()
+	require.NoError(t, err)
+
+	query := findSpan(t, recorder.Ended(), "SELECT")
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	attrs := spanAttributes(query)
+	assert.Equal(t, "postgresql", attrs["db.system"].AsString())
+	assert.Contains(t, attrs["db.statement"].AsString(), "FROM brands")
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionHandler_CancelRedemption(t *testing.T) {
+	request := domain.RefundRequest{Reason: "customer request"}
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		requestBody    string
+		mockBehavior   func(service *MockTransactionService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:          "Success Cancel",
+			transactionID: "1",
+			requestBody:   `{"reason":"customer request"}`,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CancelRedemption", int64(1), request).Return(&domain.Transaction{
+					ID:          1,
+					CustomerID:  1,
+					TotalPoints: 50000,
+					Status:      domain.TransactionStatusCancelled,
+					Items: []domain.TransactionItem{
+						{
+							ID:            1,
+							TransactionID: 1,
+							VoucherID:     1,
+							PointsUsed:    50000,
+							Status:        domain.TransactionItemStatusRefunded,
+							CreatedAt:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						},
+					},
+					CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"status": 200,
+				"message": "Redemption cancelled successfully",
+				"data": {
+					"id": 1,
+					"customer_id": 1,
+					"total_points": 50000,
+					"status": "cancelled",
+					"items": [
+						{
+							"id": 1,
+							"transaction_id": 1,
+							"voucher_id": 1,
+							"points_used": 50000,
+							"status": "refunded",
+							"created_at": "2024-03-01T00:00:00Z"
+						}
+					],
+					"created_at": "2024-03-01T00:00:00Z",
+					"updated_at": "2024-03-01T00:00:00Z"
+				}
+			}`,
+		},
+		{
+			name:           "Missing Reason",
+			transactionID:  "1",
+			requestBody:    `{}`,
+			mockBehavior:   func(service *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"validation failed","error_code":"VALIDATION_FAILED","errors":[{"field":"reason","rule":"required","message":"reason is required"}]}`,
+		},
+		{
+			name:          "Window Expired",
+			transactionID: "1",
+			requestBody:   `{"reason":"customer request"}`,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CancelRedemption", int64(1), request).Return(nil, fmt.Errorf("%w: brand 1 allows 1h0m0s", domain.ErrCancellationWindowExpired))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"status":422,"message":"cancellation window has expired: brand 1 allows 1h0m0s","error_code":"CANCELLATION_WINDOW_EXPIRED"}`,
+		},
+		{
+			name:          "Already Cancelled",
+			transactionID: "1",
+			requestBody:   `{"reason":"customer request"}`,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CancelRedemption", int64(1), request).Return(nil, fmt.Errorf("%w: status is cancelled", domain.ErrTransactionNotCancellable))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"message":"transaction cannot be cancelled: status is cancelled","error_code":"TRANSACTION_NOT_CANCELLABLE"}`,
+		},
+		{
+			name:          "Transaction Not Found",
+			transactionID: "999",
+			requestBody:   `{"reason":"customer request"}`,
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("CancelRedemption", int64(999), request).Return(nil, domain.ErrTransactionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":404,"message":"transaction not found","error_code":"TRANSACTION_NOT_FOUND"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			tt.mockBehavior(mockService)
+			handler := handler.NewTransactionHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/transaction/redemption/"+tt.transactionID+"/cancel", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{httprouter.Param{Key: "id", Value: tt.transactionID}}
+
+			handler.CancelRedemption(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransactionHandler_RefundItem(t *testing.T) {
+	request := domain.RefundRequest{Reason: "wrong voucher"}
+
+	tests := []struct {
+		name           string
+		itemID         string
+		mockBehavior   func(service *MockTransactionService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Item Already Refunded",
+			itemID: "2",
+			mockBehavior: func(service *MockTransactionService) {
+				service.On("RefundItem", int64(1), int64(2), request).Return(nil, fmt.Errorf("%w: item 2", domain.ErrTransactionItemRefunded))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":409,"message":"transaction item already refunded: item 2","error_code":"TRANSACTION_ITEM_REFUNDED"}`,
+		},
+		{
+			name:           "Invalid Item ID",
+			itemID:         "abc",
+			mockBehavior:   func(service *MockTransactionService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"message":"Invalid item ID","error_code":"BAD_REQUEST"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockTransactionService)
+			tt.mockBehavior(mockService)
+			handler := handler.NewTransactionHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/transaction/redemption/1/items/"+tt.itemID+"/refund", bytes.NewBufferString(`{"reason":"wrong voucher"}`))
+			rec := httptest.NewRecorder()
+			params := httprouter.Params{
+				httprouter.Param{Key: "id", Value: "1"},
+				httprouter.Param{Key: "item_id", Value: tt.itemID},
+			}
+
+			handler.RefundItem(rec, req, params)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestTransactionHandler_RefundActorFromPrincipal memastikan actor yang
+// dikirim client tidak pernah masuk ke riwayat refund maupun status
+func TestTransactionHandler_RefundActorFromPrincipal(t *testing.T) {
+	r := memoryRepositories(t)
+	transaction := redeemForRefund(t, r)
+	h := handler.NewTransactionHandler(newTransactionService(r))
+	principal := &domain.Principal{Type: domain.PrincipalAPIKey, ID: 3, Role: domain.RolePlatformAdmin}
+
+	send := func(path string, params httprouter.Params, call func(http.ResponseWriter, *http.Request, httprouter.Params)) {
+		body := `{"actor":"customer:999","reason":"customer request"}`
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req = req.WithContext(domain.WithPrincipal(req.Context(), principal))
+		rec := httptest.NewRecorder()
+		call(rec, req, params)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+	id := fmt.Sprint(transaction.ID)
+	send("/transaction/redemption/"+id+"/items/1/refund", httprouter.Params{
+		{Key: "id", Value: id},
+		{Key: "item_id", Value: fmt.Sprint(transaction.Items[0].ID)},
+	}, h.RefundItem)
+	send("/transaction/redemption/"+id+"/cancel", httprouter.Params{{Key: "id", Value: id}}, h.CancelRedemption)
+
+	refunds, err := r.transactions.GetRefunds(context.Background(), transaction.ID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	for _, refund := range refunds {
+		assert.Equal(t, "api_key:3", refund.Actor)
+	}
+	history, err := r.transactions.GetStatusHistory(context.Background(), transaction.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "api_key:3", history[len(history)-1].Actor)
+}
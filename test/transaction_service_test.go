@@ -0,0 +1,285 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/service"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// creditPoints memberi saldo awal ke customer lewat ledger
+func creditPoints(t *testing.T, r repositories, customerID int64, points int) {
+	require.NoError(t, r.customers.CreateLedgerEntry(context.Background(), &domain.PointsLedgerEntry{
+		CustomerID: customerID,
+		EntryType:  domain.PointsEntryCredit,
+		Points:     points,
+		Reason:     "test",
+	}))
+}
+
+func newTransactionService(r repositories) domain.TransactionService {
+	return service.NewTransactionService(r.txManager, r.transactions, nil)
+}
+
+func TestTransactionService_CreateRedemption_Points(t *testing.T) {
+	tests := []struct {
+		name            string
+		balance         int
+		expectedErr     error
+		expectedBalance int
+		expectedLedger  []domain.PointsEntryType
+		expectedItems   int
+	}{
+		{
+			name:            "Success Debits Balance Once",
+			balance:         1000,
+			expectedBalance: 250,
+			expectedLedger:  []domain.PointsEntryType{domain.PointsEntryCredit, domain.PointsEntryDebit},
+			expectedItems:   2,
+		},
+		{
+			name:            "Insufficient Points",
+			balance:         500,
+			expectedErr:     domain.ErrInsufficientPoints,
+			expectedBalance: 500,
+			expectedLedger:  []domain.PointsEntryType{domain.PointsEntryCredit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := memoryRepositories(t)
+			ctx := context.Background()
+			brand := createBrand(t, r, "Kopi", nil)
+			first := createVoucher(t, r, brand.ID, "KOPI-25K", 250, nil)
+			second := createVoucher(t, r, brand.ID, "KOPI-50K", 500, nil)
+			customer := createCustomer(t, r, "budi")
+			creditPoints(t, r, customer.ID, tt.balance)
+
+			transaction := &domain.Transaction{
+				CustomerID: customer.ID,
+				Items:      []domain.TransactionItem{{VoucherID: first.ID}, {VoucherID: second.ID}},
+			}
+			err := newTransactionService(r).CreateRedemption(ctx, transaction)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			balance, err := r.customers.GetBalance(ctx, customer.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBalance, balance)
+
+			ledger, err := r.customers.GetLedgerEntries(ctx, customer.ID)
+			require.NoError(t, err)
+			var types []domain.PointsEntryType
+			for _, entry := range ledger {
+				types = append(types, entry.EntryType)
+			}
+			assert.Equal(t, tt.expectedLedger, types)
+
+			items, err := r.transactions.GetTransactionItems(ctx, transaction.ID)
+			require.NoError(t, err)
+			assert.Len(t, items, tt.expectedItems)
+
+			if tt.expectedErr == nil {
+				debit := ledger[len(ledger)-1]
+				assert.Equal(t, 750, debit.Points)
+				assert.Equal(t, domain.PointsReasonRedemption, debit.Reason)
+				require.NotNil(t, debit.TransactionID)
+				assert.Equal(t, transaction.ID, *debit.TransactionID)
+
+				stored, err := r.transactions.GetByID(ctx, transaction.ID)
+				require.NoError(t, err)
+				assert.Equal(t, domain.TransactionStatusCompleted, stored.Status)
+				assert.Equal(t, 750, stored.TotalPoints)
+			}
+		})
+	}
+}
+
+// TestTransactionService_CreateRedemption_RecordsFailure memastikan redemption
+// gagal hanya meninggalkan transaksi failed beserta alasannya, tanpa item,
+// debit poin, maupun perubahan stok
+func TestTransactionService_CreateRedemption_RecordsFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		balance     int
+		stock       int
+		items       int
+		expectedErr error
+	}{
+		{name: "Sold Out", balance: 1000, stock: 0, items: 1, expectedErr: domain.ErrVoucherSoldOut},
+		// Voucher yang sama dua kali lolos cek awal, lalu gagal di
+		// DecrementStock kedua setelah stok pertama sudah dikurangi
+		{name: "Sold Out After Decrement", balance: 1000, stock: 1, items: 2, expectedErr: domain.ErrVoucherSoldOut},
+		{name: "Insufficient Points", balance: 100, stock: 5, items: 1, expectedErr: domain.ErrInsufficientPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := memoryRepositories(t)
+			ctx := context.Background()
+			brand := createBrand(t, r, "Kopi", nil)
+			voucher := createVoucher(t, r, brand.ID, "KOPI-25K", 250, intPtr(tt.stock))
+			customer := createCustomer(t, r, "budi")
+			creditPoints(t, r, customer.ID, tt.balance)
+
+			transaction := &domain.Transaction{CustomerID: customer.ID}
+			for i := 0; i < tt.items; i++ {
+				transaction.Items = append(transaction.Items, domain.TransactionItem{VoucherID: voucher.ID})
+			}
+			cause := newTransactionService(r).CreateRedemption(ctx, transaction)
+			require.ErrorIs(t, cause, tt.expectedErr)
+
+			transactions, _, err := r.transactions.GetByCustomerID(ctx, customer.ID, domain.TransactionFilter{})
+			require.NoError(t, err)
+			require.Len(t, transactions, 1)
+			failed := transactions[0]
+			assert.Equal(t, transaction.ID, failed.ID)
+			assert.Equal(t, domain.TransactionStatusFailed, failed.Status)
+			assert.Equal(t, cause.Error(), failed.FailureReason)
+
+			items, err := r.transactions.GetTransactionItems(ctx, failed.ID)
+			require.NoError(t, err)
+			assert.Empty(t, items)
+
+			history, err := r.transactions.GetStatusHistory(ctx, failed.ID)
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			assert.Equal(t, domain.ActorSystem, history[0].Actor)
+			assert.Equal(t, domain.TransactionStatusFailed, history[1].ToStatus)
+			assert.Equal(t, cause.Error(), history[1].Reason)
+
+			ledger, err := r.customers.GetLedgerEntries(ctx, customer.ID)
+			require.NoError(t, err)
+			require.Len(t, ledger, 1)
+			assert.Equal(t, domain.PointsEntryCredit, ledger[0].EntryType)
+
+			stored, err := r.vouchers.GetByID(ctx, voucher.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.stock, *stored.RemainingStock)
+		})
+	}
+}
+
+func TestTransactionService_CreateRedemption_Actor(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     *domain.Principal
+		expectedActor string
+	}{
+		{name: "API Key", principal: &domain.Principal{Type: domain.PrincipalAPIKey, ID: 7, Role: domain.RolePlatformAdmin}, expectedActor: "api_key:7"},
+		{name: "Auth Disabled", expectedActor: domain.ActorAnonymous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := memoryRepositories(t)
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = domain.WithPrincipal(ctx, tt.principal)
+			}
+			brand := createBrand(t, r, "Kopi", nil)
+			voucher := createVoucher(t, r, brand.ID, "KOPI-25K", 250, nil)
+			customer := createCustomer(t, r, "budi")
+			creditPoints(t, r, customer.ID, 1000)
+
+			transaction := &domain.Transaction{CustomerID: customer.ID, Items: []domain.TransactionItem{{VoucherID: voucher.ID}}}
+			require.NoError(t, newTransactionService(r).CreateRedemption(ctx, transaction))
+
+			history, err := r.transactions.GetStatusHistory(ctx, transaction.ID)
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			assert.Equal(t, domain.TransactionStatusPending, history[0].ToStatus)
+			assert.Equal(t, tt.expectedActor, history[0].Actor)
+			assert.Equal(t, domain.ActorSystem, history[1].Actor)
+		})
+	}
+}
+
+// redeemForRefund membuat redemption completed dengan dua voucher
+func redeemForRefund(t *testing.T, r repositories) *domain.Transaction {
+	brand := createBrand(t, r, "Kopi", nil)
+	first := createVoucher(t, r, brand.ID, "KOPI-25K", 250, intPtr(5))
+	second := createVoucher(t, r, brand.ID, "KOPI-50K", 500, intPtr(5))
+	customer := createCustomer(t, r, "budi")
+	creditPoints(t, r, customer.ID, 1000)
+
+	transaction := &domain.Transaction{
+		CustomerID: customer.ID,
+		Items:      []domain.TransactionItem{{VoucherID: first.ID}, {VoucherID: second.ID}},
+	}
+	require.NoError(t, newTransactionService(r).CreateRedemption(context.Background(), transaction))
+	return transaction
+}
+
+// TestTransactionService_RefundItem_Audit memastikan refund sebagian, yang
+// tidak mengubah status transaksi, tetap tercatat di audit log
+func TestTransactionService_RefundItem_Audit(t *testing.T) {
+	r := memoryRepositories(t)
+	transaction := redeemForRefund(t, r)
+	ctx := domain.WithPrincipal(context.Background(), adminPrincipal)
+	service := newTransactionService(r)
+	item := transaction.Items[0]
+
+	refunded, err := service.RefundItem(ctx, transaction.ID, item.ID, domain.RefundRequest{Reason: "wrong voucher"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransactionStatusCompleted, refunded.Status)
+
+	id := transaction.ID
+	entries, _, err := r.audit.List(context.Background(), domain.AuditFilter{EntityType: domain.AuditEntityTransaction, EntityID: &id})
+	require.NoError(t, err)
+	var refunds []domain.AuditEntry
+	for _, entry := range entries {
+		if entry.Action == domain.AuditActionItemRefund {
+			refunds = append(refunds, entry)
+		}
+	}
+	require.Len(t, refunds, 1)
+	assert.Equal(t, adminPrincipal.String(), refunds[0].Actor)
+	assert.Empty(t, refunds[0].Before)
+	assert.JSONEq(t, fmt.Sprintf(`{"item_id":%d,"voucher_id":%d,"status":"refunded","points":%d,"reason":"wrong voucher"}`,
+		item.ID, item.VoucherID, item.PointsUsed), string(refunds[0].After))
+}
+
+func TestTransactionService_RefundActor(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     *domain.Principal
+		expectedActor string
+	}{
+		{name: "API Key", principal: &domain.Principal{Type: domain.PrincipalAPIKey, ID: 7, Role: domain.RolePlatformAdmin}, expectedActor: "api_key:7"},
+		{name: "Auth Disabled", expectedActor: domain.ActorAnonymous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := memoryRepositories(t)
+			transaction := redeemForRefund(t, r)
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = domain.WithPrincipal(ctx, tt.principal)
+			}
+			service := newTransactionService(r)
+
+			refunded, err := service.RefundItem(ctx, transaction.ID, transaction.Items[0].ID, domain.RefundRequest{Reason: "wrong voucher"})
+			require.NoError(t, err)
+			require.Len(t, refunded.Refunds, 1)
+			assert.Equal(t, tt.expectedActor, refunded.Refunds[0].Actor)
+
+			cancelled, err := service.CancelRedemption(ctx, transaction.ID, domain.RefundRequest{Reason: "customer request"})
+			require.NoError(t, err)
+			require.Len(t, cancelled.Refunds, 2)
+			assert.Equal(t, tt.expectedActor, cancelled.Refunds[1].Actor)
+			last := cancelled.History[len(cancelled.History)-1]
+			assert.Equal(t, domain.TransactionStatusCancelled, last.ToStatus)
+			assert.Equal(t, tt.expectedActor, last.Actor)
+		})
+	}
+}
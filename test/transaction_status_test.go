@@ -0,0 +1,92 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from    domain.TransactionStatus
+		to      domain.TransactionStatus
+		allowed bool
+	}{
+		{"", domain.TransactionStatusPending, true},
+		{"", domain.TransactionStatusCompleted, false},
+		{domain.TransactionStatusPending, domain.TransactionStatusCompleted, true},
+		{domain.TransactionStatusPending, domain.TransactionStatusFailed, true},
+		{domain.TransactionStatusPending, domain.TransactionStatusCancelled, false},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusCancelled, true},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusRefunded, true},
+		{domain.TransactionStatusCompleted, domain.TransactionStatusPending, false},
+		{domain.TransactionStatusFailed, domain.TransactionStatusCompleted, false},
+		{domain.TransactionStatusCancelled, domain.TransactionStatusCompleted, false},
+		{domain.TransactionStatusRefunded, domain.TransactionStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := domain.ValidateTransition(tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+
+			var transitionErr *domain.InvalidTransitionError
+			assert.True(t, errors.As(err, &transitionErr))
+			assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+			assert.Equal(t, tt.from, transitionErr.From)
+			assert.Equal(t, tt.to, transitionErr.To)
+		})
+	}
+}
+
+func TestTransactionHandler_GetByID_WithHistory(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockService := new(MockTransactionService)
+	mockService.On("GetTransactionByID", int64(1)).Return(&domain.Transaction{
+		ID:          1,
+		CustomerID:  1,
+		TotalPoints: 50000,
+		Status:      domain.TransactionStatusCompleted,
+		Items:       []domain.TransactionItem{},
+		History: []domain.TransactionStatusChange{
+			{ID: 1, TransactionID: 1, ToStatus: domain.TransactionStatusPending, Actor: "customer:1", CreatedAt: created},
+			{ID: 2, TransactionID: 1, FromStatus: domain.TransactionStatusPending, ToStatus: domain.TransactionStatusCompleted, Actor: domain.ActorSystem, CreatedAt: created},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/transaction/redemption/1", nil)
+	rec := httptest.NewRecorder()
+	handler.NewTransactionHandler(mockService).GetTransactionByID(rec, req, httprouter.Params{httprouter.Param{Key: "id", Value: "1"}})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"status": 200,
+		"message": "Success",
+		"data": {
+			"id": 1,
+			"customer_id": 1,
+			"total_points": 50000,
+			"status": "completed",
+			"items": [],
+			"history": [
+				{"id": 1, "transaction_id": 1, "to_status": "pending", "actor": "customer:1", "created_at": "2024-03-01T00:00:00Z"},
+				{"id": 2, "transaction_id": 1, "from_status": "pending", "to_status": "completed", "actor": "system", "created_at": "2024-03-01T00:00:00Z"}
+			],
+			"created_at": "2024-03-01T00:00:00Z",
+			"updated_at": "2024-03-01T00:00:00Z"
+		}
+	}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"api-otto/internal/domain"
+	"api-otto/internal/handler"
+	"api-otto/internal/i18n"
+	"api-otto/internal/validation"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidation_Voucher(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		input  domain.Voucher
+		fields []domain.FieldError
+	}{
+		{
+			name:  "Valid Voucher",
+			input: domain.Voucher{BrandID: 1, Code: "PROMO-2025", Name: "Promo", Points: 100, ValidUntil: future},
+		},
+		{
+			name:  "Lowercase Code",
+			input: domain.Voucher{BrandID: 1, Code: "promo", Name: "Promo", Points: 100, ValidUntil: future},
+			fields: []domain.FieldError{
+				{Field: "code", Rule: "voucher_code", Message: "code must be 3-50 uppercase letters, digits, '-' or '_'"},
+			},
+		},
+		{
+			name:  "Valid Until In Past",
+			input: domain.Voucher{BrandID: 1, Code: "PROMO", Name: "Promo", Points: 100, ValidUntil: past},
+			fields: []domain.FieldError{
+				{Field: "valid_until", Rule: "future", Message: "valid_until must be in the future"},
+			},
+		},
+		{
+			name:  "Multiple Failures",
+			input: domain.Voucher{Code: "X", Points: -1},
+			fields: []domain.FieldError{
+				{Field: "brand_id", Rule: "required", Message: "brand_id is required"},
+				{Field: "code", Rule: "voucher_code", Message: "code must be 3-50 uppercase letters, digits, '-' or '_'"},
+				{Field: "name", Rule: "required", Message: "name is required"},
+				{Field: "points", Rule: "gt", Message: "points must be greater than 0"},
+				{Field: "valid_until", Rule: "required", Message: "valid_until is required"},
+			},
+		},
+	}
+
+	v := validation.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(i18n.English, tt.input)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *domain.Error
+			require.True(t, errors.As(err, &domainErr))
+			assert.ErrorIs(t, err, domain.ErrValidation)
+			assert.Equal(t, tt.fields, domainErr.Fields)
+		})
+	}
+}
+
+func TestValidation_Redemption(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  domain.Transaction
+		fields []domain.FieldError
+	}{
+		{
+			name: "Valid Redemption",
+			input: domain.Transaction{
+				CustomerID: 1,
+				Items:      []domain.TransactionItem{{VoucherID: 1}, {VoucherID: 2}},
+			},
+		},
+		{
+			name: "Duplicate Voucher IDs",
+			input: domain.Transaction{
+				CustomerID: 1,
+				Items:      []domain.TransactionItem{{VoucherID: 1}, {VoucherID: 1}},
+			},
+			fields: []domain.FieldError{
+				{Field: "items", Rule: "unique_vouchers", Message: "items must not contain the same voucher_id more than once"},
+			},
+		},
+		{
+			name: "Missing Item Voucher ID",
+			input: domain.Transaction{
+				CustomerID: 1,
+				Items:      []domain.TransactionItem{{VoucherID: 1}, {}},
+			},
+			fields: []domain.FieldError{
+				{Field: "items[1].voucher_id", Rule: "required", Message: "items[1].voucher_id is required"},
+			},
+		},
+	}
+
+	v := validation.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(i18n.English, tt.input)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var domainErr *domain.Error
+			require.True(t, errors.As(err, &domainErr))
+			assert.Equal(t, tt.fields, domainErr.Fields)
+		})
+	}
+}
+
+func TestTransactionHandler_CreateRedemption_FieldErrors(t *testing.T) {
+	mockService := new(MockTransactionService)
+	h := handler.NewTransactionHandler(mockService)
+
+	body := `{"items":[{"voucher_id":7},{"voucher_id":7}]}`
+	req := httptest.NewRequest(http.MethodPost, "/transaction/redemption", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+
+	h.CreateRedemption(rec, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"status": 400,
+		"message": "validation failed",
+		"error_code": "VALIDATION_FAILED",
+		"errors": [
+			{"field": "customer_id", "rule": "required", "message": "customer_id is required"},
+			{"field": "items", "rule": "unique_vouchers", "message": "items must not contain the same voucher_id more than once"}
+		]
+	}`, rec.Body.String())
+	mockService.AssertNotCalled(t, "CreateRedemption")
+}